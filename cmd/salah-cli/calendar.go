@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"salah-cli/internal/calendar"
+	"salah-cli/internal/config"
+	"salah-cli/internal/conflicts"
+	"salah-cli/internal/params"
+	"salah-cli/pkg/salah"
+	"time"
+)
+
+// parseDay reads the YYYY-MM-DD value of a flag as midday on that day, today when empty.
+// Midday keeps the calendar date stable across DST transitions.
+func parseDay(flagName, value string) (time.Time, error) {
+	day := time.Now()
+	if value != "" {
+		t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			return time.Time{}, usageError("invalid --%s '%s', expected YYYY-MM-DD", flagName, value)
+		}
+		day = t
+	}
+	y, m, d := day.Date()
+	return time.Date(y, m, d, 12, 0, 0, 0, time.Local), nil
+}
+
+func runConflicts(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	path := fs.String("calendar", "", "iCalendar (.ics) file with the meetings to check (required)")
+	window := fs.Duration("window", conflicts.DefaultWindow, "time kept free for each prayer")
+	iqamah := fs.Duration("iqamah", 0, "start the time kept free this long after the prayer time, e.g. 10m")
+	from := fs.String("from", "", "first day to check (YYYY-MM-DD, default: today)")
+	days := fs.Int("days", 7, fmt.Sprintf("number of days to check (max %d)", conflicts.MaxDays))
+	format := choiceFlag(fs, "format", "text", "output format", "text", "json")
+
+	return func(ctx *runContext, args []string) error {
+		switch {
+		case *path == "":
+			return usageError("missing --calendar")
+		case *window <= 0:
+			return usageError("invalid --window %s, must be positive", *window)
+		case *iqamah < 0:
+			return usageError("invalid --iqamah %s, must not be negative", *iqamah)
+		case *days < 1 || *days > conflicts.MaxDays:
+			return usageError("invalid --days %d, must be between 1 and %d", *days, conflicts.MaxDays)
+		}
+		first, err := parseDay("from", *from)
+		if err != nil {
+			return err
+		}
+		start := first.Add(-12 * time.Hour)
+		last := first.AddDate(0, 0, *days-1)
+
+		f, err := os.Open(*path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		parsed, err := calendar.ParseICS(f, time.Local)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", *path, err)
+		}
+		// the day after the last covers the Isha window
+		events, unsupported := calendar.Expand(parsed, start, start.AddDate(0, 0, *days+1))
+		for _, e := range unsupported {
+			fmt.Fprintf(ctx.stderr, "Only checking the first meeting of '%s', its recurrence rule is not supported\n", e.Summary)
+		}
+
+		found, err := conflicts.Find(ctx.calculator, events, first, *days, conflicts.Options{
+			Window:  *window,
+			Delay:   *iqamah,
+			IshaEnd: params.IshaEnd(ctx.config),
+		})
+		if err != nil {
+			return calculationError(fmt.Errorf("calculating prayer times: %w", err))
+		}
+		if *format == "json" {
+			return writeConflictsJSON(ctx, found, first, last, *window)
+		}
+
+		if len(found) == 0 {
+			fmt.Fprintf(ctx.stdout, "No conflicts with prayer times from %s to %s\n", first.Format(time.DateOnly), last.Format(time.DateOnly))
+			return nil
+		}
+		clock := func(t time.Time) string { return t.In(time.Local).Format("15:04") }
+		for _, c := range found {
+			fmt.Fprintf(ctx.stdout, "%s %s %s, kept free %s–%s, conflicts with:\n", c.Time.In(time.Local).Format("Mon 2006-01-02"),
+				c.Prayer, clock(c.Time), clock(c.Block.Start), clock(c.Block.End))
+			for _, e := range c.Events {
+				fmt.Fprintf(ctx.stdout, "  %s–%s %s\n", clock(e.Start), clock(e.End), e.Summary)
+			}
+			if c.Free != nil {
+				fmt.Fprintf(ctx.stdout, "  Free slot: %s–%s\n", clock(c.Free.Start), clock(c.Free.End))
+			} else {
+				fmt.Fprintf(ctx.stdout, "  No free slot before the window ends at %s\n", clock(c.WindowEnd))
+			}
+		}
+		fmt.Fprintf(ctx.stdout, "%d conflict(s) from %s to %s\n", len(found), first.Format(time.DateOnly), last.Format(time.DateOnly))
+		return nil
+	}
+}
+
+// writeConflictsJSON prints the conflicts found by 'conflicts' for bots
+func writeConflictsJSON(ctx *runContext, found []conflicts.Conflict, first, last time.Time, window time.Duration) error {
+	type slot struct {
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+	}
+	type event struct {
+		UID     string    `json:"uid,omitempty"`
+		Summary string    `json:"summary"`
+		Start   time.Time `json:"start"`
+		End     time.Time `json:"end"`
+	}
+	type conflict struct {
+		Date       string    `json:"date"`
+		Prayer     string    `json:"prayer"`
+		PrayerTime time.Time `json:"prayer_time"`
+		KeptFree   slot      `json:"kept_free"`
+		WindowEnd  time.Time `json:"window_end"`
+		Events     []event   `json:"events"`
+		// null when the window has no free slot
+		FreeSlot *slot `json:"free_slot"`
+	}
+	out := struct {
+		From          string     `json:"from"`
+		To            string     `json:"to"`
+		WindowMinutes float64    `json:"window_minutes"`
+		Conflicts     []conflict `json:"conflicts"`
+	}{first.Format(time.DateOnly), last.Format(time.DateOnly), window.Minutes(), []conflict{}}
+
+	local := func(t time.Time) time.Time { return t.In(time.Local) }
+	for _, c := range found {
+		j := conflict{
+			Date:       local(c.Time).Format(time.DateOnly),
+			Prayer:     c.Prayer.String(),
+			PrayerTime: local(c.Time),
+			KeptFree:   slot{local(c.Block.Start), local(c.Block.End)},
+			WindowEnd:  local(c.WindowEnd),
+		}
+		for _, e := range c.Events {
+			j.Events = append(j.Events, event{e.UID, e.Summary, local(e.Start), local(e.End)})
+		}
+		if c.Free != nil {
+			j.FreeSlot = &slot{local(c.Free.Start), local(c.Free.End)}
+		}
+		out.Conflicts = append(out.Conflicts, j)
+	}
+	enc := json.NewEncoder(ctx.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// defaultBusyDays is the number of days 'export busy' covers when no --to is given
+const defaultBusyDays = 30
+
+func runExportBusy(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	from := fs.String("from", "", "first day (YYYY-MM-DD, default: today)")
+	to := fs.String("to", "", fmt.Sprintf("last day (YYYY-MM-DD, default: %d days from --from)", defaultBusyDays-1))
+	durations := fs.String("duration", "", "length of the block of each prayer, e.g. Dhuhr=20m,Asr=15m (default: 20m for every prayer)")
+
+	return func(ctx *runContext, args []string) error {
+		sizes := map[salah.Prayer]time.Duration{}
+		for _, p := range salah.Obligatory {
+			sizes[p] = conflicts.DefaultWindow
+		}
+		if *durations != "" {
+			var err error
+			if sizes, err = calendar.ParseBusyDurations(*durations); err != nil {
+				return usageError("%w", err)
+			}
+		}
+		first, err := parseDay("from", *from)
+		if err != nil {
+			return err
+		}
+		last := first.AddDate(0, 0, defaultBusyDays-1)
+		if *to != "" {
+			if last, err = parseDay("to", *to); err != nil {
+				return err
+			}
+		}
+		// days between middays, rounded as a DST change shortens or lengthens one
+		days := int(last.Sub(first).Round(24*time.Hour)/(24*time.Hour)) + 1
+		if days < 1 || days > conflicts.MaxDays {
+			return usageError("invalid range from %s to %s, must be 1 to %d days", first.Format(time.DateOnly), last.Format(time.DateOnly), conflicts.MaxDays)
+		}
+
+		dir, err := config.GetConfigDir()
+		if err != nil {
+			return err
+		}
+		salt, err := calendar.LoadBusySalt(filepath.Join(dir, calendar.BusySaltFileName))
+		if err != nil {
+			return fmt.Errorf("loading the salt of busy block UIDs: %w", err)
+		}
+		events, err := calendar.BusyEvents(ctx.calculator, first, days, sizes, salt)
+		if err != nil {
+			return calculationError(fmt.Errorf("calculating prayer times: %w", err))
+		}
+		return calendar.WriteICS(ctx.stdout, "-//salah-cli//busy times//EN", events)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecute_Conflicts(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12}`)
+	path := filepath.Join(t.TempDir(), "work.ics")
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:offsite\r\nSUMMARY:Offsite\r\nDTSTART:20250827T100000Z\r\nDTEND:20250827T160000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := os.WriteFile(path, []byte(ics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, _ := run("conflicts", "--calendar", path, "--from", "2025-08-27", "--days", "2", "--format", "json")
+	var out struct {
+		Conflicts []struct {
+			Prayer   string `json:"prayer"`
+			FreeSlot *struct {
+				Start time.Time `json:"start"`
+			} `json:"free_slot"`
+		} `json:"conflicts"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil || code != exitOK {
+		t.Fatalf("unexpected output %d %q: %v", code, stdout, err)
+	}
+	// Dhuhr ends at Asr, before the offsite does; Asr is free once it is over
+	c := out.Conflicts
+	if len(c) != 2 || c[0].Prayer != "Dhuhr" || c[0].FreeSlot != nil || c[1].FreeSlot == nil || !c[1].FreeSlot.Start.Equal(time.Date(2025, 8, 27, 16, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected conflicts %+v", c)
+	}
+
+	if code, stdout, _ := run("conflicts", "--calendar", path, "--from", "2025-08-28"); code != exitOK || !strings.HasPrefix(stdout, "No conflicts") {
+		t.Errorf("expected no conflicts the next day, got %d %q", code, stdout)
+	}
+	for _, args := range [][]string{{}, {"--calendar", path, "--window", "0s"}, {"--calendar", path, "--from", "tomorrow"}, {"--calendar", path, "--days", "0"}} {
+		if code, _, _ := run(append([]string{"conflicts"}, args...)...); code != exitUsage {
+			t.Errorf("%v: expected exit %d, got %d", args, exitUsage, code)
+		}
+	}
+}
+
+func TestExecute_ExportBusy(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12}`)
+	code, stdout, _ := run("export", "busy", "--from", "2025-08-27", "--to", "2025-08-29", "--duration", "Dhuhr=20m,Asr=15m")
+	if code != exitOK || strings.Count(stdout, "BEGIN:VEVENT") != 6 || strings.Count(stdout, "TRANSP:OPAQUE") != 6 {
+		t.Fatalf("expected 6 busy blocks, got %d %q", code, stdout)
+	}
+	if strings.Contains(stdout, "VALARM") || !strings.Contains(stdout, "SUMMARY:Busy\r\n") {
+		t.Errorf("expected blocks without details or alarms, got %q", stdout)
+	}
+	// neither the prayers nor the location are given away, in any form
+	for _, detail := range []string{"fajr", "sunrise", "dhuhr", "asr", "maghrib", "isha", "51.5", "0.12"} {
+		if strings.Contains(strings.ToLower(stdout), detail) {
+			t.Errorf("expected no %q in the feed, got %q", detail, stdout)
+		}
+	}
+
+	if _, stdout, _ := run("export", "busy", "--from", "2025-08-27", "--to", "2025-08-27"); strings.Count(stdout, "BEGIN:VEVENT") != 5 {
+		t.Errorf("expected a block for each prayer by default, got %q", stdout)
+	}
+	for _, args := range [][]string{{"--duration", "Dhuhr"}, {"--from", "2025-08-27", "--to", "2025-08-26"}, {"--to", "someday"}} {
+		if code, _, _ := run(append([]string{"export", "busy"}, args...)...); code != exitUsage {
+			t.Errorf("%v: expected exit %d, got %d", args, exitUsage, code)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"salah-cli/internal/config"
+	"salah-cli/internal/params"
+	"salah-cli/pkg/salah"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Exit codes of salah-cli, documented in the README and the man page
+const (
+	exitOK            = 0
+	exitFailure       = 1   // any other failure
+	exitUsage         = 2   // unknown command, invalid flag or wrong arguments
+	exitConfigMissing = 3   // no config file, and no location from the environment or flags
+	exitConfigInvalid = 4   // the config could not be read or is invalid
+	exitCalculation   = 5   // prayer times could not be calculated
+	exitInterrupted   = 130 // interrupted by SIGINT or SIGTERM, as shells report a SIGINT
+)
+
+// exitError is an error causing a specific exit code. Without err nothing is printed, for
+// commands that already reported the problem.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
+func usageError(format string, a ...any) error {
+	return &exitError{exitUsage, fmt.Errorf(format, a...)}
+}
+
+// configError classifies an error reading the config as a missing or an invalid config
+func configError(err error) error {
+	if errors.Is(err, config.ErrNoConfig) || errors.Is(err, fs.ErrNotExist) {
+		return &exitError{exitConfigMissing, err}
+	}
+	return &exitError{exitConfigInvalid, err}
+}
+
+func calculationError(err error) error {
+	return &exitError{exitCalculation, err}
+}
+
+// exitCode returns the exit code caused by an error returned by a command
+func exitCode(err error) int {
+	var exit *exitError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &exit):
+		return exit.code
+	default:
+		return exitFailure
+	}
+}
+
+// loadLevel is what the pre-run step loads for a command
+type loadLevel int
+
+const (
+	loadNothing    loadLevel = iota
+	loadConfig               // resolve the config, adding the override flags to the command
+	loadCalculator           // also build the prayer time calculator
+	loadSettings             // resolve the config like loadConfig, without requiring a location
+)
+
+// runContext is given to a running command, with its output and what the pre-run step loaded
+type runContext struct {
+	stdout, stderr io.Writer
+	overrides      config.Overrides
+	resolved       *config.Resolved
+	config         *config.Config
+	calculator     *salah.Calculator
+}
+
+// load runs the pre-run step of a command
+func (ctx *runContext) load(level loadLevel) error {
+	if level == loadNothing {
+		return nil
+	}
+	resolve := config.Resolve
+	if level == loadSettings {
+		resolve = config.ResolveSettings
+	}
+	resolved, err := resolve(ctx.overrides)
+	if err != nil {
+		return configError(fmt.Errorf("loading configuration: %w", err))
+	}
+	ctx.resolved, ctx.config = resolved, resolved.Config
+	if level != loadCalculator {
+		return nil
+	}
+	if ctx.calculator, err = params.BuildCalculator(ctx.config); err != nil {
+		return &exitError{exitConfigInvalid, fmt.Errorf("building calculation parameters: %w", err)}
+	}
+	return nil
+}
+
+// anyArgs is the nargs of commands taking a variable number of positional arguments
+const anyArgs = -1
+
+// command is a salah-cli command. Its flags are defined once, on the flag set given to define,
+// which is used to parse them as well as for help, shell completion and the man page.
+type command struct {
+	name    string
+	args    string // positional arguments, e.g. "KEY VALUE"
+	nargs   int    // number of positional arguments, or anyArgs
+	summary string
+	load    loadLevel
+	// define adds the command's flags to fs and returns the function running the command
+	define func(fs *flag.FlagSet) func(ctx *runContext, args []string) error
+	// complete lists the candidates for the next positional argument, given the previous ones
+	complete    func(prev []string) []string
+	subcommands []*command
+	hidden      bool
+	rawArgs     bool // pass the arguments on without parsing flags
+}
+
+// commands lists every salah-cli command, in the order shown by help
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "today", summary: "Show today's prayer times", load: loadCalculator, define: runToday},
+		{name: "next", summary: "Show the next upcoming prayer time", load: loadCalculator, define: runNext},
+		{name: "current", summary: "Show the prayer window active now and the time left in it", load: loadCalculator, define: runCurrent},
+		{name: "wait", summary: "Wait until a prayer time, for use in scripts", load: loadCalculator, define: runWait},
+		{name: "schedule", summary: "Run a command at prayer times with cron, systemd or at", subcommands: []*command{
+			{name: "export", summary: "Print the jobs running a command at the coming prayer times", load: loadCalculator, define: runScheduleExport},
+			{name: "install", summary: "Install the jobs, replacing those installed before", load: loadCalculator, define: runScheduleInstall},
+		}},
+		{name: "hooks", summary: "Run commands on prayer time events", subcommands: []*command{
+			{name: "run", summary: "Run the hooks of the config as they occur, until interrupted", load: loadCalculator, define: runHooksRun},
+			{name: "test", args: "EVENT", nargs: 1, summary: "Run the hooks of an event now", load: loadCalculator, define: runHooksTest, complete: completeHookEvent},
+		}},
+		{name: "export", summary: "Export prayer times to other tools", subcommands: []*command{
+			{name: "busy", summary: "Print an iCalendar feed of busy blocks at prayer times", load: loadCalculator, define: runExportBusy},
+		}},
+		{name: "travel", summary: "Plan prayers on a journey", subcommands: []*command{
+			{name: "plan", args: "ITINERARY", nargs: 1, summary: "Show the prayer times met along the flights of a YAML itinerary", load: loadSettings, define: runTravelPlan},
+		}},
+		{name: "conflicts", summary: "Report meetings in an iCalendar file that collide with prayer times", load: loadCalculator, define: runConflicts},
+		{name: "publish", summary: "Publish prayer times to the MQTT broker and webhooks of the config", load: loadCalculator, define: runPublish},
+		{name: "validate-config", summary: "Validate the config file", define: runValidateConfig},
+		{name: "config", summary: "Show or change the config", subcommands: []*command{
+			{name: "show", summary: "Show the effective config", load: loadConfig, define: runConfigShow},
+			{name: "get", args: "KEY", nargs: 1, summary: "Show the effective value of an option", load: loadConfig, define: runConfigGet, complete: completeConfigKey(false)},
+			{name: "set", args: "KEY VALUE", nargs: 2, summary: "Change an option in the config file", define: runConfigSet, complete: completeConfigSet},
+			{name: "unset", args: "KEY", nargs: 1, summary: "Remove an optional option from the config file", define: runConfigUnset, complete: completeConfigKey(true)},
+			{name: "edit", summary: "Edit the config file in $EDITOR, validating it before saving", define: runConfigEdit},
+			{name: "schema", summary: "Print the JSON Schema of the config file", define: runConfigSchema},
+			{name: "migrate", summary: "Upgrade the config file to the latest version", define: runConfigMigrate},
+		}},
+		{name: "config-docs", summary: "Show the config reference", define: runConfigDocs},
+		{name: "log", args: "PRAYER", nargs: 1, summary: "Record a completed prayer", define: runLog, complete: completePrayer},
+		{name: "qada", summary: "Show outstanding missed prayers", load: loadCalculator, define: runQada},
+		{name: "stats", summary: "Show streaks and on-time percentages", load: loadCalculator, define: runStats},
+		{name: "serve", summary: "Serve prayer times as a JSON API with Prometheus metrics", load: loadConfig, define: runServe},
+		{name: "setup", summary: "Create or update the config file interactively", define: runSetup},
+		{name: "completion", args: "SHELL", nargs: 1, summary: "Print a completion script for bash, zsh, fish or powershell", define: runCompletion, complete: completeShell},
+		{name: "man", summary: "Print the man page", define: runMan},
+		{name: "help", args: "[COMMAND]", nargs: anyArgs, summary: "Show help for salah-cli or a command", define: runHelp, complete: completeCommand},
+		{name: completeCommandName, nargs: anyArgs, define: runComplete, hidden: true, rawArgs: true},
+	}
+}
+
+// findCommand returns the command named by the leading arguments and the arguments following
+// it. The command is nil if the arguments don't name one; a command with subcommands is
+// returned when no subcommand is given.
+func findCommand(list []*command, args []string) (*command, []string) {
+	var found *command
+	for len(args) > 0 {
+		i := slices.IndexFunc(list, func(c *command) bool { return c.name == args[0] })
+		if i < 0 {
+			break
+		}
+		found, list, args = list[i], list[i].subcommands, args[1:]
+	}
+	return found, args
+}
+
+// commandPath returns the full name of a command, e.g. "config set"
+func commandPath(cmd *command) string {
+	var path func(list []*command, prefix string) string
+	path = func(list []*command, prefix string) string {
+		for _, c := range list {
+			name := strings.TrimSpace(prefix + " " + c.name)
+			if c == cmd {
+				return name
+			}
+			if p := path(c.subcommands, name); p != "" {
+				return p
+			}
+		}
+		return ""
+	}
+	return path(commands, "")
+}
+
+// visible returns the commands shown in help, completion and the man page
+func visible(list []*command) []*command {
+	var out []*command
+	for _, c := range list {
+		if !c.hidden {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// leafCommands returns the runnable commands, depth first
+func leafCommands(list []*command) []*command {
+	var out []*command
+	for _, c := range visible(list) {
+		if c.define != nil {
+			out = append(out, c)
+		}
+		out = append(out, leafCommands(c.subcommands)...)
+	}
+	return out
+}
+
+// newFlagSet returns the flag set of a command, with its flags defined, and the function
+// running it. Override flags are added for commands loading the config.
+func newFlagSet(cmd *command, ctx *runContext) (*flag.FlagSet, func(ctx *runContext, args []string) error) {
+	fs := flag.NewFlagSet("salah-cli "+commandPath(cmd), flag.ContinueOnError)
+	fs.SetOutput(ctx.stderr)
+	if cmd.load != loadNothing {
+		ctx.overrides.AddFlags(fs)
+	}
+	run := cmd.define(fs)
+	fs.Usage = func() { printCommandHelp(fs.Output(), cmd, fs) }
+	return fs, run
+}
+
+// flagSetOf returns the flags of a command, for help, completion and the man page
+func flagSetOf(cmd *command) *flag.FlagSet {
+	fs, _ := newFlagSet(cmd, &runContext{stdout: io.Discard, stderr: io.Discard, overrides: config.Overrides{}})
+	return fs
+}
+
+// execute runs the command named by args and returns the exit code. Errors are written to
+// stderr.
+func execute(args []string, stdout, stderr io.Writer) int {
+	err := runCommand(args, &runContext{stdout: stdout, stderr: stderr, overrides: config.Overrides{}})
+	if err != nil && err.Error() != "" {
+		fmt.Fprintln(stderr, "Error:", err)
+	}
+	return exitCode(err)
+}
+
+func runCommand(args []string, ctx *runContext) error {
+	if len(args) == 0 || args[0] == "--help" || args[0] == "-h" {
+		printHelp(ctx.stdout)
+		return nil
+	}
+	cmd, rest := findCommand(commands, args)
+	if cmd == nil {
+		return usageError("unknown command %s, run 'salah-cli help' for usage", args[0])
+	}
+	if cmd.define == nil {
+		printCommandHelp(ctx.stderr, cmd, nil)
+		if len(rest) > 0 && !isFlagArg(rest[0]) {
+			return usageError("unknown %s command %s", cmd.name, rest[0])
+		}
+		return &exitError{code: exitUsage}
+	}
+
+	fs, run := newFlagSet(cmd, ctx)
+	if cmd.rawArgs {
+		return run(ctx, rest)
+	}
+	flags, positional, _ := splitArgs(fs, rest)
+	if err := fs.Parse(flags); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		// the flag package has printed the error and usage
+		return &exitError{code: exitUsage}
+	}
+	if cmd.nargs != anyArgs && len(positional) != cmd.nargs {
+		return usageError("usage: %s", usageLine(cmd, fs))
+	}
+	if err := ctx.load(cmd.load); err != nil {
+		return err
+	}
+	return run(ctx, positional)
+}
+
+// isFlagArg reports whether an argument is a flag. Negative numbers are not, so that
+// 'config set adjustments.FajrAdj -2' works.
+func isFlagArg(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
+
+// splitArgs separates flags, with their values, from positional arguments, so that flags may
+// be given either side of them. pending is the flag still expecting a value at the end.
+func splitArgs(fs *flag.FlagSet, args []string) (flags, positional []string, pending *flag.Flag) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return flags, append(positional, args[i+1:]...), nil
+		case !isFlagArg(arg):
+			positional = append(positional, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		f := fs.Lookup(name)
+		if f == nil || isBoolFlag(f) {
+			continue
+		}
+		if i+1 == len(args) {
+			return flags, positional, f
+		}
+		i++
+		flags = append(flags, args[i])
+	}
+	return flags, positional, nil
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// choiceValue is a string flag restricted to a fixed set of values, which are also completed
+type choiceValue struct {
+	value   string
+	choices []string
+}
+
+// choiceFlag defines a string flag accepting only the given choices
+func choiceFlag(fs *flag.FlagSet, name, value, usage string, choices ...string) *string {
+	c := &choiceValue{value: value, choices: choices}
+	fs.Var(c, name, fmt.Sprintf("%s: %s", usage, strings.Join(choices, ", ")))
+	return &c.value
+}
+
+func (c *choiceValue) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.value
+}
+
+func (c *choiceValue) Set(s string) error {
+	if !slices.Contains(c.choices, s) {
+		return fmt.Errorf("allowed: %s", strings.Join(c.choices, ", "))
+	}
+	c.value = s
+	return nil
+}
+
+// usageLine returns a command's synopsis, e.g. "salah-cli config set [flags] KEY VALUE"
+func usageLine(cmd *command, fs *flag.FlagSet) string {
+	line := "salah-cli " + commandPath(cmd)
+	if len(cmd.subcommands) > 0 {
+		line += " COMMAND"
+	}
+	if fs != nil && hasFlags(fs) {
+		line += " [flags]"
+	}
+	if cmd.args != "" {
+		line += " " + cmd.args
+	}
+	return line
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+func printHelp(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var overridable []string
+	for _, cmd := range leafCommands(commands) {
+		line := "salah-cli " + commandPath(cmd)
+		if cmd.args != "" {
+			line += " " + cmd.args
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", line, cmd.summary)
+		if cmd.load != loadNothing {
+			overridable = append(overridable, commandPath(cmd))
+		}
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'salah-cli help COMMAND' for the flags of a command. These commands accept")
+	fmt.Fprintln(w, "--lat, --lon, --method and --madhab to override the config, which can also be set")
+	fmt.Fprintln(w, "with SALAH_* environment variables:")
+	fmt.Fprintf(w, "  %s\n", strings.Join(overridable, ", "))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes:")
+	fmt.Fprintln(w, "  0    success")
+	fmt.Fprintln(w, "  1    other failure")
+	fmt.Fprintln(w, "  2    usage error: unknown command, invalid flag or wrong arguments")
+	fmt.Fprintln(w, "  3    config missing: no config file, and no location from SALAH_* or flags")
+	fmt.Fprintln(w, "  4    config invalid: the config could not be read or failed validation")
+	fmt.Fprintln(w, "  5    calculation failure: prayer times could not be calculated")
+	fmt.Fprintln(w, "  130  interrupted by SIGINT or SIGTERM")
+}
+
+// printCommandHelp prints the usage of a command. fs holds its flags and may be nil for
+// commands with subcommands.
+func printCommandHelp(w io.Writer, cmd *command, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s\n\n%s\n", usageLine(cmd, fs), cmd.summary)
+	if subs := visible(cmd.subcommands); len(subs) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, sub := range subs {
+			fmt.Fprintf(tw, "  %s\t%s\n", strings.TrimSpace(sub.name+" "+sub.args), sub.summary)
+		}
+		_ = tw.Flush()
+	}
+	if fs != nil && hasFlags(fs) {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+func runHelp(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		if len(args) == 0 {
+			printHelp(ctx.stdout)
+			return nil
+		}
+		cmd, rest := findCommand(commands, args)
+		if cmd == nil || len(rest) > 0 {
+			return usageError("unknown command %s", strings.Join(args, " "))
+		}
+		if cmd.define == nil {
+			printCommandHelp(ctx.stdout, cmd, nil)
+			return nil
+		}
+		printCommandHelp(ctx.stdout, cmd, flagSetOf(cmd))
+		return nil
+	}
+}
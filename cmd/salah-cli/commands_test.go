@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		path string
+		rest []string
+	}{
+		{[]string{"today", "--lat", "1"}, "today", []string{"--lat", "1"}},
+		{[]string{"config", "set", "method", "isna"}, "config set", []string{"method", "isna"}},
+		{[]string{"config"}, "config", nil},
+		{[]string{"config", "nope"}, "config", []string{"nope"}},
+		{[]string{"nope"}, "", []string{"nope"}},
+	}
+	for _, tt := range tests {
+		cmd, rest := findCommand(commands, tt.args)
+		path := ""
+		if cmd != nil {
+			path = commandPath(cmd)
+		}
+		if path != tt.path || !slices.Equal(rest, tt.rest) {
+			t.Errorf("%v: expected %q %v, got %q %v", tt.args, tt.path, tt.rest, path, rest)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("late", false, "")
+	fs.String("date", "", "")
+
+	flags, positional, pending := splitArgs(fs, []string{"--late", "fajr", "--date", "2024-01-01", "-2", "--", "--late"})
+	if !slices.Equal(flags, []string{"--late", "--date", "2024-01-01"}) {
+		t.Errorf("unexpected flags %v", flags)
+	}
+	if !slices.Equal(positional, []string{"fajr", "-2", "--late"}) {
+		t.Errorf("unexpected positional arguments %v", positional)
+	}
+	if pending != nil {
+		t.Errorf("expected no pending flag, got %s", pending.Name)
+	}
+
+	if _, _, pending := splitArgs(fs, []string{"fajr", "--date"}); pending == nil || pending.Name != "date" {
+		t.Errorf("expected --date to be pending, got %v", pending)
+	}
+	if _, _, pending := splitArgs(fs, []string{"--date=2024-01-01"}); pending != nil {
+		t.Errorf("expected no pending flag, got %s", pending.Name)
+	}
+}
+
+func TestChoiceFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	format := choiceFlag(fs, "format", "text", "output format", "text", "json")
+	if err := fs.Parse([]string{"--format", "json"}); err != nil || *format != "json" {
+		t.Errorf("expected json, got %q (%v)", *format, err)
+	}
+	if err := fs.Parse([]string{"--format", "xml"}); err == nil || !strings.Contains(err.Error(), "allowed: text, json") {
+		t.Errorf("expected allowed values in error, got %v", err)
+	}
+	if usage := fs.Lookup("format").Usage; usage != "output format: text, json" {
+		t.Errorf("unexpected usage %q", usage)
+	}
+}
+
+func TestPrintHelp(t *testing.T) {
+	var buf bytes.Buffer
+	printHelp(&buf)
+	for _, cmd := range leafCommands(commands) {
+		if !strings.Contains(buf.String(), "salah-cli "+commandPath(cmd)) {
+			t.Errorf("expected help to list %s", commandPath(cmd))
+		}
+	}
+	if strings.Contains(buf.String(), completeCommandName) {
+		t.Error("expected hidden commands to be left out")
+	}
+}
+
+func TestPrintCommandHelp(t *testing.T) {
+	cmd, _ := findCommand(commands, []string{"stats"})
+	fs := flagSetOf(cmd)
+	var buf bytes.Buffer
+	printCommandHelp(&buf, cmd, fs)
+	for _, want := range []string{"Usage: salah-cli stats [flags]", "-period", "week, month", "-method"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected help to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"salah-cli/internal/config"
+	"salah-cli/internal/journal"
+	"slices"
+	"strings"
+)
+
+// completeCommandName is the hidden command the completion scripts call with the words on the
+// command line, the last being the word to complete. It prints one candidate per line.
+const completeCommandName = "__complete"
+
+// completionScripts are the scripts printed by 'salah-cli completion', all deferring to
+// 'salah-cli __complete' so that candidates come from the command definitions
+var completionScripts = map[string]string{
+	"bash": `# bash completion for salah-cli
+# Load with: source <(salah-cli completion bash)
+_salah_cli() {
+    local IFS=$'\n'
+    COMPREPLY=($(salah-cli __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _salah_cli salah-cli
+`,
+	"zsh": `#compdef salah-cli
+# zsh completion for salah-cli
+# Load with: source <(salah-cli completion zsh)
+_salah_cli() {
+    local -a candidates
+    candidates=(${(f)"$(salah-cli __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    (( ${#candidates} )) && compadd -a candidates || _files
+}
+compdef _salah_cli salah-cli
+`,
+	"fish": `# fish completion for salah-cli
+# Load with: salah-cli completion fish | source
+function __salah_cli_complete
+    set -l words (commandline -opc)
+    salah-cli __complete $words[2..-1] (commandline -ct | string collect -a) 2>/dev/null
+end
+complete -c salah-cli -f -a '(__salah_cli_complete)'
+`,
+	"powershell": `# PowerShell completion for salah-cli
+# Load with: salah-cli completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName 'salah-cli' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') {
+        # older versions drop empty arguments to native commands
+        $words += '""'
+    }
+    salah-cli __complete @words 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`,
+}
+
+// shells lists the shells with a completion script
+var shells = []string{"bash", "zsh", "fish", "powershell"}
+
+func runCompletion(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		script, ok := completionScripts[args[0]]
+		if !ok {
+			return usageError("unknown shell %s, expected one of %s", args[0], strings.Join(shells, ", "))
+		}
+		fmt.Fprint(ctx.stdout, script)
+		return nil
+	}
+}
+
+func runComplete(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		for _, c := range completions(args) {
+			fmt.Fprintln(ctx.stdout, c)
+		}
+		return nil
+	}
+}
+
+// completions returns the candidates for the last of words, the arguments after 'salah-cli'
+func completions(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current, words := words[len(words)-1], words[:len(words)-1]
+	if current == `""` {
+		current = ""
+	}
+
+	cmd, rest := findCommand(commands, words)
+	var candidates []string
+	switch {
+	case cmd == nil && len(words) > 0:
+		return nil
+	case cmd == nil:
+		candidates = commandNames(commands)
+	case cmd.define == nil:
+		if len(rest) > 0 {
+			return nil
+		}
+		candidates = commandNames(cmd.subcommands)
+	default:
+		candidates = argCompletions(cmd, rest, current)
+	}
+
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, current) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// argCompletions returns the candidates for flags, flag values and positional arguments of a
+// command, given the arguments before the one being completed
+func argCompletions(cmd *command, args []string, current string) []string {
+	fs := flagSetOf(cmd)
+	_, positional, pending := splitArgs(fs, args)
+	switch {
+	case pending != nil:
+		return flagValues(pending)
+	case strings.HasPrefix(current, "-"):
+		var out []string
+		name, _, hasValue := strings.Cut(strings.TrimLeft(current, "-"), "=")
+		if hasValue {
+			if f := fs.Lookup(name); f != nil {
+				for _, v := range flagValues(f) {
+					out = append(out, "--"+name+"="+v)
+				}
+			}
+			return out
+		}
+		fs.VisitAll(func(f *flag.Flag) { out = append(out, "--"+f.Name) })
+		return out
+	case cmd.complete != nil:
+		return cmd.complete(positional)
+	}
+	return nil
+}
+
+// flagValues returns the values of a flag with a fixed set of values
+func flagValues(f *flag.Flag) []string {
+	if c, ok := f.Value.(*choiceValue); ok {
+		return c.choices
+	}
+	for _, o := range config.OverrideFlags {
+		if o.Name == f.Name {
+			return optionValues(o.Key)
+		}
+	}
+	return nil
+}
+
+func commandNames(list []*command) []string {
+	var names []string
+	for _, c := range visible(list) {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// configOptions returns the config options by dotted key. Objects are included with
+// withObjects, their keys always are.
+func configOptions(withObjects bool) ([]string, map[string]config.FieldDoc) {
+	var keys []string
+	docs := map[string]config.FieldDoc{}
+	var walk func(prefix string, list []config.FieldDoc)
+	walk = func(prefix string, list []config.FieldDoc) {
+		for _, d := range list {
+			key := prefix + d.Key
+			if len(d.Keys) == 0 || withObjects {
+				keys = append(keys, key)
+				docs[key] = d
+			}
+			walk(key+".", d.Keys)
+		}
+	}
+	walk("", config.Docs())
+	return keys, docs
+}
+
+// optionValues returns the values of a config option with a fixed set of values, such as the
+// method names or highlight colours
+func optionValues(key string) []string {
+	_, docs := configOptions(false)
+	d, ok := docs[key]
+	if !ok {
+		return nil
+	}
+	if d.Type == "boolean" {
+		return []string{"true", "false"}
+	}
+	var values []string
+	for _, c := range d.Choices {
+		values = append(values, c.Value)
+	}
+	return values
+}
+
+// completeConfigKey completes the key of 'config get' and 'config unset'
+func completeConfigKey(withObjects bool) func(prev []string) []string {
+	return func(prev []string) []string {
+		if len(prev) > 0 {
+			return nil
+		}
+		keys, _ := configOptions(withObjects)
+		return keys
+	}
+}
+
+func completeConfigSet(prev []string) []string {
+	switch len(prev) {
+	case 0:
+		keys, _ := configOptions(false)
+		return slices.DeleteFunc(keys, func(k string) bool { return k == "version" })
+	case 1:
+		return optionValues(prev[0])
+	}
+	return nil
+}
+
+func completePrayer(prev []string) []string {
+	if len(prev) > 0 {
+		return nil
+	}
+	var names []string
+	for _, p := range journal.Prayers {
+		names = append(names, strings.ToLower(p))
+	}
+	return names
+}
+
+// completeHookEvent completes the events of the hooks in the config
+func completeHookEvent(prev []string) []string {
+	cfg, err := config.Load()
+	if len(prev) > 0 || err != nil {
+		return nil
+	}
+	var events []string
+	for _, h := range cfg.Hooks {
+		if !slices.Contains(events, h.Event) {
+			events = append(events, h.Event)
+		}
+	}
+	return events
+}
+
+func completeShell(prev []string) []string {
+	if len(prev) > 0 {
+		return nil
+	}
+	return slices.Clone(shells)
+}
+
+// completeCommand completes the command names of 'salah-cli help'
+func completeCommand(prev []string) []string {
+	cmd, rest := findCommand(commands, prev)
+	switch {
+	case len(prev) == 0:
+		return commandNames(commands)
+	case cmd != nil && len(rest) == 0:
+		return commandNames(cmd.subcommands)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompletions(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"con"}, []string{"conflicts", "config", "config-docs"}},
+		{[]string{"config", "s"}, []string{"show", "set", "schema"}},
+		{[]string{"today", "--m"}, []string{"--madhab", "--method"}},
+		{[]string{"today", "--madhab", ""}, []string{"shafi", "hanafi"}},
+		{[]string{"qada", "--madhab=h"}, []string{"--madhab=hanafi"}},
+		{[]string{"today", "--method", "north"}, []string{"north_america"}},
+		{[]string{"validate-config", "--format", ""}, []string{"text", "json"}},
+		{[]string{"stats", "--period", "m"}, []string{"month"}},
+		{[]string{"log", "--late", "f"}, []string{"fajr"}},
+		{[]string{"log", "fajr", ""}, nil},
+		{[]string{"config", "get", "adjustments.F"}, []string{"adjustments.FajrAdj"}},
+		{[]string{"config", "unset", "adj"}, []string{"adjustments", "adjustments.FajrAdj", "adjustments.SunriseAdj", "adjustments.DhuhrAdj", "adjustments.AsrAdj", "adjustments.MaghribAdj", "adjustments.IshaAdj"}},
+		{[]string{"config", "set", "v"}, nil},
+		{[]string{"config", "set", "highlight_colour", "c"}, []string{"cyan"}},
+		{[]string{"config", "set", "enable_countdown", ""}, []string{"true", "false"}},
+		{[]string{"config", "set", "method", "umm"}, []string{"umm_al_qura"}},
+		{[]string{"completion", "p"}, []string{"powershell"}},
+		{[]string{"help", "config", "m"}, []string{"migrate"}},
+		{[]string{"__c"}, nil},
+		{[]string{"nope", ""}, nil},
+	}
+	for _, tt := range tests {
+		if got := completions(tt.words); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.words, tt.want, got)
+		}
+	}
+
+	// PowerShell passes an empty word as ""
+	if got := completions([]string{"config", "set", `""`}); !slices.Contains(got, "latitude") || slices.Contains(got, "version") {
+		t.Errorf("expected every key but version, got %v", got)
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range shells {
+		script := completionScripts[shell]
+		if script == "" {
+			t.Errorf("expected a %s script", shell)
+		}
+		if !slices.Contains(completeShell(nil), shell) {
+			t.Errorf("expected %s to be completed", shell)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"salah-cli/internal/config"
+	"salah-cli/internal/diff"
+	"salah-cli/internal/params"
+	"salah-cli/internal/prayers"
+	"strings"
+	"text/tabwriter"
+)
+
+func runValidateConfig(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	format := choiceFlag(fs, "format", "text", "output format", "text", "json")
+	return func(ctx *runContext, args []string) error {
+		path, err := config.GetConfigPath()
+		if err != nil {
+			return configError(fmt.Errorf("failed to load config: %w", err))
+		}
+		_, problems, err := config.CheckFile(path)
+		if err != nil {
+			return configError(fmt.Errorf("failed to load config: %w", err))
+		}
+		valid := !config.HasErrors(problems)
+
+		if *format == "json" {
+			enc := json.NewEncoder(ctx.stdout)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(struct {
+				Path     string           `json:"path"`
+				Valid    bool             `json:"valid"`
+				Problems []config.Problem `json:"problems"`
+			}{path, valid, append([]config.Problem{}, problems...)})
+		} else {
+			for _, p := range problems {
+				fmt.Fprintf(ctx.stdout, "%s: %s: %s\n", p.At(path), p.Severity, p.Message)
+			}
+			if valid {
+				fmt.Fprintln(ctx.stdout, "✅ Config is valid!")
+			} else {
+				fmt.Fprintf(ctx.stdout, "❌ Invalid config: %d problem(s) found\n", len(problems))
+			}
+		}
+		if !valid {
+			// the problems are the command's output, so there is nothing more to report
+			return &exitError{code: exitConfigInvalid}
+		}
+		return nil
+	}
+}
+
+func runSetup(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		existing, existingPath, err := config.LoadUserConfig()
+		if err != nil {
+			// start over rather than make the user fix the file first
+			fmt.Fprintf(ctx.stderr, "Ignoring invalid existing config: %v\n", err)
+			existing = &config.Config{}
+			if existingPath, err = config.GetConfigPath(); err != nil {
+				return configError(err)
+			}
+		}
+		overwrite := existingPath
+		if _, err := os.Stat(existingPath); err != nil {
+			overwrite = ""
+		}
+
+		generatedConfig, format, err := config.SetupConfig(existing, overwrite, previewTimes)
+		if errors.Is(err, config.ErrSetupCancelled) {
+			fmt.Fprintln(ctx.stdout, err.Error())
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if format == config.FormatJSON {
+			generatedConfig.Schema = existing.Schema
+		}
+
+		configPath := existingPath
+		if current, _ := config.FormatOf(existingPath); current != format {
+			configPath = config.PathWithFormat(existingPath, format)
+		}
+		if err := config.SaveConfig(generatedConfig, configPath); err != nil {
+			return fmt.Errorf("failed to save created config: %w", err)
+		}
+
+		// keep a single config file, so the new one is the one loaded
+		if configPath != existingPath {
+			if _, err := os.Stat(existingPath); err == nil {
+				if err := os.Rename(existingPath, existingPath+".bak"); err != nil {
+					return fmt.Errorf("failed to move previous config aside: %w", err)
+				}
+				fmt.Fprintf(ctx.stdout, "Moved previous config to %s.bak\n", existingPath)
+			}
+		}
+		fmt.Fprintf(ctx.stdout, "Successfully written config file to %s\n", configPath)
+		return nil
+	}
+}
+
+// previewTimes formats today's prayer times for a config, for review before it is saved
+func previewTimes(cfg *config.Config) string {
+	plain := *cfg
+	plain.EnableHighlighting = false
+	calculator, err := params.BuildCalculator(&plain)
+	if err != nil {
+		return "⚠ " + err.Error()
+	}
+	todays, err := prayers.GetTodaysPrayerTimes(calculator)
+	if err != nil {
+		return "⚠ " + err.Error()
+	}
+	return prayers.FormatPrayerTimes(todays, &plain)
+}
+
+func runConfigShow(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	origin := fs.Bool("origin", false, "show where each value comes from")
+	return func(ctx *runContext, args []string) error {
+		w := tabwriter.NewWriter(ctx.stdout, 0, 0, 2, ' ', 0)
+		for _, s := range ctx.resolved.Settings {
+			s = s.Masked()
+			if *origin {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, s.Origin)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\n", s.Key, s.Value)
+			}
+		}
+		return w.Flush()
+	}
+}
+
+func runConfigGet(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		setting, err := ctx.resolved.Get(args[0])
+		if err != nil {
+			return usageError("%w", err)
+		}
+		setting = setting.Masked()
+		// print strings bare, for use in scripts
+		var value string
+		if json.Unmarshal([]byte(setting.Value), &value) != nil {
+			value = setting.Value
+		}
+		fmt.Fprintln(ctx.stdout, value)
+		return nil
+	}
+}
+
+func runConfigSet(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		key, value := args[0], args[1]
+		return updateUserConfig(ctx, key, fmt.Sprintf("Set %s to %s", key, value), func(cfg *config.Config) error { return cfg.Set(key, value) })
+	}
+}
+
+func runConfigUnset(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		key := args[0]
+		return updateUserConfig(ctx, key, "Unset "+key, func(cfg *config.Config) error { return cfg.Unset(key) })
+	}
+}
+
+// updateUserConfig applies change to the user config file and saves it if the result is valid,
+// printing done on success
+func updateUserConfig(ctx *runContext, key, done string, change func(*config.Config) error) error {
+	cfg, path, err := config.LoadUserConfig()
+	if err != nil {
+		return configError(fmt.Errorf("loading configuration: %w", err))
+	}
+	if err := change(cfg); err != nil {
+		return usageError("%w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return &exitError{exitConfigInvalid, err}
+	}
+	if err := config.SaveChange(cfg, path, key); err != nil {
+		return fmt.Errorf("saving configuration: %w", err)
+	}
+	fmt.Fprintln(ctx.stdout, done)
+
+	// the new value has no effect while a higher layer sets the option
+	if resolved, err := config.Resolve(nil); err == nil {
+		for _, s := range resolved.Settings {
+			if (s.Key == key || strings.HasPrefix(s.Key, key+".")) && (s.Origin.Layer == config.LayerEnv || s.Origin.Layer == config.LayerFlag) {
+				fmt.Fprintf(ctx.stdout, "Note: %s is overridden by %s\n", s.Key, s.Origin.Source)
+			}
+		}
+	}
+	return nil
+}
+
+// editorCommand returns the user's editor and its arguments
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(name)); len(editor) > 0 {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+func runConfigEdit(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		cfg, path, err := config.LoadUserConfig()
+		if err != nil {
+			return configError(fmt.Errorf("loading configuration: %w (fix it in %s directly)", err, path))
+		}
+		format, err := config.FormatOf(path)
+		if err != nil {
+			return configError(err)
+		}
+		original, err := os.ReadFile(path)
+		if err != nil {
+			if original, err = config.Encode(cfg, format); err != nil {
+				return err
+			}
+		}
+
+		// edit a copy, so the config is only replaced once it is valid
+		tmp, err := os.CreateTemp("", "salah-cli-*"+filepath.Ext(path))
+		if err != nil {
+			return fmt.Errorf("creating temp file: %w", err)
+		}
+		tmpPath := tmp.Name()
+		defer os.Remove(tmpPath)
+		_, err = tmp.Write(original)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("writing temp file: %w", err)
+		}
+
+		stdin := bufio.NewReader(os.Stdin)
+		for {
+			editor := editorCommand()
+			cmd := exec.Command(editor[0], append(editor[1:], tmpPath)...)
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("running editor %s: %w", editor[0], err)
+			}
+
+			edited, problems, err := config.CheckFile(tmpPath)
+			if err != nil {
+				return err
+			}
+			for _, p := range problems {
+				fmt.Fprintf(ctx.stdout, "%s: %s: %s\n", p.At(path), p.Severity, p.Message)
+			}
+			if !config.HasErrors(problems) {
+				cfg = edited
+				break
+			}
+			fmt.Fprint(ctx.stdout, "Edit again? [Y/n] ")
+			answer, _ := stdin.ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "n" || answer == "no" {
+				return &exitError{exitConfigInvalid, errors.New("config left unchanged")}
+			}
+		}
+
+		data, err := os.ReadFile(tmpPath)
+		if err != nil {
+			return fmt.Errorf("reading temp file: %w", err)
+		}
+		if bytes.Equal(data, original) {
+			fmt.Fprintln(ctx.stdout, "No changes")
+			return nil
+		}
+		if err := cfg.Validate(); err != nil {
+			return &exitError{exitConfigInvalid, err}
+		}
+		// save the file as edited, with its comments and the values as written
+		if err := config.WriteFile(path, data); err != nil {
+			return fmt.Errorf("saving configuration: %w", err)
+		}
+		fmt.Fprintf(ctx.stdout, "Saved %s\n", path)
+		return nil
+	}
+}
+
+func runConfigSchema(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	write := fs.Bool("write", false, "write the schema next to the config file and reference it from config.json")
+	return func(ctx *runContext, args []string) error {
+		schema, err := config.JSONSchema()
+		if err != nil {
+			return err
+		}
+		if !*write {
+			_, err := ctx.stdout.Write(schema)
+			return err
+		}
+
+		cfg, path, err := config.LoadUserConfig()
+		if err != nil {
+			return configError(fmt.Errorf("loading configuration: %w", err))
+		}
+		schemaPath := filepath.Join(filepath.Dir(path), config.SchemaFileName)
+		if err := os.MkdirAll(filepath.Dir(schemaPath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(schemaPath, schema, 0o644); err != nil {
+			return fmt.Errorf("writing schema: %w", err)
+		}
+		fmt.Fprintf(ctx.stdout, "Wrote %s\n", schemaPath)
+
+		ref := "./" + config.SchemaFileName
+		format, _ := config.FormatOf(path)
+		switch {
+		case format == config.FormatYAML:
+			fmt.Fprintf(ctx.stdout, "Add this comment to the top of %s for editor support:\n# yaml-language-server: $schema=%s\n", path, ref)
+		case format == config.FormatTOML:
+			fmt.Fprintf(ctx.stdout, "Add this comment to the top of %s for editor support:\n#:schema %s\n", path, ref)
+		case cfg.Schema == ref:
+		default:
+			if _, err := os.Stat(path); err != nil {
+				fmt.Fprintf(ctx.stdout, "Reference it with \"$schema\": \"%s\" once %s exists ('salah-cli setup')\n", ref, path)
+				return nil
+			}
+			cfg.Schema = ref
+			if err := config.SaveConfig(cfg, path); err != nil {
+				return fmt.Errorf("saving configuration: %w", err)
+			}
+			fmt.Fprintf(ctx.stdout, "Referenced it from %s\n", path)
+		}
+		return nil
+	}
+}
+
+func runConfigMigrate(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	dryRun := fs.Bool("dry-run", false, "show the changes without writing the config")
+	return func(ctx *runContext, args []string) error {
+		path, err := config.GetConfigPath()
+		if err != nil {
+			return configError(err)
+		}
+		result, err := config.Migrate(path, *dryRun)
+		if err != nil {
+			return configError(fmt.Errorf("migrating config: %w", err))
+		}
+		if len(result.Applied) == 0 {
+			fmt.Fprintf(ctx.stdout, "Config is already at version %d\n", config.CurrentVersion)
+			return nil
+		}
+
+		for _, m := range result.Applied {
+			fmt.Fprintln(ctx.stdout, "Migration", m)
+		}
+		if *dryRun {
+			fmt.Fprint(ctx.stdout, diff.Unified(path, path+" (migrated)", string(result.Before), string(result.After)))
+			return nil
+		}
+		fmt.Fprintf(ctx.stdout, "Migrated %s from version %d to %d (backup: %s)\n", path, result.FromVersion, config.CurrentVersion, result.BackupPath)
+		return nil
+	}
+}
+
+func runConfigDocs(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	format := choiceFlag(fs, "format", "text", "output format", config.DocFormats...)
+	return func(ctx *runContext, args []string) error {
+		return config.WriteDocs(ctx.stdout, *format)
+	}
+}
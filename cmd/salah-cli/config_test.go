@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecute_ConfigHidesSecrets(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12, "mqtt": {"broker": "localhost:1883", "username": "ha", "password": "hunter2"},
+		"webhooks": [{"url": "https://example.com/hook", "secret": "s3cret"}]}`)
+	code, stdout, stderr := run("config", "show", "--origin")
+	if code != exitOK {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	for _, secret := range []string{"hunter2", "s3cret"} {
+		if strings.Contains(stdout, secret) {
+			t.Errorf("expected %s to be hidden:\n%s", secret, stdout)
+		}
+	}
+	if !strings.Contains(stdout, "https://example.com/hook") || !strings.Contains(stdout, "localhost:1883") {
+		t.Errorf("expected the other values to be shown:\n%s", stdout)
+	}
+	if _, stdout, _ := run("config", "get", "mqtt.password"); stdout != "********\n" {
+		t.Errorf("expected a hidden password, got %q", stdout)
+	}
+}
+
+func TestExecute_ConfigChangesKeepFile(t *testing.T) {
+	setupConfigDir(t, "")
+	path := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "salah-cli", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("# home\nlatitude: 51.5 # London\nlongitude: -0.12\nmethod: isna\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"set", "madhab", "hanafi"}, {"set", "adjustments.IshaAdj", "3"}, {"unset", "adjustments"}} {
+		if code, _, stderr := run(append([]string{"config"}, args...)...); code != exitOK {
+			t.Fatalf("config %v: exit %d: %s", args, code, stderr)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if want := "# home\nlatitude: 51.5 # London\nlongitude: -0.12\nmethod: isna\nmadhab: hanafi\n"; string(data) != want {
+		t.Errorf("expected only madhab added, got %q", data)
+	}
+
+	// the editor's file is saved as it is
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(editor, []byte("#!/bin/sh\nprintf 'madhab: shafi # as edited\\nmethod: isna\\nlatitude: 51.5\\nlongitude: -0.12\\n' > \"$1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", editor)
+	if code, _, stderr := run("config", "edit"); code != exitOK {
+		t.Fatalf("config edit: exit %d: %s", code, stderr)
+	}
+	data, _ = os.ReadFile(path)
+	if want := "madhab: shafi # as edited\nmethod: isna\nlatitude: 51.5\nlongitude: -0.12\n"; string(data) != want {
+		t.Errorf("expected the edited file saved as is, got %q", data)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"salah-cli/internal/config"
+	"salah-cli/internal/hooks"
+	"salah-cli/internal/params"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// lateGrace is how late a hook still runs or a prayer event is still published, e.g. when the
+// system wakes from sleep after its time
+const lateGrace = 2 * time.Minute
+
+// runHooks runs the hooks of occurrences at the same time concurrently and returns the number
+// that failed
+func runHooks(ctx context.Context, occurrences []hooks.Occurrence, logger *log.Logger) int {
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for _, o := range occurrences {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Printf("%s: running %s", o.Hook.Event, o.Hook.Command)
+			if err := hooks.Run(ctx, o, logger); err != nil {
+				logger.Printf("%s: failed: %v", o.Hook.Event, err)
+				failed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	return int(failed.Load())
+}
+
+func runHooksRun(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		if len(ctx.config.Hooks) == 0 {
+			return fmt.Errorf("no hooks in the config, see 'salah-cli config-docs' for the hooks option")
+		}
+		signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		logger := log.New(ctx.stdout, "", log.LstdFlags)
+		ishaEnd := params.IshaEnd(ctx.config)
+		after := time.Now()
+		for {
+			next, err := hooks.Next(ctx.calculator, ctx.config.Hooks, ishaEnd, after)
+			if err != nil {
+				return calculationError(fmt.Errorf("determining the next hook: %w", err))
+			}
+			at := next[0].At
+			var events []string
+			for _, o := range next {
+				events = append(events, o.Hook.Event)
+			}
+			logger.Printf("next: %s at %s", strings.Join(events, ", "), at.Format("2006-01-02 15:04:05"))
+
+			if err := sleepUntil(signalCtx, at); err != nil {
+				logger.Print("stopped")
+				return nil
+			}
+			if late := time.Since(at); late > lateGrace {
+				logger.Printf("skipping %s, missed by %s", strings.Join(events, ", "), late.Round(time.Second))
+			} else {
+				runHooks(signalCtx, next, logger)
+			}
+			after = at
+		}
+	}
+}
+
+func runHooksTest(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		event, err := config.ParseHookEvent(args[0])
+		if err != nil {
+			return usageError("%w", err)
+		}
+		var occurrences []hooks.Occurrence
+		for _, h := range ctx.config.Hooks {
+			if e, err := config.ParseHookEvent(h.Event); err != nil || e != event {
+				continue
+			}
+			// run now, with the data of the event's next occurrence
+			o, err := hooks.NextOf(ctx.calculator, h, params.IshaEnd(ctx.config), time.Now())
+			if err != nil {
+				return calculationError(fmt.Errorf("determining the time of %s: %w", h.Event, err))
+			}
+			occurrences = append(occurrences, o)
+		}
+		if len(occurrences) == 0 {
+			return usageError("no hooks for %s in the config", args[0])
+		}
+
+		signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if failed := runHooks(signalCtx, occurrences, log.New(ctx.stdout, "", 0)); failed > 0 {
+			return fmt.Errorf("%d of %d hooks failed", failed, len(occurrences))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExecute_Hooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use /bin/sh")
+	}
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12, "hooks": [
+		{"event": "at:fajr", "command": "echo {{.Prayer}} $SALAH_HOOK_EVENT"},
+		{"event": "end:isha", "command": "exit 1"}
+	]}`)
+	code, stdout, _ := run("hooks", "test", "at:Fajr")
+	if code != exitOK || !strings.Contains(stdout, "at:fajr: Fajr at:fajr\n") {
+		t.Errorf("expected the hook output, got %d %q", code, stdout)
+	}
+	if code, _, stderr := run("hooks", "test", "end:isha"); code != exitFailure || !strings.Contains(stderr, "1 of 1 hooks failed") {
+		t.Errorf("expected the failing hook to be reported, got %d %q", code, stderr)
+	}
+	for _, event := range []string{"at:asr", "at:noon"} {
+		if code, _, _ := run("hooks", "test", event); code != exitUsage {
+			t.Errorf("%s: expected exit %d, got %d", event, exitUsage, code)
+		}
+	}
+}
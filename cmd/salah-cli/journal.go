@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"salah-cli/internal/journal"
+	"salah-cli/internal/params"
+	"salah-cli/internal/prayers"
+	"salah-cli/pkg/salah"
+	"time"
+)
+
+// journalSchedule adapts the configured prayer windows for use by the journal
+func journalSchedule(calculator *salah.Calculator, ishaEnd salah.IshaEnd) journal.ScheduleFunc {
+	return func(date time.Time) (map[string]journal.Window, error) {
+		times, err := prayers.GetPrayerTimesForDate(calculator, date)
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[string]journal.Window, len(salah.Obligatory))
+		for _, p := range salah.Obligatory {
+			w, err := calculator.Window(times.Time(p), ishaEnd)
+			if err != nil {
+				return nil, err
+			}
+			out[p.String()] = journal.Window{Start: w.Start, End: w.End}
+		}
+		return out, nil
+	}
+}
+
+// journalDate returns the date prayers logged at now belong to: before Fajr, Isha is still
+// that of the day before
+func journalDate(calculator *salah.Calculator, now time.Time) (string, error) {
+	today, err := calculator.ForDate(now)
+	if err != nil {
+		return "", err
+	}
+	if now.Before(today.Fajr) {
+		now = now.AddDate(0, 0, -1)
+	}
+	return now.Format(journal.DateLayout), nil
+}
+
+// loadJournal opens the journal from its default location
+func loadJournal() (*journal.Journal, string, error) {
+	path, err := journal.GetJournalPath()
+	if err != nil {
+		return nil, "", fmt.Errorf("locating journal: %w", err)
+	}
+	j, err := journal.Load(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("loading journal: %w", err)
+	}
+	return j, path, nil
+}
+
+func runLog(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	late := fs.Bool("late", false, "prayer was performed after its time")
+	jamaah := fs.Bool("jamaah", false, "prayer was performed in congregation")
+	date := fs.String("date", "", "date the prayer belongs to (YYYY-MM-DD, default: today, or yesterday before Fajr)")
+
+	return func(ctx *runContext, args []string) error {
+		prayerArg := args[0]
+		if *date == "" {
+			// without a config the calendar date is used
+			*date = time.Now().Format(journal.DateLayout)
+			if ctx.load(loadCalculator) == nil {
+				if d, err := journalDate(ctx.calculator, time.Now()); err == nil {
+					*date = d
+				}
+			}
+		}
+
+		j, path, err := loadJournal()
+		if err != nil {
+			return err
+		}
+		replaced, err := j.Add(journal.Entry{
+			Prayer:   prayerArg,
+			Date:     *date,
+			LoggedAt: time.Now(),
+			Late:     *late,
+			Jamaah:   *jamaah,
+		})
+		if err != nil {
+			return usageError("logging prayer: %w", err)
+		}
+		if err := journal.Save(j, path); err != nil {
+			return fmt.Errorf("saving journal: %w", err)
+		}
+
+		prayer, _ := journal.ParsePrayer(prayerArg)
+		if replaced {
+			fmt.Fprintf(ctx.stdout, "Updated %s on %s\n", prayer, *date)
+		} else {
+			fmt.Fprintf(ctx.stdout, "Logged %s on %s\n", prayer, *date)
+		}
+		return nil
+	}
+}
+
+func runQada(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	since := fs.String("since", "", "start counting from this date (YYYY-MM-DD, default: first journal entry)")
+	return func(ctx *runContext, args []string) error {
+		j, _, err := loadJournal()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		from, ok := j.Start(time.Local)
+		if *since != "" {
+			t, err := time.ParseInLocation(journal.DateLayout, *since, time.Local)
+			if err != nil {
+				return usageError("invalid --since date '%s', expected YYYY-MM-DD", *since)
+			}
+			from, ok = t, true
+		}
+		if !ok {
+			fmt.Fprintln(ctx.stdout, "Journal is empty. Record prayers with 'salah-cli log PRAYER' to start tracking.")
+			return nil
+		}
+
+		missed, err := j.Outstanding(from, now, journalSchedule(ctx.calculator, params.IshaEnd(ctx.config)))
+		if err != nil {
+			return calculationError(fmt.Errorf("computing outstanding prayers: %w", err))
+		}
+		fmt.Fprintln(ctx.stdout, journal.FormatOutstanding(missed, from))
+		return nil
+	}
+}
+
+func runStats(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	period := choiceFlag(fs, "period", "week", "period to summarise", "week", "month")
+	return func(ctx *runContext, args []string) error {
+		now := time.Now()
+		var from time.Time
+		switch *period {
+		case "week":
+			from = now.AddDate(0, 0, -6)
+		case "month":
+			from = now.AddDate(0, -1, 1)
+		}
+
+		j, _, err := loadJournal()
+		if err != nil {
+			return err
+		}
+		stats, err := j.Stats(from, now, journalSchedule(ctx.calculator, params.IshaEnd(ctx.config)))
+		if err != nil {
+			return calculationError(fmt.Errorf("computing stats: %w", err))
+		}
+		fmt.Fprintln(ctx.stdout, journal.FormatStats(stats, from, now))
+		return nil
+	}
+}
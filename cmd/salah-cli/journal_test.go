@@ -0,0 +1,24 @@
+package main
+
+import (
+	"salah-cli/pkg/salah"
+	"testing"
+	"time"
+)
+
+func TestJournalDate(t *testing.T) {
+	calculator, err := salah.New(salah.WithLocation(51.5, -0.12), salah.WithTimezone(time.UTC), salah.WithEngine(salah.FixedEngine{Fajr: 5 * time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[time.Time]string{
+		time.Date(2025, 8, 28, 0, 30, 0, 0, time.UTC): "2025-08-27", // Isha after midnight
+		time.Date(2025, 8, 28, 5, 0, 0, 0, time.UTC):  "2025-08-28",
+		time.Date(2025, 8, 28, 23, 0, 0, 0, time.UTC): "2025-08-28",
+	}
+	for now, want := range tests {
+		if got, err := journalDate(calculator, now); err != nil || got != want {
+			t.Errorf("%s: expected %s, got %s, %v", now.Format(time.RFC3339), want, got, err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"salah-cli/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupConfigDir points the user config at a temp dir, optionally writing config.json, and the
+// system config at an empty one
+func setupConfigDir(t *testing.T, content string) {
+	t.Helper()
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("config location set through XDG_CONFIG_HOME")
+	}
+	t.Cleanup(config.SetSystemConfigDir(t.TempDir()))
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	for _, name := range []string{"SALAH_LATITUDE", "SALAH_LONGITUDE", "SALAH_METHOD", "SALAH_MADHAB"} {
+		t.Setenv(name, "")
+	}
+	dir := filepath.Join(home, "salah-cli")
+	if content != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func run(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = execute(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestExecute_Success(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12}`)
+	code, stdout, stderr := run("today")
+	if code != exitOK || !strings.Contains(stdout, "Fajr") || stderr != "" {
+		t.Errorf("expected times and exit 0, got %d %q %q", code, stdout, stderr)
+	}
+	if code, stdout, _ := run("next", "--lat", "21.4", "--lon", "39.8"); code != exitOK || stdout == "" {
+		t.Errorf("expected next prayer and exit 0, got %d %q", code, stdout)
+	}
+	code, stdout, _ = run("current", "--format", "json")
+	var window struct {
+		End            time.Time `json:"end"`
+		Next           string    `json:"next"`
+		ElapsedPercent float64   `json:"elapsed_percent"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &window); code != exitOK || err != nil {
+		t.Fatalf("expected the current window as JSON, got %d %q: %v", code, stdout, err)
+	}
+	if window.Next == "" || !window.End.After(time.Now()) || window.ElapsedPercent < 0 || window.ElapsedPercent > 100 {
+		t.Errorf("unexpected current window %+v", window)
+	}
+}
+
+func TestExecute_UsageError(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12}`)
+	tests := map[string][]string{
+		"unknown command":    {"tomorrow"},
+		"unknown subcommand": {"config", "nope"},
+		"missing subcommand": {"config"},
+		"unknown flag":       {"today", "--nope"},
+		"invalid choice":     {"stats", "--period", "year"},
+		"missing argument":   {"log"},
+		"extra argument":     {"today", "now"},
+		"unknown key":        {"config", "get", "nope"},
+		"unknown shell":      {"completion", "tcsh"},
+		"unknown prayer":     {"wait", "--until", "tahajjud"},
+		"negative duration":  {"wait", "--before", "-5m"},
+		"missing command":    {"schedule", "export"},
+		"too many days":      {"schedule", "export", "--command", "true", "--days", "400"},
+	}
+	for name, args := range tests {
+		code, stdout, stderr := run(args...)
+		if code != exitUsage {
+			t.Errorf("%s: expected exit %d, got %d (%q)", name, exitUsage, code, stderr)
+		}
+		if stdout != "" || stderr == "" {
+			t.Errorf("%s: expected the error on stderr only, got %q %q", name, stdout, stderr)
+		}
+	}
+}
+
+func TestExecute_Help(t *testing.T) {
+	for _, args := range [][]string{nil, {"--help"}, {"help"}, {"help", "config", "set"}, {"today", "-h"}} {
+		if code, _, _ := run(args...); code != exitOK {
+			t.Errorf("%v: expected exit 0, got %d", args, code)
+		}
+	}
+}
+
+func TestExecute_ConfigMissing(t *testing.T) {
+	setupConfigDir(t, "")
+	for _, args := range [][]string{{"today"}, {"qada"}, {"config", "get", "method"}, {"validate-config"}} {
+		code, stdout, stderr := run(args...)
+		if code != exitConfigMissing {
+			t.Errorf("%v: expected exit %d, got %d (%q)", args, exitConfigMissing, code, stderr)
+		}
+		if stdout != "" || !strings.Contains(stderr, "Error:") {
+			t.Errorf("%v: expected the error on stderr only, got %q %q", args, stdout, stderr)
+		}
+	}
+}
+
+func TestExecute_ConfigInvalid(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 100, "longitude": -0.12}`)
+	code, stdout, stderr := run("today")
+	if code != exitConfigInvalid || stdout != "" || !strings.Contains(stderr, "latitude") {
+		t.Errorf("expected invalid config error, got %d %q %q", code, stdout, stderr)
+	}
+
+	// validate-config reports the problems as its output
+	code, stdout, _ = run("validate-config")
+	if code != exitConfigInvalid || !strings.Contains(stdout, "Invalid config") {
+		t.Errorf("expected problems and exit %d, got %d %q", exitConfigInvalid, code, stdout)
+	}
+
+	if code, _, _ := run("config", "set", "method", "isna"); code != exitConfigInvalid {
+		t.Errorf("expected set to refuse an invalid config, got %d", code)
+	}
+}
+
+func TestExecute_CalculationFailure(t *testing.T) {
+	// a timetable without today's date
+	timetable := filepath.Join(t.TempDir(), "times.csv")
+	if err := os.WriteFile(timetable, []byte("date,fajr,sunrise,dhuhr,asr,maghrib,isha\n2000-01-01,05:00,06:30,12:00,15:00,17:30,19:00\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12, "engine": "timetable", "timetable_path": "`+filepath.ToSlash(timetable)+`"}`)
+
+	code, stdout, stderr := run("today")
+	if code != exitCalculation || stdout != "" || !strings.Contains(stderr, "no entry") {
+		t.Errorf("expected calculation failure, got %d %q %q", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"salah-cli/internal/config"
+	"strings"
+)
+
+func runMan(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	dir := fs.String("dir", "", "write salah-cli.1 and a page per command to this directory instead of printing salah-cli.1")
+	return func(ctx *runContext, args []string) error {
+		if *dir == "" {
+			return writeManPage(ctx.stdout)
+		}
+		if err := writeManPages(*dir); err != nil {
+			return fmt.Errorf("writing man pages: %w", err)
+		}
+		fmt.Fprintf(ctx.stdout, "Wrote man pages to %s\n", *dir)
+		return nil
+	}
+}
+
+// writeManPage writes the salah-cli(1) man page, covering every command and the config file
+func writeManPage(w io.Writer) error {
+	fmt.Fprintln(w, `.TH SALAH-CLI 1 "" "salah-cli" "User Commands"`)
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintln(w, `salah\-cli \- Islamic prayer times from the command line`)
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, ".B salah\\-cli\n.I command\n[\\fIflags\\fR] [\\fIarguments\\fR]")
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintln(w, "Calculates prayer times for a location from the config file, keeps a journal of completed")
+	fmt.Fprintln(w, "prayers and serves prayer times over HTTP.")
+	fmt.Fprintln(w, ".SH COMMANDS")
+	for _, cmd := range leafCommands(commands) {
+		fs := flagSetOf(cmd)
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %s\n", config.ManEscape(strings.TrimPrefix(usageLine(cmd, fs), "salah-cli ")))
+		fmt.Fprintln(w, config.ManEscape(cmd.summary)+".")
+		if hasFlags(fs) {
+			fmt.Fprintln(w, ".RS")
+			writeManFlags(w, fs)
+			fmt.Fprintln(w, ".RE")
+		}
+	}
+	writeManExitStatus(w)
+	writeManEnvironment(w)
+	writeManFiles(w)
+	return config.WriteDocs(w, "man")
+}
+
+// writeManPages writes salah-cli.1 and a salah-cli-COMMAND.1 page for every command to dir
+func writeManPages(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeManPage(&buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "salah-cli.1"), buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	for _, cmd := range leafCommands(commands) {
+		buf.Reset()
+		writeCommandManPage(&buf, cmd)
+		name := "salah-cli-" + strings.ReplaceAll(commandPath(cmd), " ", "-") + ".1"
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCommandManPage writes the man page of a single command
+func writeCommandManPage(w io.Writer, cmd *command) {
+	fs := flagSetOf(cmd)
+	name := "salah-cli-" + strings.ReplaceAll(commandPath(cmd), " ", "-")
+	fmt.Fprintf(w, ".TH %s 1 \"\" \"salah-cli\" \"User Commands\"\n", config.ManEscape(strings.ToUpper(name)))
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintf(w, "%s \\- %s\n", config.ManEscape(name), config.ManEscape(cmd.summary))
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintf(w, ".B %s\n", config.ManEscape(usageLine(cmd, fs)))
+	if hasFlags(fs) {
+		fmt.Fprintln(w, ".SH OPTIONS")
+		writeManFlags(w, fs)
+	}
+	fmt.Fprintln(w, ".SH SEE ALSO")
+	fmt.Fprintln(w, ".BR salah\\-cli (1)")
+}
+
+func writeManFlags(w io.Writer, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintln(w, ".TP")
+		name, usage := flag.UnquoteUsage(f)
+		if isBoolFlag(f) {
+			fmt.Fprintf(w, "\\fB\\-\\-%s\\fR\n", config.ManEscape(f.Name))
+		} else {
+			fmt.Fprintf(w, "\\fB\\-\\-%s\\fR \\fI%s\\fR\n", config.ManEscape(f.Name), config.ManEscape(cmp.Or(name, "value")))
+		}
+		if f.DefValue != "" && f.DefValue != "false" {
+			usage += fmt.Sprintf(" (default: %s)", f.DefValue)
+		}
+		fmt.Fprintln(w, config.ManEscape(usage))
+	})
+}
+
+func writeManExitStatus(w io.Writer) {
+	fmt.Fprintln(w, ".SH EXIT STATUS")
+	for _, s := range []struct {
+		code        int
+		description string
+	}{
+		{exitOK, "Success."},
+		{exitFailure, "Any other failure."},
+		{exitUsage, "Usage error: unknown command, invalid flag or wrong arguments."},
+		{exitConfigMissing, "No config file, and no location from the environment or flags."},
+		{exitConfigInvalid, "The config could not be read or is invalid."},
+		{exitCalculation, "Prayer times could not be calculated."},
+		{exitInterrupted, "Interrupted by SIGINT or SIGTERM."},
+	} {
+		fmt.Fprintf(w, ".TP\n.B %d\n%s\n", s.code, s.description)
+	}
+}
+
+func writeManEnvironment(w io.Writer) {
+	fmt.Fprintln(w, ".SH ENVIRONMENT")
+	keys, docs := configOptions(false)
+	for _, key := range keys {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %s\n", config.ManEscape(config.EnvName(key)))
+		fmt.Fprintf(w, "Overrides \\fB%s\\fR. %s.\n", config.ManEscape(key), config.ManEscape(docs[key].Description))
+	}
+	fmt.Fprintln(w, ".TP\n.B VISUAL\\fR, \\fBEDITOR")
+	fmt.Fprintln(w, "Editor used by \\fBconfig edit\\fR.")
+	fmt.Fprintln(w, ".TP\n.B ACCESSIBLE")
+	fmt.Fprintln(w, "When set, \\fBsetup\\fR uses plain prompts suited to screen readers.")
+}
+
+func writeManFiles(w io.Writer) {
+	fmt.Fprintln(w, ".SH FILES")
+	fmt.Fprintln(w, ".TP\n.I ~/.config/salah\\-cli/config.json")
+	fmt.Fprintln(w, "The user config, which may also be \\fIconfig.yaml\\fR, \\fIconfig.yml\\fR or \\fIconfig.toml\\fR")
+	fmt.Fprintln(w, "(\\fI%APPDATA%\\esalah\\-cli\\fR on Windows).")
+	fmt.Fprintln(w, ".TP\n.I /etc/salah\\-cli/config.json")
+	fmt.Fprintln(w, "The system config, overridden by the user config")
+	fmt.Fprintln(w, "(\\fI%ProgramData%\\esalah\\-cli\\fR on Windows).")
+	fmt.Fprintln(w, ".TP\n.I ~/.config/salah\\-cli/journal.json")
+	fmt.Fprintln(w, "The prayer journal written by \\fBlog\\fR.")
+}
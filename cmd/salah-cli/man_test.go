@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteManPage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeManPage(&buf); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	page := buf.String()
+	for _, want := range []string{
+		".TH SALAH-CLI 1",
+		".B config set KEY VALUE",
+		".B log [flags] PRAYER",
+		`\fB\-\-period\fR \fIvalue\fR`,
+		"(default: week)",
+		".B SALAH_ADJUSTMENTS_FAJRADJ",
+		".SH EXIT STATUS\n.TP\n.B 0\n",
+		".SH FILES",
+		".SH CONFIGURATION",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected man page to contain %q", want)
+		}
+	}
+	if strings.Contains(page, completeCommandName) {
+		t.Error("expected hidden commands to be left out")
+	}
+}
+
+func TestWriteManPages(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeManPages(dir); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "salah-cli-config-set.1"))
+	if err != nil {
+		t.Fatalf("expected a page per command, got %v", err)
+	}
+	if !strings.Contains(string(data), `salah\-cli\-config\-set \- Change an option in the config file`) {
+		t.Errorf("unexpected page:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "salah-cli.1")); err != nil {
+		t.Errorf("expected the main page, got %v", err)
+	}
+}
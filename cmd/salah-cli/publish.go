@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"salah-cli/internal/publish"
+	"syscall"
+	"time"
+)
+
+func runPublish(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	once := fs.Bool("once", false, "publish the next prayer and exit")
+	return func(ctx *runContext, args []string) error {
+		publisher := publish.New(ctx.config)
+		if !publisher.Configured() {
+			return fmt.Errorf("no mqtt or webhooks in the config, see 'salah-cli config-docs' for the options")
+		}
+		signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		logger := log.New(ctx.stdout, "", log.LstdFlags)
+		send := func(m publish.Message) error {
+			err := publisher.Publish(signalCtx, m)
+			if err != nil {
+				logger.Printf("publishing %s %s failed: %v", m.Type, m.Prayer, err)
+			} else {
+				logger.Printf("published %s %s at %s", m.Type, m.Prayer, m.Time.Format("2006-01-02 15:04"))
+			}
+			return err
+		}
+		after := time.Now()
+		for {
+			next, err := publish.Next(ctx.calculator, after)
+			if err != nil {
+				return calculationError(fmt.Errorf("determining the next prayer: %w", err))
+			}
+			if err := send(next); *once {
+				return err
+			}
+
+			if err := sleepUntil(signalCtx, next.Time); err != nil {
+				logger.Print("stopped")
+				return nil
+			}
+			if late := time.Since(next.Time); late > lateGrace {
+				logger.Printf("skipping %s, missed by %s", next.Prayer, late.Round(time.Second))
+			} else {
+				event := next
+				event.Type = publish.TypePrayer
+				send(event)
+			}
+			after = next.Time
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"salah-cli/internal/publish"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecute_Publish(t *testing.T) {
+	var posted publish.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12, "webhooks": [{"url": "`+server.URL+`"}]}`)
+	code, stdout, _ := run("publish", "--once")
+	if code != exitOK || !strings.Contains(stdout, "published next") || posted.Type != publish.TypeNext || !posted.Time.After(time.Now()) {
+		t.Errorf("expected the next prayer to be posted, got %d %q %+v", code, stdout, posted)
+	}
+
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12}`)
+	if code, _, stderr := run("publish"); code != exitFailure || !strings.Contains(stderr, "no mqtt or webhooks") {
+		t.Errorf("expected an error without integrations, got %d %q", code, stderr)
+	}
+}
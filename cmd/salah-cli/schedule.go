@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"salah-cli/internal/schedule"
+	"time"
+)
+
+// scheduleFlags defines the flags shared by 'schedule export' and 'schedule install' and
+// returns a function computing the jobs
+func scheduleFlags(fs *flag.FlagSet) (format, command *string, jobs func(ctx *runContext) ([]schedule.Job, error)) {
+	format = choiceFlag(fs, "format", "cron", "scheduler", schedule.Formats...)
+	command = fs.String("command", "", "shell command to run at each prayer time (required)")
+	days := fs.Int("days", 7, fmt.Sprintf("number of days to schedule, today included (max %d)", schedule.MaxDays))
+
+	return format, command, func(ctx *runContext) ([]schedule.Job, error) {
+		if *command == "" {
+			return nil, usageError("missing --command")
+		}
+		if *days < 1 || *days > schedule.MaxDays {
+			return nil, usageError("invalid --days %d, must be between 1 and %d", *days, schedule.MaxDays)
+		}
+		jobs, err := schedule.Jobs(ctx.calculator, time.Now(), *days, time.Local)
+		if err != nil {
+			return nil, calculationError(fmt.Errorf("calculating prayer times: %w", err))
+		}
+		if len(jobs) == 0 {
+			return nil, usageError("no prayer times left in the next %d day(s), increase --days", *days)
+		}
+		return jobs, nil
+	}
+}
+
+func runScheduleExport(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	format, command, scheduleJobs := scheduleFlags(fs)
+	return func(ctx *runContext, args []string) error {
+		jobs, err := scheduleJobs(ctx)
+		if err != nil {
+			return err
+		}
+		switch *format {
+		case "cron":
+			fmt.Fprint(ctx.stdout, schedule.Crontab(jobs, *command))
+		case "systemd":
+			fmt.Fprintf(ctx.stdout, "# %s.timer\n%s\n# %s.service\n%s", schedule.UnitName, schedule.SystemdTimer(jobs),
+				schedule.UnitName, schedule.SystemdService(*command))
+		case "at":
+			fmt.Fprint(ctx.stdout, schedule.AtScript(jobs, *command))
+		}
+		return nil
+	}
+}
+
+func runScheduleInstall(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	format, command, scheduleJobs := scheduleFlags(fs)
+	return func(ctx *runContext, args []string) error {
+		jobs, err := scheduleJobs(ctx)
+		if err != nil {
+			return err
+		}
+		switch *format {
+		case "cron":
+			if err := schedule.InstallCron(jobs, *command); err != nil {
+				return fmt.Errorf("installing crontab: %w", err)
+			}
+			fmt.Fprintf(ctx.stdout, "Installed %d jobs in the crontab\n", len(jobs))
+		case "systemd":
+			configDir, err := os.UserConfigDir()
+			if err != nil {
+				return err
+			}
+			dir := filepath.Join(configDir, "systemd", "user")
+			if err := schedule.InstallSystemd(dir, jobs, *command); err != nil {
+				return fmt.Errorf("installing systemd units: %w", err)
+			}
+			fmt.Fprintf(ctx.stdout, "Installed %s.timer with %d prayer times in %s\n", schedule.UnitName, len(jobs), dir)
+		case "at":
+			removed, err := schedule.InstallAt(jobs, *command)
+			if err != nil {
+				return fmt.Errorf("installing at jobs: %w", err)
+			}
+			fmt.Fprintf(ctx.stdout, "Installed %d at jobs, removing %d installed before\n", len(jobs), removed)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecute_ScheduleExport(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12}`)
+	code, stdout, _ := run("schedule", "export", "--command", "./pause-builds.sh", "--days", "2")
+	if code != exitOK || strings.Count(stdout, "./pause-builds.sh\n") < 5 {
+		t.Errorf("expected crontab lines for at least five prayers, got %d %q", code, stdout)
+	}
+	code, stdout, _ = run("schedule", "export", "--format", "systemd", "--command", "true", "--days", "2")
+	if code != exitOK || !strings.Contains(stdout, "OnCalendar=") || !strings.Contains(stdout, "ExecStart=") {
+		t.Errorf("expected systemd units, got %d %q", code, stdout)
+	}
+}
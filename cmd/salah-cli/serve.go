@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"salah-cli/internal/server"
+	"syscall"
+)
+
+func runServe(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	addr := fs.String("addr", ":8080", "address to listen on")
+	return func(ctx *runContext, args []string) error {
+		signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Fprintf(ctx.stdout, "Serving prayer times on %s\n", *addr)
+		return server.ListenAndServe(signalCtx, *addr, server.New(ctx.config).Handler())
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"salah-cli/internal/params"
+	"salah-cli/internal/prayers"
+	"salah-cli/pkg/salah"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func runToday(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		todays, err := prayers.GetTodaysPrayerTimes(ctx.calculator)
+		if err != nil {
+			return calculationError(fmt.Errorf("failed to get today's prayer times: %w", err))
+		}
+		fmt.Fprintln(ctx.stdout, prayers.FormatPrayerTimes(todays, ctx.config))
+		return nil
+	}
+}
+
+func runNext(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	return func(ctx *runContext, args []string) error {
+		todays, err := prayers.GetTodaysPrayerTimes(ctx.calculator)
+		if err != nil {
+			return calculationError(fmt.Errorf("failed to get today's prayer times: %w", err))
+		}
+		tomorrows, err := prayers.GetTomorrowsPrayerTimes(ctx.calculator)
+		if err != nil {
+			return calculationError(fmt.Errorf("failed to get tomorrow's prayer times: %w", err))
+		}
+		name, t, err := prayers.NextPrayerInfo(todays, tomorrows, time.Local)
+		if err != nil {
+			return calculationError(fmt.Errorf("determining next prayer: %w", err))
+		}
+		fmt.Fprintln(ctx.stdout, prayers.FormatNextPrayerInfo(name, t, ctx.config))
+		return nil
+	}
+}
+
+func runCurrent(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	format := choiceFlag(fs, "format", "text", "output format", "text", "json")
+	return func(ctx *runContext, args []string) error {
+		w, err := prayers.CurrentWindow(ctx.calculator, params.IshaEnd(ctx.config))
+		if err != nil {
+			return calculationError(fmt.Errorf("determining current prayer: %w", err))
+		}
+		if *format == "text" {
+			fmt.Fprintln(ctx.stdout, prayers.FormatWindow(w, time.Local, ctx.config))
+			return nil
+		}
+
+		now := time.Now()
+		// null between windows, from sunrise to Dhuhr or from midnight to Fajr
+		var prayer *string
+		if w.Prayer != salah.NoPrayer {
+			name := w.Prayer.String()
+			prayer = &name
+		}
+		enc := json.NewEncoder(ctx.stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(struct {
+			Prayer           *string   `json:"prayer"`
+			Start            time.Time `json:"start"`
+			End              time.Time `json:"end"`
+			Next             string    `json:"next"`
+			ElapsedPercent   float64   `json:"elapsed_percent"`
+			RemainingSeconds int       `json:"remaining_seconds"`
+		}{
+			prayer, w.Start.In(time.Local), w.End.In(time.Local), w.Next.String(),
+			math.Round(w.Elapsed(now)*1000) / 10, int(w.Remaining(now).Seconds()),
+		})
+		return nil
+	}
+}
+
+// waitPoll bounds each sleep of 'wait'. Timers follow the monotonic clock, which stops while
+// the system is suspended and ignores changes to the wall clock, so the wall clock is checked
+// again at least this often.
+var waitPoll = 30 * time.Second
+
+// sleepUntil blocks until the wall clock reaches t or ctx is done
+func sleepUntil(ctx context.Context, t time.Time) error {
+	for {
+		// Round(0) drops the monotonic reading, comparing wall clock times
+		remaining := t.Sub(time.Now().Round(0))
+		if remaining <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(min(remaining, waitPoll))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func runWait(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	targets := []string{"next"}
+	for _, p := range salah.Prayers {
+		targets = append(targets, strings.ToLower(p.String()))
+	}
+	until := choiceFlag(fs, "until", "next", "prayer to wait for", targets...)
+	before := fs.Duration("before", 0, "return this long before the prayer time, e.g. 10m")
+
+	return func(ctx *runContext, args []string) error {
+		if *before < 0 {
+			return usageError("invalid --before %s, must not be negative", *before)
+		}
+		todays, err := prayers.GetTodaysPrayerTimes(ctx.calculator)
+		if err != nil {
+			return calculationError(fmt.Errorf("failed to get today's prayer times: %w", err))
+		}
+		tomorrows, err := prayers.GetTomorrowsPrayerTimes(ctx.calculator)
+		if err != nil {
+			return calculationError(fmt.Errorf("failed to get tomorrow's prayer times: %w", err))
+		}
+
+		var name string
+		var at time.Time
+		if *until == "next" {
+			name, at, err = prayers.NextPrayerInfo(todays, tomorrows, time.Local)
+		} else {
+			p, _ := salah.ParsePrayer(*until)
+			name = p.String()
+			at, err = prayers.NextTimeOf(p, todays, tomorrows, time.Local)
+		}
+		if err != nil {
+			return calculationError(fmt.Errorf("determining prayer time: %w", err))
+		}
+
+		// within --before of the prayer the wait is already over
+		target := at.Add(-*before)
+		if *before > 0 {
+			fmt.Fprintf(ctx.stdout, "Waiting until %s, %s before %s %s\n", target.Format("15:04"), *before, name, at.Format("15:04"))
+		} else {
+			fmt.Fprintf(ctx.stdout, "Waiting until %s %s\n", name, at.Format("15:04"))
+		}
+
+		signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := sleepUntil(signalCtx, target); err != nil {
+			return &exitError{code: exitInterrupted}
+		}
+		return nil
+	}
+}
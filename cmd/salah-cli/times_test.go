@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecute_Wait(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12}`)
+	// every prayer is less than two days away, so the wait is already over
+	code, stdout, stderr := run("wait", "--until", "maghrib", "--before", "48h")
+	if code != exitOK || !strings.Contains(stdout, "before Maghrib") || stderr != "" {
+		t.Errorf("expected an immediate exit 0, got %d %q %q", code, stdout, stderr)
+	}
+}
+
+func TestSleepUntil(t *testing.T) {
+	original := waitPoll
+	defer func() { waitPoll = original }()
+	waitPoll = 10 * time.Millisecond
+
+	start := time.Now()
+	if err := sleepUntil(context.Background(), start.Add(50*time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("returned after %s, before the target", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sleepUntil(ctx, time.Now().Add(time.Hour)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the wait to be cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"salah-cli/internal/params"
+	"salah-cli/internal/travel"
+	"salah-cli/pkg/salah"
+	"time"
+)
+
+func runTravelPlan(fs *flag.FlagSet) func(ctx *runContext, args []string) error {
+	combine := fs.Bool("combine", false, "suggest combining prayers met in flight with Dhuhr/Asr or Maghrib/Isha on the ground")
+	format := choiceFlag(fs, "format", "text", "output format", "text", "json")
+
+	return func(ctx *runContext, args []string) error {
+		path := args[0]
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		it, err := travel.Parse(f)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		// a timetable is for one place, the times along the route are calculated
+		cfg := *ctx.config
+		if cfg.Engine == "timetable" {
+			fmt.Fprintln(ctx.stderr, "Calculating the prayer times along the route with the adhan engine, the timetable is for one place")
+			cfg.Engine = ""
+		}
+		opts, err := params.BuildOptions(&cfg)
+		if err != nil {
+			return &exitError{exitConfigInvalid, fmt.Errorf("building calculation parameters: %w", err)}
+		}
+		segments, err := travel.Plan(it, func(latitude, longitude float64) (*salah.Calculator, error) {
+			return salah.New(append(opts, salah.WithLocation(latitude, longitude), salah.WithTimezone(time.UTC))...)
+		}, *combine)
+		if err != nil {
+			return calculationError(fmt.Errorf("calculating prayer times: %w", err))
+		}
+		if *format == "json" {
+			return writeTravelPlanJSON(ctx, it, segments)
+		}
+
+		stamp := func(t time.Time) string { return t.In(time.Local).Format("Mon 2006-01-02 15:04 MST") }
+		clock := func(t time.Time) string { return t.In(time.Local).Format("15:04 MST") }
+		inFlight := 0
+		for i, s := range segments {
+			if i > 0 {
+				fmt.Fprintln(ctx.stdout)
+			}
+			if s.Leg >= 0 {
+				l := it.Legs[s.Leg]
+				fmt.Fprintf(ctx.stdout, "Flight %s → %s, %s – %s\n", l.From.Name, l.To.Name, stamp(s.Start), stamp(s.End))
+			} else {
+				fmt.Fprintf(ctx.stdout, "Layover in %s, %s – %s\n", s.Place, stamp(s.Start), stamp(s.End))
+			}
+			if len(s.Prayers) == 0 {
+				fmt.Fprintln(ctx.stdout, "  No prayer times")
+			}
+			for _, p := range s.Prayers {
+				where := "in " + p.Place
+				if p.InFlight() {
+					inFlight++
+					where = fmt.Sprintf("in flight near %.2f, %.2f", p.Latitude, p.Longitude)
+				}
+				fmt.Fprintf(ctx.stdout, "  %-8s %s  %s\n", p.Prayer, stamp(p.Time), where)
+				if c := p.CombineWith; c != nil {
+					when := "after landing"
+					if c.Time.Before(p.Time) {
+						when = "before take-off"
+					}
+					fmt.Fprintf(ctx.stdout, "           combine with %s at %s in %s, %s\n", c.Prayer, clock(c.Time), c.Place, when)
+				}
+			}
+		}
+		if inFlight > 0 && !*combine {
+			fmt.Fprintf(ctx.stdout, "\n%d prayer time(s) in flight, --combine suggests prayers on the ground to combine them with\n", inFlight)
+		}
+		return nil
+	}
+}
+
+// writeTravelPlanJSON prints the segments planned by 'travel plan' for bots
+func writeTravelPlanJSON(ctx *runContext, it *travel.Itinerary, segments []travel.Segment) error {
+	type combined struct {
+		Prayer string    `json:"prayer"`
+		Time   time.Time `json:"time"`
+		Place  string    `json:"place"`
+	}
+	type prayer struct {
+		Prayer    string    `json:"prayer"`
+		Time      time.Time `json:"time"`
+		InFlight  bool      `json:"in_flight"`
+		Latitude  float64   `json:"latitude"`
+		Longitude float64   `json:"longitude"`
+		Place     string    `json:"place,omitempty"`
+		// set with --combine for prayers in flight that can be combined with one on the ground
+		CombineWith *combined `json:"combine_with,omitempty"`
+	}
+	type segment struct {
+		Type    string    `json:"type"`
+		From    string    `json:"from,omitempty"`
+		To      string    `json:"to,omitempty"`
+		Place   string    `json:"place,omitempty"`
+		Start   time.Time `json:"start"`
+		End     time.Time `json:"end"`
+		Prayers []prayer  `json:"prayers"`
+	}
+	out := struct {
+		Segments []segment `json:"segments"`
+	}{[]segment{}}
+
+	local := func(t time.Time) time.Time { return t.In(time.Local) }
+	for _, s := range segments {
+		j := segment{Type: "layover", Place: s.Place, Start: local(s.Start), End: local(s.End), Prayers: []prayer{}}
+		if s.Leg >= 0 {
+			j.Type, j.From, j.To = "flight", it.Legs[s.Leg].From.Name, it.Legs[s.Leg].To.Name
+		}
+		for _, p := range s.Prayers {
+			jp := prayer{p.Prayer.String(), local(p.Time), p.InFlight(), p.Latitude, p.Longitude, p.Place, nil}
+			if c := p.CombineWith; c != nil {
+				jp.CombineWith = &combined{c.Prayer.String(), local(c.Time), c.Place}
+			}
+			j.Prayers = append(j.Prayers, jp)
+		}
+		out.Segments = append(out.Segments, j)
+	}
+	enc := json.NewEncoder(ctx.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecute_TravelPlan(t *testing.T) {
+	setupConfigDir(t, `{"latitude": 51.5, "longitude": -0.12}`)
+	path := filepath.Join(t.TempDir(), "itinerary.yaml")
+	itinerary := `legs:
+  - from: {name: London, latitude: 51.47, longitude: -0.45}
+    to: {name: Dubai, latitude: 25.25, longitude: 55.36}
+    depart: 2025-09-01T08:00:00Z
+    arrive: 2025-09-01T15:00:00Z
+`
+	if err := os.WriteFile(path, []byte(itinerary), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, _ := run("travel", "plan", path)
+	if code != exitOK || !strings.HasPrefix(stdout, "Flight London → Dubai") || !strings.Contains(stdout, "in flight near") {
+		t.Fatalf("unexpected output %d %q", code, stdout)
+	}
+
+	code, stdout, _ = run("travel", "plan", path, "--combine", "--format", "json")
+	var out struct {
+		Segments []struct {
+			Prayers []struct {
+				Prayer      string `json:"prayer"`
+				InFlight    bool   `json:"in_flight"`
+				CombineWith *struct {
+					Prayer string `json:"prayer"`
+					Place  string `json:"place"`
+				} `json:"combine_with"`
+			} `json:"prayers"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil || code != exitOK || len(out.Segments) != 1 {
+		t.Fatalf("unexpected output %d %q: %v", code, stdout, err)
+	}
+	// Maghrib comes just before landing, Isha after it in Dubai
+	prayers := out.Segments[0].Prayers
+	maghrib := prayers[len(prayers)-1]
+	if maghrib.Prayer != "Maghrib" || !maghrib.InFlight || maghrib.CombineWith == nil || maghrib.CombineWith.Prayer != "Isha" || maghrib.CombineWith.Place != "Dubai" {
+		t.Errorf("expected Maghrib in flight combined with Isha in Dubai, got %+v", prayers)
+	}
+
+	if code, _, stderr := run("travel", "plan", filepath.Join(t.TempDir(), "missing.yaml")); code != exitFailure || stderr == "" {
+		t.Errorf("expected a missing itinerary to fail, got %d %q", code, stderr)
+	}
+	if code, _, _ := run("travel", "plan", path, "--format", "csv"); code != exitUsage {
+		t.Errorf("expected exit %d for an unknown format, got %d", exitUsage, code)
+	}
+
+	// the places come from the itinerary, so no config is needed
+	setupConfigDir(t, "")
+	if code, stdout, stderr := run("travel", "plan", path, "--method", "isna"); code != exitOK || !strings.HasPrefix(stdout, "Flight London → Dubai") {
+		t.Errorf("expected a plan without a config, got %d %q %q", code, stdout, stderr)
+	}
+}
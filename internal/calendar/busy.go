@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"salah-cli/pkg/salah"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// BusySummary is the summary of busy blocks, which keep the prayer they are for private
+	BusySummary = "Busy"
+	// BusySaltFileName is the file next to the config holding the secret busy block UIDs are
+	// derived from
+	BusySaltFileName = "busy-salt"
+)
+
+// LoadBusySalt reads the salt of busy block UIDs from path, creating a random one the first
+// time. Keeping it means exporting again updates the blocks instead of duplicating them.
+func LoadBusySalt(path string) ([]byte, error) {
+	salt, err := os.ReadFile(path)
+	if err == nil && len(salt) > 0 {
+		return salt, nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	salt = make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	salt = []byte(hex.EncodeToString(salt))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, salt, 0o600); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// busyUID returns the UID of the block of the slot-th prayer of a date. It is a hash keyed by a
+// secret salt, so it gives away neither the prayer nor the location.
+func busyUID(salt []byte, date time.Time, slot int) string {
+	h := sha256.New()
+	h.Write(salt)
+	fmt.Fprintf(h, "|%s|%d", date.Format("20060102"), slot)
+	return hex.EncodeToString(h.Sum(nil)[:16]) + "@salah-cli"
+}
+
+// ParseBusyDurations parses the size of the busy block of each prayer, e.g. "Dhuhr=20m,Asr=15m"
+func ParseBusyDurations(s string) (map[salah.Prayer]time.Duration, error) {
+	durations := map[salah.Prayer]time.Duration{}
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid duration '%s', expected PRAYER=DURATION such as Dhuhr=20m", part)
+		}
+		p, err := salah.ParsePrayer(name)
+		if err != nil || !slices.Contains(salah.Obligatory, p) {
+			return nil, fmt.Errorf("invalid duration '%s': '%s' is not an obligatory prayer", part, name)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid duration '%s': '%s' is not a positive duration", part, value)
+		}
+		durations[p] = d
+	}
+	return durations, nil
+}
+
+// BusyEvents returns opaque blocks starting at the prayer times of days days from from, lasting
+// the duration of their prayer. Prayers without a duration get no block. Their UIDs are
+// derived from salt (see LoadBusySalt).
+func BusyEvents(calculator *salah.Calculator, from time.Time, days int, durations map[salah.Prayer]time.Duration, salt []byte) ([]Event, error) {
+	var events []Event
+	for d := range days {
+		date := from.AddDate(0, 0, d)
+		s, err := calculator.ForDate(date)
+		if err != nil {
+			return nil, err
+		}
+		for slot, p := range salah.Obligatory {
+			duration, ok := durations[p]
+			if !ok {
+				continue
+			}
+			start := s.Time(p)
+			events = append(events, Event{
+				UID:     busyUID(salt, date, slot),
+				Summary: BusySummary,
+				Start:   start,
+				End:     start.Add(duration),
+				Opaque:  true,
+			})
+		}
+	}
+	return events, nil
+}
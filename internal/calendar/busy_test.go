@@ -0,0 +1,65 @@
+package calendar
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"salah-cli/pkg/salah"
+	"salah-cli/pkg/salah/salahtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBusyDurations(t *testing.T) {
+	got, err := ParseBusyDurations("Dhuhr=20m, asr=15m")
+	if err != nil || len(got) != 2 || got[salah.Dhuhr] != 20*time.Minute || got[salah.Asr] != 15*time.Minute {
+		t.Errorf("unexpected durations %v, %v", got, err)
+	}
+	for _, s := range []string{"", "Dhuhr", "Dhuhr=", "Dhuhr=0m", "Dhuhr=-5m", "Sunrise=10m", "Noon=10m", "Dhuhr=20"} {
+		if _, err := ParseBusyDurations(s); err == nil {
+			t.Errorf("ParseBusyDurations(%q): expected an error", s)
+		}
+	}
+}
+
+func TestBusyEvents(t *testing.T) {
+	calculator := salahtest.Calculator(t)
+	from := time.Date(2025, 8, 27, 12, 0, 0, 0, time.UTC)
+	durations := map[salah.Prayer]time.Duration{salah.Dhuhr: 20 * time.Minute, salah.Asr: 15 * time.Minute}
+	events, err := BusyEvents(calculator, from, 2, durations, []byte("salt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected Dhuhr and Asr on 2 days, got %+v", events)
+	}
+	asr := events[3]
+	if !asr.Start.Equal(time.Date(2025, 8, 28, 16, 30, 0, 0, time.UTC)) || asr.End.Sub(asr.Start) != 15*time.Minute {
+		t.Errorf("unexpected Asr block %+v", asr)
+	}
+	if !asr.Opaque || asr.Summary != BusySummary || strings.Contains(strings.ToLower(asr.UID), "asr") || strings.Contains(asr.UID, "51") {
+		t.Errorf("expected an opaque block without details, got %+v", asr)
+	}
+
+	// UIDs are stable for a salt, so exporting again updates the blocks
+	again, _ := BusyEvents(calculator, from, 2, durations, []byte("salt"))
+	other, _ := BusyEvents(calculator, from, 2, durations, []byte("other"))
+	if again[3].UID != asr.UID || other[3].UID == asr.UID || events[2].UID == asr.UID {
+		t.Errorf("expected UIDs unique to the block and salt, got %s, %s, %s", asr.UID, again[3].UID, other[3].UID)
+	}
+}
+
+func TestLoadBusySalt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "salah-cli", BusySaltFileName)
+	salt, err := LoadBusySalt(path)
+	if err != nil || len(salt) == 0 {
+		t.Fatalf("expected a new salt, got %q, %v", salt, err)
+	}
+	if again, err := LoadBusySalt(path); err != nil || !bytes.Equal(again, salt) {
+		t.Errorf("expected the salt kept, got %q, %v", again, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected a private salt file, got %v, %v", info, err)
+	}
+}
@@ -0,0 +1,104 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+// Event is a single VEVENT entry
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	// Opaque marks the event as busy time (TRANSP:OPAQUE) rather than free
+	Opaque bool
+
+	// The fields below are only read from calendars (see ParseICS), not written
+
+	// AllDay events span whole days, from the date of Start to the date of End
+	AllDay bool
+	// Cancelled events have STATUS:CANCELLED
+	Cancelled bool
+	// Recurrence is the RRULE of a recurring event and Exceptions the starts it skips (EXDATE)
+	Recurrence string
+	Exceptions []time.Time
+	// RecurrenceID is the start of the instance of a recurring event this event replaces
+	RecurrenceID time.Time
+}
+
+// WriteICS writes the events as an iCalendar (RFC 5545) document
+func WriteICS(w io.Writer, prodID string, events []Event) error {
+	stamp := time.Now().UTC().Format(icsTimeLayout)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + escapeText(prodID),
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	for _, e := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+e.UID,
+			"DTSTAMP:"+stamp,
+			"DTSTART:"+e.Start.UTC().Format(icsTimeLayout),
+		)
+		if !e.End.IsZero() {
+			lines = append(lines, "DTEND:"+e.End.UTC().Format(icsTimeLayout))
+		}
+		lines = append(lines, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Opaque {
+			lines = append(lines, "TRANSP:OPAQUE")
+		} else {
+			lines = append(lines, "TRANSP:TRANSPARENT")
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldLine(line)+"\r\n"); err != nil {
+			return fmt.Errorf("failed to write calendar: %w", err)
+		}
+	}
+	return nil
+}
+
+// escapeText escapes characters with special meaning in iCalendar TEXT values
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// foldLine splits lines longer than 75 octets as required by RFC 5545. Continuation lines start
+// with a space, which counts towards their 75 octets.
+func foldLine(line string) string {
+	limit := 75
+	if len(line) <= limit {
+		return line
+	}
+	var b strings.Builder
+	for len(line) > limit {
+		cut := limit
+		// avoid splitting a multi-byte UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICS(t *testing.T) {
+	start := time.Date(2025, 8, 27, 12, 30, 0, 0, time.UTC)
+	events := []Event{
+		{UID: "dhuhr-20250827@salah-cli", Summary: "Dhuhr, London", Start: start, End: start.Add(20 * time.Minute), Opaque: true},
+		{UID: "asr-20250827@salah-cli", Summary: "Asr", Start: start.Add(3 * time.Hour)},
+	}
+
+	var b strings.Builder
+	if err := WriteICS(&b, "-//salah-cli//EN", events); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20250827T123000Z\r\n",
+		"DTEND:20250827T125000Z\r\n",
+		"SUMMARY:Dhuhr\\, London\r\n",
+		"TRANSP:OPAQUE\r\n",
+		"TRANSP:TRANSPARENT\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 2 {
+		t.Errorf("expected 2 events, got:\n%s", out)
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	for _, line := range []string{
+		"DESCRIPTION:" + strings.Repeat("a", 100),
+		"DESCRIPTION:" + strings.Repeat("a", 300),
+		"DESCRIPTION:" + strings.Repeat("é", 150),
+	} {
+		folded := foldLine(line)
+		for _, part := range strings.Split(folded, "\r\n") {
+			if len(part) > 75 {
+				t.Errorf("folded part longer than 75 octets: %q", part)
+			}
+		}
+		if strings.ReplaceAll(folded, "\r\n ", "") != line {
+			t.Errorf("unfolding did not restore the original line")
+		}
+	}
+}
+
+func TestWriteICS_LineLength(t *testing.T) {
+	start := time.Date(2025, 8, 27, 12, 30, 0, 0, time.UTC)
+	events := []Event{{
+		UID:         strings.Repeat("0123456789abcdef", 10) + "@salah-cli",
+		Summary:     "Dhuhr",
+		Description: strings.Repeat("A long description, with commas; and semicolons. ", 10),
+		Start:       start,
+		End:         start.Add(20 * time.Minute),
+	}}
+	var b strings.Builder
+	if err := WriteICS(&b, "-//salah-cli//EN", events); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets (%d): %q", len(line), line)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icsDateLayout      = "20060102"
+	icsLocalTimeLayout = "20060102T150405"
+)
+
+// property is a content line of an iCalendar document, e.g. DTSTART;TZID=Europe/London:20250827T130000
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICS reads the events of an iCalendar (RFC 5545) document. Times without a time zone,
+// and times in zones unknown to the system such as Windows zone names, are read in loc.
+func ParseICS(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	var events []Event
+	var event *Event
+	// components nested in an event, such as alarms, are skipped
+	nested := 0
+	var end *property
+	var duration time.Duration
+	for i, line := range lines {
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch {
+		case p.name == "BEGIN" && p.value == "VEVENT" && event == nil:
+			event = &Event{Opaque: true}
+			end, duration = nil, 0
+			continue
+		case event == nil:
+			continue
+		case p.name == "BEGIN":
+			nested++
+			continue
+		case p.name == "END" && nested > 0:
+			nested--
+			continue
+		case nested > 0:
+			continue
+		}
+
+		switch p.name {
+		case "END":
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", i+1, event.Summary)
+			}
+			if err := setEnd(event, end, duration, loc); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			events = append(events, *event)
+			event = nil
+		case "UID":
+			event.UID = p.value
+		case "SUMMARY":
+			event.Summary = unescapeText(p.value)
+		case "DESCRIPTION":
+			event.Description = unescapeText(p.value)
+		case "TRANSP":
+			event.Opaque = p.value != "TRANSPARENT"
+		case "STATUS":
+			event.Cancelled = p.value == "CANCELLED"
+		case "RRULE":
+			event.Recurrence = p.value
+		case "DTSTART":
+			event.Start, event.AllDay, err = parseTime(p, loc)
+		case "DTEND":
+			// resolved at the end of the event, once DTSTART is known
+			end = &p
+		case "DURATION":
+			duration, err = parseDuration(p.value)
+		case "RECURRENCE-ID":
+			event.RecurrenceID, _, err = parseTime(p, loc)
+		case "EXDATE":
+			for _, v := range strings.Split(p.value, ",") {
+				var t time.Time
+				if t, _, err = parseTime(property{p.name, p.params, v}, loc); err != nil {
+					break
+				}
+				event.Exceptions = append(event.Exceptions, t)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("event %q is not terminated by END:VEVENT", event.Summary)
+	}
+	return events, nil
+}
+
+// setEnd sets the end of an event from its DTEND or DURATION. Without either, events end when
+// they start, or after a day when all-day.
+func setEnd(e *Event, end *property, duration time.Duration, loc *time.Location) error {
+	switch {
+	case end != nil:
+		var err error
+		if e.End, _, err = parseTime(*end, loc); err != nil {
+			return err
+		}
+	case duration != 0:
+		e.End = e.Start.Add(duration)
+	case e.AllDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	default:
+		e.End = e.Start
+	}
+	if e.End.Before(e.Start) {
+		return fmt.Errorf("event %q ends before it starts", e.Summary)
+	}
+	return nil
+}
+
+// unfoldLines reads the content lines of a document, joining folded lines
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line into its name, parameters and value
+func parseProperty(line string) (property, error) {
+	// the value starts at the first colon outside a quoted parameter value
+	quoted, colon := false, -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("invalid content line %q", line)
+	}
+	parts := strings.Split(line[:colon], ";")
+	p := property{name: strings.ToUpper(parts[0]), value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		if p.params == nil {
+			p.params = map[string]string{}
+		}
+		p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+// parseTime reads a DATE or DATE-TIME value, reporting whether it is a date
+func parseTime(p property, loc *time.Location) (time.Time, bool, error) {
+	if p.params["VALUE"] == "DATE" || len(p.value) == len(icsDateLayout) {
+		t, err := time.ParseInLocation(icsDateLayout, p.value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s date %q", p.name, p.value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse(icsTimeLayout, p.value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s time %q", p.name, p.value)
+		}
+		return t, false, nil
+	}
+	if tzid := p.params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = zone
+		}
+	}
+	t, err := time.ParseInLocation(icsLocalTimeLayout, p.value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s time %q", p.name, p.value)
+	}
+	return t, false, nil
+}
+
+// parseDuration reads a DURATION value such as PT1H30M, P1D or -PT15M
+func parseDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q", s)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, invalid
+	}
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var d time.Duration
+	n := ""
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			n += string(c)
+		case c == 'T':
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		default:
+			v, err := strconv.Atoi(n)
+			if err != nil || units[c] == 0 {
+				return 0, invalid
+			}
+			d += time.Duration(v) * units[c]
+			n = ""
+		}
+	}
+	if n != "" {
+		return 0, invalid
+	}
+	return sign * d, nil
+}
+
+// unescapeText reverses escapeText
+func unescapeText(s string) string {
+	r := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return r.Replace(s)
+}
@@ -0,0 +1,117 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VTIMEZONE
+TZID:W. Europe Standard Time
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:standup@example.com
+SUMMARY:Stand-up\, daily
+DTSTART;TZID="Europe/London":20250827T130000
+DURATION:PT15M
+RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR
+EXDATE;TZID=Europe/London:20250828T130000,20250829T130000
+BEGIN:VALARM
+TRIGGER:-PT5M
+DESCRIPTION:ignored
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:review@example.com
+SUMMARY:Design review with a long title that is folded onto a second line by
+  the calendar
+DTSTART:20250827T153000Z
+DTEND:20250827T163000Z
+TRANSP:TRANSPARENT
+END:VEVENT
+BEGIN:VEVENT
+UID:holiday@example.com
+SUMMARY:Bank holiday
+DTSTART;VALUE=DATE:20250825
+STATUS:CANCELLED
+END:VEVENT
+BEGIN:VEVENT
+UID:sync@example.com
+SUMMARY:Sync
+DTSTART;TZID=W. Europe Standard Time:20250827T140000
+DTEND;TZID=W. Europe Standard Time:20250827T143000
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestParseICS(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	events, err := ParseICS(strings.NewReader(strings.ReplaceAll(testCalendar, "\n", "\r\n")), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %+v", events)
+	}
+
+	standup := events[0]
+	if !standup.Start.Equal(time.Date(2025, 8, 27, 13, 0, 0, 0, london)) || standup.End.Sub(standup.Start) != 15*time.Minute {
+		t.Errorf("unexpected stand-up times %v %v", standup.Start, standup.End)
+	}
+	if standup.Summary != "Stand-up, daily" || standup.Recurrence == "" || len(standup.Exceptions) != 2 || !standup.Opaque {
+		t.Errorf("unexpected stand-up %+v", standup)
+	}
+	if review := events[1]; review.Opaque || !strings.HasSuffix(review.Summary, "by the calendar") || review.End.Sub(review.Start) != time.Hour {
+		t.Errorf("unexpected review %+v", review)
+	}
+	if holiday := events[2]; !holiday.AllDay || !holiday.Cancelled || holiday.End.Sub(holiday.Start) != 24*time.Hour {
+		t.Errorf("unexpected holiday %+v", holiday)
+	}
+	// unknown zones are read in the given location
+	if sync := events[3]; !sync.Start.Equal(time.Date(2025, 8, 27, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected sync start %v", sync.Start)
+	}
+}
+
+func TestParseICS_Invalid(t *testing.T) {
+	for name, ics := range map[string]string{
+		"no start":       "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n",
+		"bad time":       "BEGIN:VEVENT\nDTSTART:2025-08-27\nEND:VEVENT\n",
+		"ends before":    "BEGIN:VEVENT\nDTSTART:20250827T100000Z\nDTEND:20250827T090000Z\nEND:VEVENT\n",
+		"bad duration":   "BEGIN:VEVENT\nDTSTART:20250827T100000Z\nDURATION:1h\nEND:VEVENT\n",
+		"unterminated":   "BEGIN:VEVENT\nDTSTART:20250827T100000Z\n",
+		"no colon":       "BEGIN:VEVENT\nDTSTART\nEND:VEVENT\n",
+		"bad exceptions": "BEGIN:VEVENT\nDTSTART:20250827T100000Z\nEXDATE:20250828T100000Z,tomorrow\nEND:VEVENT\n",
+	} {
+		if _, err := ParseICS(strings.NewReader(ics), time.UTC); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	valid := map[string]time.Duration{
+		"PT15M":      15 * time.Minute,
+		"PT1H30M":    90 * time.Minute,
+		"P1D":        24 * time.Hour,
+		"P1W":        7 * 24 * time.Hour,
+		"-PT5M":      -5 * time.Minute,
+		"P1DT2H3S":   26*time.Hour + 3*time.Second,
+		"+PT0H10M0S": 10 * time.Minute,
+	}
+	for s, want := range valid {
+		if got, err := parseDuration(s); err != nil || got != want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "P", "PT", "15M", "PT15", "P1H", "PTxM"} {
+		if _, err := parseDuration(s); err == nil {
+			t.Errorf("parseDuration(%q): expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds the periods of a recurring event that are expanded
+const maxPeriods = 100000
+
+// rule is the supported subset of an RRULE: a frequency with an interval, ended by a count or
+// a date, optionally limited to some days of the week
+type rule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// parseRule reads an RRULE, returning an error for the parts it doesn't support
+func parseRule(s string, loc *time.Location) (rule, error) {
+	r := rule{interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		var err error
+		switch key {
+		case "FREQ":
+			if !slices.Contains([]string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}, value) {
+				return rule{}, fmt.Errorf("unsupported frequency %s", value)
+			}
+			r.freq = value
+		case "INTERVAL":
+			if r.interval, err = strconv.Atoi(value); err != nil || r.interval < 1 {
+				return rule{}, fmt.Errorf("invalid interval %s", value)
+			}
+		case "COUNT":
+			if r.count, err = strconv.Atoi(value); err != nil || r.count < 1 {
+				return rule{}, fmt.Errorf("invalid count %s", value)
+			}
+		case "UNTIL":
+			var date bool
+			if r.until, date, err = parseTime(property{name: "UNTIL", value: value}, loc); err != nil {
+				return rule{}, err
+			}
+			if date {
+				// the whole day is included
+				r.until = r.until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				d, ok := weekdays[day]
+				if !ok {
+					return rule{}, fmt.Errorf("unsupported BYDAY %s", day)
+				}
+				r.byDay = append(r.byDay, d)
+			}
+		case "WKST":
+			// weeks start on Monday
+		default:
+			return rule{}, fmt.Errorf("unsupported %s", key)
+		}
+	}
+	if r.freq == "" {
+		return rule{}, fmt.Errorf("missing FREQ")
+	}
+	if len(r.byDay) > 0 && r.freq != "DAILY" && r.freq != "WEEKLY" {
+		return rule{}, fmt.Errorf("BYDAY is only supported with DAILY and WEEKLY")
+	}
+	return r, nil
+}
+
+// starts returns the starts of the n-th period of the rule from start, in order. Monthly and
+// yearly periods on dates that don't exist, such as the 31st of a short month, are empty.
+func (r rule) starts(start time.Time, n int) []time.Time {
+	y, m, d := start.Date()
+	h, mi, s := start.Clock()
+	step := n * r.interval
+	switch r.freq {
+	case "DAILY":
+		t := start.AddDate(0, 0, step)
+		if len(r.byDay) > 0 && !slices.Contains(r.byDay, t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+	case "WEEKLY":
+		week := start.AddDate(0, 0, 7*step)
+		if len(r.byDay) == 0 {
+			return []time.Time{week}
+		}
+		monday := week.AddDate(0, 0, -(int(week.Weekday())+6)%7)
+		var out []time.Time
+		for offset := range 7 {
+			if t := monday.AddDate(0, 0, offset); slices.Contains(r.byDay, t.Weekday()) {
+				out = append(out, t)
+			}
+		}
+		return out
+	case "MONTHLY":
+		if t := time.Date(y, m+time.Month(step), d, h, mi, s, 0, start.Location()); t.Day() == d {
+			return []time.Time{t}
+		}
+	case "YEARLY":
+		if t := time.Date(y+step, m, d, h, mi, s, 0, start.Location()); t.Day() == d {
+			return []time.Time{t}
+		}
+	}
+	return nil
+}
+
+// Expand returns the events overlapping [from, to), with recurring events expanded into their
+// instances. Changed instances replace the instances they override, and cancelled events are
+// left out. Recurring events with rules that aren't supported are returned in unsupported,
+// only their first instance being expanded.
+func Expand(events []Event, from, to time.Time) (expanded, unsupported []Event) {
+	// the instances replaced by changed ones, by UID
+	overridden := map[string][]time.Time{}
+	for _, e := range events {
+		if !e.RecurrenceID.IsZero() {
+			overridden[e.UID] = append(overridden[e.UID], e.RecurrenceID)
+		}
+	}
+	skipped := func(e Event, start time.Time) bool {
+		same := func(t time.Time) bool { return t.Equal(start) }
+		return slices.ContainsFunc(e.Exceptions, same) || slices.ContainsFunc(overridden[e.UID], same)
+	}
+	add := func(e Event, start time.Time) {
+		instance := e
+		instance.Start, instance.End = start, start.Add(e.End.Sub(e.Start))
+		instance.Recurrence, instance.Exceptions = "", nil
+		if !instance.Cancelled && instance.End.After(from) && instance.Start.Before(to) {
+			expanded = append(expanded, instance)
+		}
+	}
+
+	for _, e := range events {
+		if e.Recurrence == "" || !e.RecurrenceID.IsZero() {
+			add(e, e.Start)
+			continue
+		}
+		r, err := parseRule(e.Recurrence, e.Start.Location())
+		if err != nil {
+			unsupported = append(unsupported, e)
+			add(e, e.Start)
+			continue
+		}
+		count := 0
+	periods:
+		for n := range maxPeriods {
+			for _, start := range r.starts(e.Start, n) {
+				switch {
+				case start.Before(e.Start):
+					continue
+				case !r.until.IsZero() && start.After(r.until), !start.Before(to), r.count > 0 && count == r.count:
+					break periods
+				}
+				// excluded instances still count towards COUNT
+				count++
+				if !skipped(e, start) {
+					add(e, start)
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(expanded, func(a, b Event) int { return a.Start.Compare(b.Start) })
+	return expanded, unsupported
+}
@@ -0,0 +1,80 @@
+package calendar
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	start := time.Date(2025, 8, 27, 13, 0, 0, 0, time.UTC) // a Wednesday
+	at := func(day, hour int) time.Time { return time.Date(2025, 8, day, hour, 0, 0, 0, time.UTC) }
+	event := func(uid, rule string) Event {
+		return Event{UID: uid, Start: start, End: start.Add(30 * time.Minute), Recurrence: rule}
+	}
+	starts := func(events []Event) []int {
+		var days []int
+		for _, e := range events {
+			days = append(days, e.Start.Day())
+		}
+		return days
+	}
+
+	tests := []struct {
+		name   string
+		events []Event
+		want   []int
+	}{
+		{"single", []Event{{Start: at(28, 9), End: at(28, 10)}}, []int{28}},
+		{"daily", []Event{event("a", "FREQ=DAILY")}, []int{27, 28, 29, 30, 31}},
+		{"weekdays", []Event{event("a", "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR")}, []int{27, 28, 29}},
+		{"count", []Event{event("a", "FREQ=DAILY;COUNT=2")}, []int{27, 28}},
+		{"until", []Event{event("a", "FREQ=DAILY;UNTIL=20250829")}, []int{27, 28, 29}},
+		{"interval", []Event{event("a", "FREQ=DAILY;INTERVAL=2")}, []int{27, 29, 31}},
+		{"weekly", []Event{event("a", "FREQ=WEEKLY;BYDAY=MO,TH;WKST=MO")}, []int{28}},
+		{"monthly", []Event{event("a", "FREQ=MONTHLY")}, []int{27}},
+		{
+			"exceptions count towards COUNT",
+			[]Event{func() Event { e := event("a", "FREQ=DAILY;COUNT=3"); e.Exceptions = []time.Time{at(28, 13)}; return e }()},
+			[]int{27, 29},
+		},
+		{
+			"changed instance",
+			[]Event{event("a", "FREQ=DAILY;COUNT=2"), {UID: "a", Start: at(28, 15), End: at(28, 16), RecurrenceID: at(28, 13)}},
+			[]int{27, 28},
+		},
+		{
+			"cancelled instance",
+			[]Event{event("a", "FREQ=DAILY;COUNT=2"), {UID: "a", Start: at(28, 13), End: at(28, 14), RecurrenceID: at(28, 13), Cancelled: true}},
+			[]int{27},
+		},
+	}
+	for _, tt := range tests {
+		got, unsupported := Expand(tt.events, at(25, 0), at(31, 23))
+		if len(unsupported) != 0 || !slices.Equal(starts(got), tt.want) {
+			t.Errorf("%s: got days %v (unsupported %v), want %v", tt.name, starts(got), unsupported, tt.want)
+		}
+	}
+
+	got, _ := Expand([]Event{event("a", "FREQ=DAILY;COUNT=2"), {UID: "a", Start: at(28, 15), End: at(28, 16), RecurrenceID: at(28, 13)}}, at(25, 0), at(31, 0))
+	if got[1].Start.Hour() != 15 {
+		t.Errorf("expected the changed instance to replace the original, got %+v", got)
+	}
+
+	got, unsupported := Expand([]Event{event("a", "FREQ=MONTHLY;BYMONTHDAY=1,15")}, at(25, 0), at(31, 0))
+	if len(unsupported) != 1 || len(got) != 1 || !got[0].Start.Equal(start) {
+		t.Errorf("expected the unsupported rule to be reported with its first instance, got %v %v", got, unsupported)
+	}
+}
+
+func TestExpand_KeepsWallClockAcrossDST(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	start := time.Date(2025, 10, 24, 13, 0, 0, 0, london)
+	got, _ := Expand([]Event{{Start: start, End: start.Add(time.Hour), Recurrence: "FREQ=DAILY;COUNT=4"}}, start, start.AddDate(0, 0, 7))
+	if len(got) != 4 || got[3].Start.Hour() != 13 || got[3].Start.Day() != 27 {
+		t.Errorf("expected 13:00 each day after the clocks change, got %+v", got)
+	}
+}
@@ -1,38 +1,82 @@
 package config
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"salah-cli/internal/util"
-	"strconv"
-
-	"github.com/charmbracelet/huh"
-	calc "github.com/mnadev/adhango/pkg/calc"
+	"salah-cli/pkg/salah"
+	"slices"
+	"strings"
 )
 
-// Config holds user settings
+// PrayerAdjustments holds per-prayer offsets in minutes
+type PrayerAdjustments struct {
+	FajrAdj    int `json:"FajrAdj" doc:"Minutes added to Fajr"`
+	SunriseAdj int `json:"SunriseAdj" doc:"Minutes added to Sunrise"`
+	DhuhrAdj   int `json:"DhuhrAdj" doc:"Minutes added to Dhuhr"`
+	AsrAdj     int `json:"AsrAdj" doc:"Minutes added to Asr"`
+	MaghribAdj int `json:"MaghribAdj" doc:"Minutes added to Maghrib"`
+	IshaAdj    int `json:"IshaAdj" doc:"Minutes added to Isha"`
+}
+
+// adjustmentKeys are the JSON names of the PrayerAdjustments fields, in daily order
+var adjustmentKeys = []string{"FajrAdj", "SunriseAdj", "DhuhrAdj", "AsrAdj", "MaghribAdj", "IshaAdj"}
+
+// values returns the adjustments in the order of adjustmentKeys
+func (a *PrayerAdjustments) values() []int {
+	if a == nil {
+		return nil
+	}
+	return []int{a.FajrAdj, a.SunriseAdj, a.DhuhrAdj, a.AsrAdj, a.MaghribAdj, a.IshaAdj}
+}
+
+// Config holds user settings.
+//
+// The doc, default and example tags are used to generate the config reference (see Docs)
 type Config struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-
-	Method            *int                    `json:"method,omitempty"`
-	FajrAngle         *float64                `json:"fajr_angle,omitempty"`
-	IshaAngle         *float64                `json:"isha_angle,omitempty"`
-	IshaInterval      *int                    `json:"isha_interval,omitempty"`
-	Madhab            *int                    `json:"madhab,omitempty"`
-	HighLatitudeRule  *int                    `json:"high_latitude_rule,omitempty"`
-	Adjustments       *calc.PrayerAdjustments `json:"adjustments,omitempty"`
-	MethodAdjustments *calc.PrayerAdjustments `json:"method_adjustments,omitempty"`
+	// Schema points editors at the JSON Schema of the file (see JSONSchema), it is not a setting
+	Schema string `json:"$schema,omitempty" doc:"-"`
+
+	Version int `json:"version,omitempty" doc:"Config schema version, upgraded automatically (see 'salah-cli config migrate')" default:"1" example:"2"`
+
+	Latitude  float64 `json:"latitude" doc:"Latitude of your location in degrees (-90 to 90)" example:"51.5074"`
+	Longitude float64 `json:"longitude" doc:"Longitude of your location in degrees (-180 to 180)" example:"-0.1278"`
+
+	Method            *Method            `json:"method,omitempty" doc:"Calculation method defining the Fajr and Isha angles" type:"string or integer" example:"\"isna\""`
+	FajrAngle         *float64           `json:"fajr_angle,omitempty" doc:"Custom Fajr angle in degrees, overriding the method" default:"from method" example:"18.0"`
+	IshaAngle         *float64           `json:"isha_angle,omitempty" doc:"Custom Isha angle in degrees, overriding the method. Cannot be combined with isha_interval" default:"from method" example:"17.0"`
+	IshaInterval      *int               `json:"isha_interval,omitempty" doc:"Minutes after Maghrib for Isha, overriding the method. Cannot be combined with isha_angle" default:"from method" example:"90"`
+	Madhab            *Madhab            `json:"madhab,omitempty" doc:"Juristic method used to compute Asr" type:"string or integer" example:"\"hanafi\""`
+	HighLatitudeRule  *HighLatitudeRule  `json:"high_latitude_rule,omitempty" doc:"Rule bounding Fajr and Isha at high latitudes" type:"string or integer" example:"\"auto\""`
+	Adjustments       *PrayerAdjustments `json:"adjustments,omitempty" doc:"Per-prayer offsets in minutes" example:"{\"FajrAdj\": 2, \"IshaAdj\": 3}"`
+	MethodAdjustments *PrayerAdjustments `json:"method_adjustments,omitempty" doc:"Per-prayer offsets in minutes replacing those of the method" default:"from method" example:"{\"DhuhrAdj\": 1}"`
+	IshaEnd           string             `json:"isha_end,omitempty" doc:"When the Isha window ends: at Fajr or at Islamic midnight, halfway between Maghrib and Fajr" example:"midnight"`
+
+	// Calculation engine ("adhan" by default, "native" or "timetable")
+	Engine        string `json:"engine,omitempty" doc:"Engine used to calculate prayer times" example:"native"`
+	TimetablePath string `json:"timetable_path,omitempty" doc:"CSV timetable (date,fajr,sunrise,dhuhr,asr,maghrib,isha) read by the timetable engine" example:"~/timetable.csv"`
 
 	// User Preferences
-	EnableCountdown    bool   `json:"enable_countdown"`
-	EnableHighlighting bool   `json:"enable_highlighting"`
-	HighlightColour    string `json:"highlight_colour"`
+	EnableCountdown    bool   `json:"enable_countdown" doc:"Show a countdown to the next prayer" default:"false" example:"true"`
+	EnableHighlighting bool   `json:"enable_highlighting" doc:"Highlight the current prayer in colour" default:"false" example:"true"`
+	HighlightColour    string `json:"highlight_colour" doc:"Colour used for highlighting" example:"cyan"`
+
+	Hooks []Hook `json:"hooks,omitempty" doc:"Commands run on prayer time events by 'salah-cli hooks run'" example:"[{\"event\": \"before:maghrib:5m\", \"command\": \"./pause-builds.sh\"}]"`
+
+	// Outbound integrations used by 'salah-cli publish'
+	MQTT     *MQTT     `json:"mqtt,omitempty" doc:"MQTT broker prayer events and the next prayer are published to by 'salah-cli publish'" example:"{\"broker\": \"localhost:1883\"}"`
+	Webhooks []Webhook `json:"webhooks,omitempty" doc:"URLs prayer events and the next prayer are posted to by 'salah-cli publish'" example:"[{\"url\": \"https://ha.local/api/webhook/prayer\"}]"`
 }
 
+// Engines lists the calculation engines that can be selected in the config
+var Engines = []string{"adhan", "native", "timetable"}
+
+// IshaEnds lists the times the Isha window can end at, the first being the default
+var IshaEnds = []string{"fajr", "midnight"}
+
 const (
 	DefaultConfigFileName = "config.json"
 	AppName               = "salah-cli"
@@ -45,8 +89,8 @@ var getEnv = os.Getenv
 // For testability, allow overriding the OS
 var getOS = func() string { return runtime.GOOS }
 
-// GetConfigPath determines the default path for the config file
-func GetConfigPath() (string, error) {
+// GetConfigDir determines the directory holding the config file
+func GetConfigDir() (string, error) {
 	switch getOS() {
 	case "windows":
 		appData := getEnv("APPDATA")
@@ -57,7 +101,7 @@ func GetConfigPath() (string, error) {
 			}
 			appData = filepath.Join(userProfile, "AppData", "Roaming")
 		}
-		return filepath.Join(appData, AppName, DefaultConfigFileName), nil
+		return filepath.Join(appData, AppName), nil
 	case "darwin", "linux":
 		configHome := getEnv("XDG_CONFIG_HOME")
 		if configHome == "" {
@@ -67,12 +111,40 @@ func GetConfigPath() (string, error) {
 			}
 			configHome = filepath.Join(home, unixDefaultConfigDir)
 		}
-		return filepath.Join(configHome, AppName, DefaultConfigFileName), nil
+		return filepath.Join(configHome, AppName), nil
 	default:
 		return "", fmt.Errorf("unsupported OS: %s", getOS())
 	}
 }
 
+// GetConfigPath determines the path of the config file: whichever of ConfigFileNames exists
+// in the config directory, or DefaultConfigFileName if none does
+func GetConfigPath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return findConfigFile(dir)
+}
+
+// findConfigFile returns whichever of ConfigFileNames exists in dir, or DefaultConfigFileName if none does
+func findConfigFile(dir string) (string, error) {
+	var found []string
+	for _, name := range ConfigFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			found = append(found, filepath.Join(dir, name))
+		}
+	}
+	switch len(found) {
+	case 0:
+		return filepath.Join(dir, DefaultConfigFileName), nil
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("several config files found (%s), remove all but one", strings.Join(found, ", "))
+	}
+}
+
 // loadFromFile loads config from a given file path with validation
 func loadFromFile(path string) (*Config, error) {
 	// Ensure the config directory exists
@@ -83,35 +155,46 @@ func loadFromFile(path string) (*Config, error) {
 		}
 	}
 
-	file, err := os.Open(path)
+	cfg, problems, err := CheckFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("unable to open config file %s: %w", path, err)
+		return nil, err
 	}
-	defer file.Close()
-
-	var cfg Config
-	decoder := json.NewDecoder(file)
-	decoder.DisallowUnknownFields() // fail if unexpected keys are found
-
-	if err := decoder.Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("error decoding JSON from %s: %w", path, err)
+	if err := fileError(path, problems); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
 
-	// Run validation checks
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid config in %s: %w", path, err)
+// fileError reports the first error found in the config file at path, with its position
+func fileError(path string, problems []Problem) error {
+	var errs []Problem
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			errs = append(errs, p)
+		}
 	}
-
-	return &cfg, nil
+	if len(errs) == 1 {
+		return fmt.Errorf("invalid config in %s: %s", errs[0].At(path), errs[0].Message)
+	}
+	if len(errs) > 1 {
+		return fmt.Errorf("invalid config in %s: %s (and %d more, run 'salah-cli validate-config')", errs[0].At(path), errs[0].Message, len(errs)-1)
+	}
+	return nil
 }
 
-// load loads config from the default config path
+// Load resolves the config from the system and user config files and SALAH_* environment variables
+// (see Resolve)
 func Load() (*Config, error) {
-	path, err := GetConfigPath()
+	return LoadWith(nil)
+}
+
+// LoadWith resolves the config like Load, with values given as command-line flags taking precedence
+func LoadWith(overrides Overrides) (*Config, error) {
+	resolved, err := Resolve(overrides)
 	if err != nil {
 		return nil, err
 	}
-	return loadFromFile(path)
+	return resolved.Config, nil
 }
 
 func validateLatitude(latitude float64) error {
@@ -129,30 +212,84 @@ func validateLongitude(longitude float64) error {
 	return nil
 }
 
-// Validate checks for semantic errors in the configuration
+// Validate checks for semantic errors in the configuration, returning the first one found.
+// Use Problems to get every error and warning.
 func (c *Config) Validate() error {
-	// Latitude must be -90..90
-	if err := validateLatitude(c.Latitude); err != nil {
-		return err
+	for _, p := range c.Problems() {
+		if p.Severity == SeverityError {
+			return errors.New(p.Message)
+		}
 	}
+	return nil
+}
 
-	if err := validateLongitude(c.Longitude); err != nil {
-		return err
+// Problems checks the configuration and returns every error and warning found
+func (c *Config) Problems() []Problem {
+	var problems []Problem
+	add := func(severity Severity, field string, err error) {
+		if err != nil {
+			problems = append(problems, Problem{Severity: severity, Field: field, Message: err.Error()})
+		}
 	}
 
+	if c.Version < 0 || c.Version > CurrentVersion {
+		add(SeverityError, "version", fmt.Errorf("unsupported config version %d (latest is %d)", c.Version, CurrentVersion))
+	}
+
+	// Latitude must be -90..90
+	add(SeverityError, "latitude", validateLatitude(c.Latitude))
+	add(SeverityError, "longitude", validateLongitude(c.Longitude))
+
 	// Highlight colour must be valid if provided
 	if c.EnableHighlighting && c.HighlightColour != "" {
 		if _, ok := util.AnsiColors[c.HighlightColour]; !ok {
-			return fmt.Errorf("invalid highlight colour '%s'. Allowed: %v", c.HighlightColour, keys(util.AnsiColors))
+			add(SeverityError, "highlight_colour", fmt.Errorf("invalid highlight colour '%s'. Allowed: %v", c.HighlightColour, keys(util.AnsiColors)))
 		}
 	}
 
+	add(SeverityError, "method", validateName("method", (*salah.Method)(c.Method), MethodNames))
+	add(SeverityError, "madhab", validateName("madhab", (*salah.Madhab)(c.Madhab), MadhabNames))
+	add(SeverityError, "high_latitude_rule", validateName("high_latitude_rule", (*salah.HighLatitudeRule)(c.HighLatitudeRule), HighLatitudeRuleNames))
+
 	// If both isha_angle and isha_interval are set, that’s a conflict
 	if c.IshaAngle != nil && c.IshaInterval != nil {
-		return fmt.Errorf("only one of isha_angle or isha_interval can be set")
+		add(SeverityError, "isha_interval", fmt.Errorf("only one of isha_angle or isha_interval can be set"))
+	}
+	add(SeverityWarning, "fajr_angle", checkAngle("fajr_angle", c.FajrAngle))
+	add(SeverityWarning, "isha_angle", checkAngle("isha_angle", c.IshaAngle))
+	if c.IshaInterval != nil && (*c.IshaInterval < 0 || *c.IshaInterval > maxIshaInterval) {
+		add(SeverityWarning, "isha_interval", fmt.Errorf("isha_interval of %d minutes is outside 0 to %d", *c.IshaInterval, maxIshaInterval))
+	}
+	for _, field := range []string{"adjustments", "method_adjustments"} {
+		adj := c.Adjustments
+		if field == "method_adjustments" {
+			adj = c.MethodAdjustments
+		}
+		for i, minutes := range adj.values() {
+			key := adjustmentKeys[i]
+			if minutes < -maxAdjustment || minutes > maxAdjustment {
+				add(SeverityWarning, field+"."+key, fmt.Errorf("%s.%s of %d minutes is beyond ±%d minutes", field, key, minutes, maxAdjustment))
+			}
+		}
 	}
 
-	return nil
+	if c.IshaEnd != "" && !slices.Contains(IshaEnds, c.IshaEnd) {
+		add(SeverityError, "isha_end", fmt.Errorf("invalid isha_end '%s'. Allowed: %v", c.IshaEnd, IshaEnds))
+	}
+
+	if c.Engine != "" && !slices.Contains(Engines, c.Engine) {
+		add(SeverityError, "engine", fmt.Errorf("invalid engine '%s'. Allowed: %v", c.Engine, Engines))
+	}
+	if c.Engine == "timetable" && c.TimetablePath == "" {
+		add(SeverityError, "engine", fmt.Errorf("timetable_path is required when engine is 'timetable'"))
+	}
+
+	for i, h := range c.Hooks {
+		hookProblems(i, h, add)
+	}
+	c.publishProblems(add)
+
+	return problems
 }
 
 // keys returns the keys of a string map (helper for error messages)
@@ -164,98 +301,29 @@ func keys(m map[string]string) []string {
 	return out
 }
 
-func SetupConfig() (*Config, error) {
-	var config Config
-
-	var latitude string
-	var longitude string
-	var madhab int
-	var moonsightingMethod int
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().
-				Title("Enter your latitude:").
-				Value(&latitude).
-				Validate(func(str string) error {
-					if str == "" {
-						return fmt.Errorf("value can't be empty")
-					}
-					fl, err := strconv.ParseFloat(str, 64)
-					if err != nil {
-						return fmt.Errorf("failed to parse latitude value: %v", err)
-					}
-					return validateLatitude(fl)
-
-				}),
-
-			huh.NewInput().
-				Title("Enter your longitude:").
-				Value(&longitude).
-				Validate(func(str string) error {
-					if str == "" {
-						return fmt.Errorf("value can't be empty")
-					}
-					fl, err := strconv.ParseFloat(str, 64)
-					if err != nil {
-						return fmt.Errorf("failed to parse longitude value: %v", err)
-					}
-					return validateLongitude(fl)
-
-				}),
-
-			huh.NewSelect[int]().
-				Title("Choose your Madhab").
-				Options(
-					huh.NewOption("Shafi/Hanbali/Maliki", 0),
-					huh.NewOption("Hanafi", 1),
-				).
-				Value(&madhab),
-
-			huh.NewSelect[int]().
-				Title("Choose your moonsighting method").
-				Options(
-					huh.NewOption("Other", 0),
-					huh.NewOption("Muslim World League", 1),
-					huh.NewOption("Egyptian", 2),
-					huh.NewOption("Karachi", 3),
-					huh.NewOption("Umm al-Qura", 4),
-					huh.NewOption("Dubai", 5),
-					huh.NewOption("Moon Sighting Committee", 6),
-					huh.NewOption("North America (ISNA)", 7),
-					huh.NewOption("Kuwait", 8),
-					huh.NewOption("Qatar", 9),
-					huh.NewOption("Singapore", 10),
-					huh.NewOption("UOIF", 11),
-				).Value(&moonsightingMethod),
-		),
-	)
-
-	err := form.Run()
+// SaveConfig writes the given config to the specified filepath safely using an atomic rename.
+// The format follows the file extension and the config is always written at CurrentVersion.
+func SaveConfig(config *Config, path string) error {
+	format, err := FormatOf(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to setup config: %s", err.Error())
+		return err
 	}
-
-	// Ignoring error as this was previously validated
-	latFloat, _ := strconv.ParseFloat(latitude, 64)
-	config.Latitude = latFloat
-
-	lonFloat, _ := strconv.ParseFloat(longitude, 64)
-	config.Longitude = lonFloat
-	config.Method = &moonsightingMethod
-	config.Madhab = &madhab
-
-	return &config, nil
-
+	data, err := encodeConfigAs(config, format)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data)
 }
 
-// SaveConfig writes the given config to the specified filepath safely using an atomic rename
-func SaveConfig(config *Config, path string) error {
+// WriteFile replaces the file at path with data safely using an atomic rename, creating its
+// directory if needed
+func WriteFile(path string, data []byte) error {
 	dir := filepath.Dir(path)
 	if statErr := os.MkdirAll(dir, 0o755); statErr != nil {
 		return fmt.Errorf("failed to create config directory %s: %w", dir, statErr)
 	}
 
-	tmpFile, err := os.CreateTemp(dir, "config.json.tmp.*")
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(path)+".tmp.*")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -264,12 +332,10 @@ func SaveConfig(config *Config, path string) error {
 	// ensure temp file is removed on any early return
 	cleanupTemp := func() { _ = os.Remove(tmpPath) }
 
-	enc := json.NewEncoder(tmpFile)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(config); err != nil {
+	if _, err := tmpFile.Write(data); err != nil {
 		_ = tmpFile.Close()
 		cleanupTemp()
-		return fmt.Errorf("failed to encode config to JSON: %w", err)
+		return fmt.Errorf("failed to write temp file: %w", err)
 	}
 
 	if err := tmpFile.Sync(); err != nil {
@@ -182,6 +182,42 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "unknown engine",
+			cfg: Config{
+				Latitude:  10.0,
+				Longitude: 10.0,
+				Engine:    "abacus",
+			},
+			expectErr: true,
+		},
+		{
+			name: "timetable engine without path",
+			cfg: Config{
+				Latitude:  10.0,
+				Longitude: 10.0,
+				Engine:    "timetable",
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown isha end",
+			cfg: Config{
+				Latitude:  10.0,
+				Longitude: 10.0,
+				IshaEnd:   "dawn",
+			},
+			expectErr: true,
+		},
+		{
+			name: "native engine",
+			cfg: Config{
+				Latitude:  10.0,
+				Longitude: 10.0,
+				Engine:    "native",
+			},
+			expectErr: false,
+		},
 		{
 			name: "highlighting disabled ignores colour",
 			cfg: Config{
@@ -322,3 +358,20 @@ func TestSaveConfig_AtomicOverwrite(t *testing.T) {
 		t.Fatalf("overwrite did not update file correctly")
 	}
 }
+
+// Test adjustments keep their existing JSON field names
+func TestLoadFromFile_Adjustments(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{"latitude":10,"longitude":20,"adjustments":{"FajrAdj":2,"IshaAdj":-3}}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := loadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Adjustments == nil || cfg.Adjustments.FajrAdj != 2 || cfg.Adjustments.IshaAdj != -3 {
+		t.Errorf("unexpected adjustments: %+v", cfg.Adjustments)
+	}
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"reflect"
+	"salah-cli/internal/util"
+	"salah-cli/pkg/salah"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Choice is an allowed value of a config option
+type Choice struct {
+	Value string
+	Name  string
+}
+
+// FieldDoc documents a single config option
+type FieldDoc struct {
+	Key         string
+	Type        string
+	Required    bool
+	Secret      bool // hidden by 'salah-cli config show' and 'config get'
+	Default     string
+	Description string
+	Example     string
+	Choices     []Choice
+	Keys        []FieldDoc // for object options
+	Items       []FieldDoc // keys of the objects in array options
+}
+
+// DocFormats lists the formats supported by WriteDocs
+var DocFormats = []string{"text", "markdown", "man"}
+
+// choices returns the allowed values for options with a fixed set of values
+func choices() map[string][]Choice {
+	out := map[string][]Choice{}
+	for _, m := range salah.Methods {
+		name := fmt.Sprintf("%d, %s", int(m), m)
+		var aliases []string
+		for alias, am := range methodAliases {
+			if am == m {
+				aliases = append(aliases, alias)
+			}
+		}
+		if len(aliases) > 0 {
+			slices.Sort(aliases)
+			name += ", also: " + strings.Join(aliases, ", ")
+		}
+		out["method"] = append(out["method"], Choice{MethodNames[m], name})
+	}
+	for _, m := range salah.Madhabs {
+		out["madhab"] = append(out["madhab"], Choice{MadhabNames[m], fmt.Sprintf("%d, %s", int(m), m)})
+	}
+	for _, r := range salah.HighLatitudeRules {
+		out["high_latitude_rule"] = append(out["high_latitude_rule"], Choice{HighLatitudeRuleNames[r], fmt.Sprintf("%d, %s", int(r), r)})
+	}
+	for _, e := range IshaEnds {
+		out["isha_end"] = append(out["isha_end"], Choice{Value: e})
+	}
+	for _, e := range Engines {
+		out["engine"] = append(out["engine"], Choice{Value: e})
+	}
+	colours := keys(util.AnsiColors)
+	slices.Sort(colours)
+	for _, c := range colours {
+		if c != "reset" {
+			out["highlight_colour"] = append(out["highlight_colour"], Choice{Value: c})
+		}
+	}
+	return out
+}
+
+// defaults returns the defaults of options whose default is set in code rather than in a tag
+func defaults() map[string]string {
+	params := salah.MethodParameters(salah.DefaultMethod)
+	return map[string]string{
+		"method":             MethodNames[salah.DefaultMethod],
+		"madhab":             MadhabNames[params.Madhab],
+		"high_latitude_rule": HighLatitudeRuleNames[params.HighLatitudeRule],
+		"isha_end":           IshaEnds[0],
+		"engine":             Engines[0],
+		"highlight_colour":   util.DefaultColour,
+	}
+}
+
+// Docs generates the reference for every config option from the Config struct tags
+func Docs() []FieldDoc {
+	return fieldDocs(reflect.TypeFor[Config](), choices(), defaults())
+}
+
+func fieldDocs(t reflect.Type, allowed map[string][]Choice, defaulted map[string]string) []FieldDoc {
+	var docs []FieldDoc
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || field.Tag.Get("doc") == "-" {
+			continue
+		}
+		doc := FieldDoc{
+			Key:         name,
+			Type:        cmp.Or(field.Tag.Get("type"), typeName(field.Type)),
+			Default:     cmp.Or(field.Tag.Get("default"), defaulted[name]),
+			Description: field.Tag.Get("doc"),
+			Example:     field.Tag.Get("example"),
+			Choices:     allowed[name],
+			Secret:      field.Tag.Get("secret") == "true",
+		}
+		doc.Required = doc.Default == "" && !strings.Contains(opts, "omitempty")
+		if doc.Type == "string" && doc.Example != "" {
+			doc.Example = strconv.Quote(doc.Example)
+		}
+		switch ft := derefType(field.Type); {
+		case ft.Kind() == reflect.Struct:
+			doc.Keys = fieldDocs(ft, allowed, defaulted)
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct:
+			doc.Items = fieldDocs(ft.Elem(), allowed, defaulted)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// typeName returns the JSON type of a field
+func typeName(t reflect.Type) string {
+	switch derefType(t).Kind() {
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice:
+		return "array"
+	default:
+		return "string"
+	}
+}
+
+// summary returns the type, and whether the option is required or its default
+func (d FieldDoc) summary() string {
+	switch {
+	case d.Required:
+		return d.Type + ", required"
+	case d.Default != "":
+		return fmt.Sprintf("%s, default: %s", d.Type, d.Default)
+	default:
+		return d.Type + ", optional"
+	}
+}
+
+// WriteDocs writes the config reference in the given format (text, markdown or man)
+func WriteDocs(w io.Writer, format string) error {
+	docs := Docs()
+	switch format {
+	case "text":
+		writeDocsText(w, docs)
+	case "markdown":
+		writeDocsMarkdown(w, docs)
+	case "man":
+		writeDocsMan(w, docs)
+	default:
+		return fmt.Errorf("unknown format '%s'. Allowed: %v", format, DocFormats)
+	}
+	return nil
+}
+
+func writeDocsText(w io.Writer, docs []FieldDoc) {
+	fmt.Fprintln(w, "Configuration options")
+	fmt.Fprintln(w)
+	for _, d := range docs {
+		fmt.Fprintf(w, "%s (%s)\n", d.Key, d.summary())
+		fmt.Fprintf(w, "    %s\n", d.Description)
+		if len(d.Choices) > 0 {
+			fmt.Fprintln(w, "    Allowed:")
+			for _, c := range d.Choices {
+				if c.Name == "" {
+					fmt.Fprintf(w, "      %s\n", c.Value)
+				} else {
+					fmt.Fprintf(w, "      %-23s %s\n", c.Value, c.Name)
+				}
+			}
+		}
+		if len(d.Keys) > 0 {
+			fmt.Fprintln(w, "    Keys:")
+			for _, k := range d.Keys {
+				fmt.Fprintf(w, "      %-11s %s (%s)\n", k.Key, k.Description, k.Type)
+			}
+		}
+		if len(d.Items) > 0 {
+			fmt.Fprintln(w, "    Keys of each item:")
+			for _, k := range d.Items {
+				fmt.Fprintf(w, "      %-11s %s (%s)\n", k.Key, k.Description, k.summary())
+			}
+		}
+		if d.Example != "" {
+			fmt.Fprintf(w, "    Example: \"%s\": %s\n", d.Key, d.Example)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func writeDocsMarkdown(w io.Writer, docs []FieldDoc) {
+	fmt.Fprintln(w, "# Configuration")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Option | Type | Default | Description |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- |")
+	for _, d := range docs {
+		def := d.Default
+		if d.Required {
+			def = "required"
+		} else if def == "" {
+			def = "-"
+		}
+		fmt.Fprintf(w, "| [`%s`](#%s) | %s | %s | %s |\n", d.Key, d.Key, d.Type, def, d.Description)
+	}
+	for _, d := range docs {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "## %s\n\n", d.Key)
+		fmt.Fprintf(w, "%s (%s).\n", d.Description, d.summary())
+		if len(d.Choices) > 0 {
+			fmt.Fprintln(w)
+			for _, c := range d.Choices {
+				if c.Name == "" {
+					fmt.Fprintf(w, "- `%s`\n", c.Value)
+				} else {
+					fmt.Fprintf(w, "- `%s`: %s\n", c.Value, c.Name)
+				}
+			}
+		}
+		if len(d.Keys) > 0 {
+			fmt.Fprintln(w)
+			for _, k := range d.Keys {
+				fmt.Fprintf(w, "- `%s` (%s): %s\n", k.Key, k.Type, k.Description)
+			}
+		}
+		if len(d.Items) > 0 {
+			fmt.Fprint(w, "\nKeys of each item:\n\n")
+			for _, k := range d.Items {
+				fmt.Fprintf(w, "- `%s` (%s): %s\n", k.Key, k.summary(), k.Description)
+			}
+		}
+		if d.Example != "" {
+			fmt.Fprintf(w, "\n```json\n\"%s\": %s\n```\n", d.Key, d.Example)
+		}
+	}
+}
+
+func writeDocsMan(w io.Writer, docs []FieldDoc) {
+	fmt.Fprintln(w, ".SH CONFIGURATION")
+	fmt.Fprintln(w, "Options are read from the config file (JSON, YAML or TOML).")
+	for _, d := range docs {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %s\n", ManEscape(d.Key))
+		fmt.Fprintf(w, "(%s) %s.\n", ManEscape(d.summary()), ManEscape(d.Description))
+		if len(d.Choices) > 0 || len(d.Keys) > 0 || len(d.Items) > 0 {
+			fmt.Fprintln(w, ".RS")
+			for _, c := range d.Choices {
+				fmt.Fprintf(w, ".IP \"%s\" 4\n", ManEscape(c.Value))
+				if c.Name != "" {
+					fmt.Fprintln(w, ManEscape(c.Name))
+				}
+			}
+			for _, k := range d.Keys {
+				fmt.Fprintf(w, ".IP \"%s\" 4\n%s (%s)\n", ManEscape(k.Key), ManEscape(k.Description), k.Type)
+			}
+			for _, k := range d.Items {
+				fmt.Fprintf(w, ".IP \"%s\" 4\n%s (%s)\n", ManEscape(k.Key), ManEscape(k.Description), ManEscape(k.summary()))
+			}
+			fmt.Fprintln(w, ".RE")
+		}
+		if d.Example != "" {
+			fmt.Fprintf(w, ".br\nExample: \\fB\"%s\": %s\\fR\n", ManEscape(d.Key), ManEscape(d.Example))
+		}
+	}
+}
+
+// ManEscape escapes text for use in a roff document
+func ManEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"salah-cli/pkg/salah"
+	"strings"
+	"testing"
+)
+
+// Every option must be documented so the reference cannot drift from the struct
+func TestDocs_EveryFieldDocumented(t *testing.T) {
+	var check func(docs []FieldDoc)
+	check = func(docs []FieldDoc) {
+		for _, d := range docs {
+			if d.Description == "" {
+				t.Errorf("option %s has no doc tag", d.Key)
+			}
+			check(d.Keys)
+			check(d.Items)
+		}
+	}
+	docs := Docs()
+	check(docs)
+
+	// fields are only left out when explicitly tagged doc:"-"
+	typ := reflect.TypeFor[Config]()
+	expected := 0
+	for i := range typ.NumField() {
+		if typ.Field(i).Tag.Get("doc") != "-" {
+			expected++
+		}
+	}
+	if len(docs) != expected {
+		t.Errorf("expected %d options, got %d", expected, len(docs))
+	}
+}
+
+func TestDocs_DefaultsMatchCalculator(t *testing.T) {
+	params := salah.MethodParameters(salah.DefaultMethod)
+	expected := map[string]string{
+		"method":             MethodNames[salah.DefaultMethod],
+		"madhab":             MadhabNames[params.Madhab],
+		"high_latitude_rule": HighLatitudeRuleNames[params.HighLatitudeRule],
+		"engine":             Engines[0],
+		"isha_end":           IshaEnds[0],
+		"highlight_colour":   "green",
+	}
+	for _, d := range Docs() {
+		if want, ok := expected[d.Key]; ok && d.Default != want {
+			t.Errorf("expected default %s for %s, got %s", want, d.Key, d.Default)
+		}
+	}
+}
+
+func TestDocs_Choices(t *testing.T) {
+	byKey := map[string]FieldDoc{}
+	for _, d := range Docs() {
+		byKey[d.Key] = d
+	}
+
+	if got := len(byKey["method"].Choices); got != len(salah.Methods) {
+		t.Errorf("expected %d methods, got %d", len(salah.Methods), got)
+	}
+	if c := byKey["high_latitude_rule"].Choices[4]; c.Value != "auto" || c.Name != "4, auto" {
+		t.Errorf("expected auto (4), got %+v", c)
+	}
+	for _, c := range byKey["highlight_colour"].Choices {
+		if c.Value == "reset" {
+			t.Error("expected reset to be excluded from highlight colours")
+		}
+	}
+	if !byKey["latitude"].Required || byKey["method"].Required {
+		t.Error("expected latitude to be required and method to be optional")
+	}
+	if len(byKey["adjustments"].Keys) != 6 {
+		t.Errorf("expected 6 adjustment keys, got %d", len(byKey["adjustments"].Keys))
+	}
+	if hooks := byKey["hooks"]; hooks.Type != "array" || len(hooks.Keys) != 0 || len(hooks.Items) != 5 || !hooks.Items[0].Required {
+		t.Errorf("expected hooks to be an array of objects with a required event, got %+v", hooks)
+	}
+}
+
+func TestWriteDocs_Formats(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{"text", []string{"method (string or integer, default: moonsighting_committee)", "north_america           7, North America (ISNA), also: isna", `Example: "engine": "native"`}},
+		{"markdown", []string{"| [`latitude`](#latitude) | number | required |", "## high_latitude_rule", "- `moonsighting_committee`: 6, Moonsighting Committee"}},
+		{"man", []string{".SH CONFIGURATION", ".B high_latitude_rule", `Longitude of your location in degrees (\-180 to 180)`}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteDocs(&buf, tt.format); err != nil {
+			t.Fatalf("unexpected error for %s: %v", tt.format, err)
+		}
+		for _, want := range tt.expected {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("expected %s output to contain %q", tt.format, want)
+			}
+		}
+	}
+
+	if err := WriteDocs(&bytes.Buffer{}, "html"); err == nil {
+		t.Error("expected error for unknown format, got nil")
+	}
+}
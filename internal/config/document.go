@@ -0,0 +1,278 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// changeDocument sets the option with the given dotted path to a JSON value in a config
+// document, or removes it and its nested keys when value is nil. Everything else in the
+// document is left as it is: comments, the order of keys and the way other values are written.
+func changeDocument(data []byte, format Format, key string, value json.RawMessage) ([]byte, error) {
+	var node *yaml.Node
+	if value != nil {
+		var err error
+		if node, err = jsonToNode(json.NewDecoder(bytes.NewReader(value))); err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", key, err)
+		}
+	}
+	switch format {
+	case FormatYAML:
+		return changeYAML(data, key, node)
+	case FormatTOML:
+		return changeTOML(data, key, node)
+	default:
+		return changeJSON(data, key, node)
+	}
+}
+
+// changeMapping sets or removes the value at path in a mapping node
+func changeMapping(root *yaml.Node, path []string, value *yaml.Node) error {
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not an object", path[0])
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != path[0] {
+			continue
+		}
+		current := root.Content[i+1]
+		switch {
+		case len(path) > 1:
+			return changeMapping(current, path[1:], value)
+		case value == nil:
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		default:
+			value.HeadComment, value.LineComment, value.FootComment = current.HeadComment, current.LineComment, current.FootComment
+			root.Content[i+1] = value
+		}
+		return nil
+	}
+	if value == nil {
+		return nil
+	}
+	for i := len(path) - 1; i > 0; i-- {
+		value = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[i]}, value}}
+	}
+	root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}, value)
+	return nil
+}
+
+func changeYAML(data []byte, key string, value *yaml.Node) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if err := changeMapping(doc.Content[0], strings.Split(key, "."), value); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func changeJSON(data []byte, key string, value *yaml.Node) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	if len(bytes.TrimSpace(data)) > 0 {
+		var err error
+		if root, err = jsonToNode(json.NewDecoder(bytes.NewReader(data))); err != nil {
+			return nil, err
+		}
+	}
+	if err := changeMapping(root, strings.Split(key, "."), value); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeJSONNode(&buf, root, "")
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeJSONNode writes a node converted by jsonToNode back as indented JSON, in the same order
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node, indent string) {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		open, end, step := "{", "}", 2
+		if node.Kind == yaml.SequenceNode {
+			open, end, step = "[", "]", 1
+		}
+		if len(node.Content) == 0 {
+			buf.WriteString(open + end)
+			return
+		}
+		buf.WriteString(open + "\n")
+		for i := 0; i < len(node.Content); i += step {
+			buf.WriteString(indent + "  ")
+			if step == 2 {
+				key, _ := json.Marshal(node.Content[i].Value)
+				buf.Write(key)
+				buf.WriteString(": ")
+			}
+			writeJSONNode(buf, node.Content[i+step-1], indent+"  ")
+			if i+step < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + end)
+	default:
+		if node.Tag == "!!str" {
+			s, _ := json.Marshal(node.Value)
+			buf.Write(s)
+		} else {
+			buf.WriteString(node.Value)
+		}
+	}
+}
+
+// tomlLine is a line of a TOML document with the table it is in and the dotted path of the key
+// or table it defines, if any
+type tomlLine struct {
+	text   string
+	table  string
+	path   string
+	header bool
+}
+
+// tomlLines splits a TOML document into lines, finding what each defines like decodeTOML
+func tomlLines(data []byte) []tomlLine {
+	var lines []tomlLine
+	table := ""
+	for _, text := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		l := tomlLine{text: text}
+		trimmed := strings.TrimSpace(text)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+		case strings.HasPrefix(trimmed, "["):
+			if m := tomlTableHeader.FindStringSubmatch(trimmed); m != nil {
+				table = unquoteKey(m[1])
+				l.path, l.header = table, true
+			}
+		default:
+			if key, _, ok := strings.Cut(trimmed, "="); ok {
+				l.path = joinPath(table, unquoteKey(strings.TrimSpace(key)))
+			}
+		}
+		l.table = table
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// within reports whether path is key or one of its nested keys
+func within(path, key string) bool {
+	return path == key || strings.HasPrefix(path, key+".")
+}
+
+func changeTOML(data []byte, key string, value *yaml.Node) ([]byte, error) {
+	lines := tomlLines(data)
+	var out []string
+	if value == nil {
+		// drop the key, or every line of its tables
+		removing := false
+		for _, l := range lines {
+			if l.header {
+				removing = within(l.path, key)
+			}
+			if !removing && (l.header || !within(l.path, key)) {
+				out = append(out, l.text)
+			}
+		}
+		return []byte(strings.TrimRight(strings.Join(out, "\n"), "\n") + "\n"), nil
+	}
+
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+	if value.Kind == yaml.MappingNode {
+		return nil, fmt.Errorf("%s is a table", key)
+	}
+	entry := tomlKey(name) + " = " + tomlValue(value)
+
+	// replace the key where it is
+	for i, l := range lines {
+		switch {
+		case l.header && within(l.path, key):
+			return nil, fmt.Errorf("%s is a table", key)
+		case l.path == key:
+			indent := l.text[:len(l.text)-len(strings.TrimLeft(l.text, " \t"))]
+			lines[i].text = indent + entry
+			return joinTOMLLines(lines), nil
+		}
+	}
+
+	// or add it after the last key of its table, before any nested tables
+	insert, found := -1, table == ""
+	for i, l := range lines {
+		if l.header && l.path == table {
+			insert, found = i, true
+		}
+		if l.table == table && !l.header && l.path != "" {
+			insert = i
+		}
+		if !l.header && l.path != "" && within(table, l.path) && table != "" {
+			return nil, fmt.Errorf("%s is an inline table", table)
+		}
+	}
+	if !found {
+		return []byte(strings.TrimRight(string(data), "\n") + "\n\n[" + tomlKeyPath(table) + "]\n" + entry + "\n"), nil
+	}
+	if insert < 0 {
+		// a top-level key of a document without any, before its first table
+		insert = len(lines) - 1
+		for i, l := range lines {
+			if l.header {
+				insert = i - 1
+				for insert >= 0 && strings.TrimSpace(lines[insert].text) == "" {
+					insert--
+				}
+				break
+			}
+		}
+	}
+	lines = append(lines[:insert+1], append([]tomlLine{{text: entry}}, lines[insert+1:]...)...)
+	return joinTOMLLines(lines), nil
+}
+
+func joinTOMLLines(lines []tomlLine) []byte {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l.text)
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// tomlKeyPath quotes each key of a dotted path as needed
+func tomlKeyPath(path string) string {
+	keys := strings.Split(path, ".")
+	for i, k := range keys {
+		keys[i] = tomlKey(k)
+	}
+	return strings.Join(keys, ".")
+}
+
+// sameConfig reports whether a config document decodes to config
+func sameConfig(data []byte, format Format, config *Config) bool {
+	cfg, problems := CheckDataAs(data, format)
+	if HasErrors(problems) {
+		return false
+	}
+	got, err := encodeConfig(cfg)
+	if err != nil {
+		return false
+	}
+	want, err := encodeConfig(config)
+	return err == nil && bytes.Equal(got, want)
+}
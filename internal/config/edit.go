@@ -0,0 +1,158 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Get returns the effective value of the option with the given dotted path
+func (r *Resolved) Get(key string) (Setting, error) {
+	for _, s := range r.Settings {
+		if s.Key == key {
+			return s, nil
+		}
+	}
+	return Setting{}, unknownKey(key)
+}
+
+// Set sets the option with the given dotted path, e.g. "method" or "adjustments.FajrAdj". The
+// value is parsed according to the option's type and the resulting config must be valid.
+func (c *Config) Set(key, value string) error {
+	doc, ok := leafDoc(key)
+	if !ok {
+		return unknownKey(key)
+	}
+	if key == "version" {
+		return fmt.Errorf("version is managed by 'salah-cli config migrate'")
+	}
+	values, err := c.flatValues()
+	if err != nil {
+		return err
+	}
+	values[key] = argValue(value, doc.Type)
+	return c.replace(values)
+}
+
+// Unset clears an optional option, so its default applies. Only options that can be left out
+// of the config, like method or adjustments, can be unset.
+func (c *Config) Unset(key string) error {
+	target, ok := fieldByKey(&Config{}, key)
+	if !ok {
+		if _, leaf := leafDoc(key); leaf {
+			parent, _, _ := strings.Cut(key, ".")
+			return fmt.Errorf("%s cannot be unset on its own, unset %s instead", key, parent)
+		}
+		return unknownKey(key)
+	}
+	if kind := reflect.TypeOf(target).Elem().Kind(); kind != reflect.Pointer && kind != reflect.Slice {
+		return fmt.Errorf("%s cannot be unset, change it with 'salah-cli config set'", key)
+	}
+	values, err := c.flatValues()
+	if err != nil {
+		return err
+	}
+	for k := range values {
+		if k == key || strings.HasPrefix(k, key+".") {
+			delete(values, k)
+		}
+	}
+	return c.replace(values)
+}
+
+// SaveChange writes config to path like SaveConfig after key was set or unset, changing only
+// that key in an existing file so its comments, the order of its keys and the way its other
+// values are written are kept
+func SaveChange(config *Config, path, key string) error {
+	original, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return SaveConfig(config, path)
+	}
+	if err != nil {
+		return err
+	}
+	format, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+	values, err := config.flatValues()
+	if err != nil {
+		return err
+	}
+	data, err := changeDocument(original, format, key, values[key])
+	if err != nil || !sameConfig(data, format, config) {
+		// the file is written in a way the change cannot be made in place, e.g. a value on
+		// several lines of a TOML file, so rewrite it
+		return SaveConfig(config, path)
+	}
+	return WriteFile(path, data)
+}
+
+// flatValues returns the config's values keyed by dotted path
+func (c *Config) flatValues() (map[string]json.RawMessage, error) {
+	data, err := encodeConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return flatten(raw), nil
+}
+
+// replace decodes values into c, leaving c untouched if they are not a valid config
+func (c *Config) replace(values map[string]json.RawMessage) error {
+	data, err := json.Marshal(unflatten(values))
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	cfg, problems := CheckData(data)
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return fmt.Errorf("%s", p.Message)
+		}
+	}
+	*c = *cfg
+	return nil
+}
+
+// leafDoc returns the documentation of the single-valued option with the given dotted path
+func leafDoc(key string) (FieldDoc, bool) {
+	for _, d := range leafDocs(Docs(), "") {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return FieldDoc{}, false
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown config key '%s', see 'salah-cli config-docs' for every option", key)
+}
+
+// LoadUserConfig loads the user config file for changes, returning an empty config if there is
+// none yet, along with the file's path
+func LoadUserConfig() (*Config, string, error) {
+	path, err := GetConfigPath()
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return &Config{Version: CurrentVersion}, path, nil
+	}
+	cfg, err := loadFromFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, path, nil
+}
+
+// Encode returns the config as SaveConfig writes it in the given format
+func Encode(config *Config, format Format) ([]byte, error) {
+	return encodeConfigAs(config, format)
+}
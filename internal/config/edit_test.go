@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigSet(t *testing.T) {
+	cfg := &Config{Latitude: 51.5, Longitude: -0.12}
+	steps := map[string]string{
+		"method":              "isna",
+		"madhab":              "1",
+		"fajr_angle":          "18.5",
+		"adjustments.FajrAdj": "2",
+		"enable_countdown":    "true",
+		"highlight_colour":    "cyan",
+		"engine":              "native",
+	}
+	for key, value := range steps {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("Set(%s, %s): expected no error, got %v", key, value, err)
+		}
+	}
+	if cfg.Latitude != 51.5 || *cfg.Method != 7 || *cfg.Madhab != 1 || *cfg.FajrAngle != 18.5 ||
+		cfg.Adjustments.FajrAdj != 2 || !cfg.EnableCountdown || cfg.HighlightColour != "cyan" || cfg.Engine != "native" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestConfigSet_Invalid(t *testing.T) {
+	angle := 17.0
+	cfg := &Config{Latitude: 51.5, IshaAngle: &angle}
+	tests := map[string]struct{ key, value, expected string }{
+		"unknown key":  {"colour", "red", "unknown config key 'colour'"},
+		"object":       {"adjustments", "2", "unknown config key"},
+		"wrong type":   {"latitude", "north", "latitude must be a number"},
+		"out of range": {"longitude", "200", "longitude must be between"},
+		"bad name":     {"method", "foo", "unknown method 'foo'"},
+		"conflict":     {"isha_interval", "90", "only one of isha_angle or isha_interval"},
+		"version":      {"version", "1", "config migrate"},
+	}
+	for name, tt := range tests {
+		err := cfg.Set(tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected error containing %q, got %v", name, tt.expected, err)
+		}
+	}
+	if cfg.Latitude != 51.5 || cfg.IshaInterval != nil {
+		t.Errorf("expected config unchanged after errors, got %+v", cfg)
+	}
+}
+
+func TestConfigUnset(t *testing.T) {
+	cfg := &Config{Latitude: 51.5}
+	for _, step := range [][2]string{{"method", "isna"}, {"adjustments.IshaAdj", "3"}} {
+		if err := cfg.Set(step[0], step[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, key := range []string{"method", "adjustments"} {
+		if err := cfg.Unset(key); err != nil {
+			t.Fatalf("Unset(%s): expected no error, got %v", key, err)
+		}
+	}
+	if cfg.Method != nil || cfg.Adjustments != nil || cfg.Latitude != 51.5 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	for _, key := range []string{"latitude", "adjustments.IshaAdj", "nope"} {
+		if err := cfg.Unset(key); err == nil {
+			t.Errorf("Unset(%s): expected error", key)
+		}
+	}
+}
+
+func TestSaveChange_KeepsDocument(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "# home\nlatitude: 51.5 # London\nlongitude: -0.12\nmethod: isna\n",
+		"config.toml": "# home\nlatitude = 51.5 # London\nlongitude = -0.12\nmethod = \"isna\"\n\n[mqtt]\nbroker = \"localhost:1883\"\n",
+		"config.json": "{\"longitude\": -0.12, \"latitude\": 51.5, \"method\": 2}",
+	}
+	for name, content := range files {
+		path := writeConfigFile(t, name, content)
+		for _, step := range []struct{ key, value string }{{"madhab", "hanafi"}, {"adjustments.FajrAdj", "2"}, {"adjustments", ""}, {"madhab", "shafi"}} {
+			cfg, err := loadFromFile(path)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if step.value == "" {
+				err = cfg.Unset(step.key)
+			} else {
+				err = cfg.Set(step.key, step.value)
+			}
+			if err == nil {
+				err = SaveChange(cfg, path, step.key)
+			}
+			if err != nil {
+				t.Fatalf("%s: changing %s: %v", name, step.key, err)
+			}
+		}
+
+		data, _ := os.ReadFile(path)
+		got := string(data)
+		cfg, err := loadFromFile(path)
+		if err != nil || cfg.Madhab == nil || *cfg.Madhab != 0 || cfg.Adjustments != nil || cfg.Method == nil {
+			t.Errorf("%s: unexpected config %+v, %v", name, cfg, err)
+		}
+		// unrelated values are kept as written and defaults are not added
+		if strings.Contains(got, "enable_countdown") || strings.Contains(got, "adjustments") || strings.Contains(got, "north_america") {
+			t.Errorf("%s: expected only madhab to change, got %q", name, got)
+		}
+		if name != "config.json" && (!strings.Contains(got, "# home\n") || !strings.Contains(got, "# London") || !strings.Contains(got, "isna")) {
+			t.Errorf("%s: expected comments and values kept, got %q", name, got)
+		}
+		if name == "config.json" && strings.Index(got, "longitude") > strings.Index(got, "latitude") {
+			t.Errorf("%s: expected the order of keys kept, got %q", name, got)
+		}
+	}
+}
+
+func TestLoadUserConfig(t *testing.T) {
+	_, userDir := setupLayers(t, nil)
+
+	cfg, path, err := LoadUserConfig()
+	if err != nil || path != filepath.Join(userDir, DefaultConfigFileName) || cfg.Version != CurrentVersion {
+		t.Fatalf("expected empty config for missing file, got %+v, %s, %v", cfg, path, err)
+	}
+
+	writeConfigFile(t, filepath.Join(userDir, "config.toml"), tomlConfig)
+	cfg, path, err = LoadUserConfig()
+	if err != nil || filepath.Base(path) != "config.toml" || cfg.Latitude != 51.5 {
+		t.Errorf("expected config.toml to be loaded, got %+v, %s, %v", cfg, path, err)
+	}
+
+	if err := os.WriteFile(path, []byte("latitude = 100\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadUserConfig(); err == nil {
+		t.Error("expected error for invalid config")
+	}
+}
+
+func TestResolvedGet(t *testing.T) {
+	setupLayers(t, map[string]string{"SALAH_LATITUDE": "1", "SALAH_LONGITUDE": "2"})
+	resolved, err := Resolve(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := resolved.Get("latitude"); err != nil || s.Value != "1" || s.Origin.Layer != LayerEnv {
+		t.Errorf("unexpected setting %+v, %v", s, err)
+	}
+	if _, err := resolved.Get("nope"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
@@ -0,0 +1,370 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a config file format
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// Formats lists the supported config file formats
+var Formats = []Format{FormatJSON, FormatYAML, FormatTOML}
+
+// ConfigFileNames lists the config files looked for in the config directory
+var ConfigFileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// fileHeader is written at the top of config files in formats that support comments
+const fileHeader = "salah-cli configuration, see 'salah-cli config-docs' for every option"
+
+// FormatOf returns the format of a config file from its extension
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported config file %s, use .json, .yaml, .yml or .toml", path)
+	}
+}
+
+// PathWithFormat returns path with its extension replaced for the given format
+func PathWithFormat(path string, format Format) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + string(format)
+}
+
+// keyPosition is the 1-based line and column of a key in a config file
+type keyPosition struct {
+	line, column int
+}
+
+// decodeDocument converts a config document to JSON, returning the position of every key by dotted path
+func decodeDocument(data []byte, format Format) ([]byte, map[string]keyPosition, *Problem) {
+	switch format {
+	case FormatYAML:
+		return decodeYAML(data)
+	case FormatTOML:
+		return decodeTOML(data)
+	default:
+		positions := map[string]keyPosition{}
+		for path, offset := range keyOffsets(data) {
+			line, column := position(data, offset)
+			positions[path] = keyPosition{line, column}
+		}
+		return data, positions, nil
+	}
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+func decodeYAML(data []byte) ([]byte, map[string]keyPosition, *Problem) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		p := &Problem{Severity: SeverityError, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+			p.Column = 1
+		}
+		return nil, nil, p
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, &Problem{Severity: SeverityError, Line: 1, Column: 1, Message: "config must be a YAML mapping"}
+	}
+
+	var value any
+	if err := doc.Content[0].Decode(&value); err != nil {
+		return nil, nil, &Problem{Severity: SeverityError, Line: 1, Column: 1, Message: err.Error()}
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, &Problem{Severity: SeverityError, Line: 1, Column: 1, Message: fmt.Sprintf("unsupported value: %v", err)}
+	}
+
+	positions := map[string]keyPosition{}
+	var walk func(n *yaml.Node, prefix string)
+	walk = func(n *yaml.Node, prefix string) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := n.Content[i]
+				path := joinPath(prefix, key.Value)
+				positions[path] = keyPosition{key.Line, key.Column}
+				walk(n.Content[i+1], path)
+			}
+		case yaml.SequenceNode:
+			for i, item := range n.Content {
+				walk(item, fmt.Sprintf("%s[%d]", prefix, i))
+			}
+		}
+	}
+	walk(doc.Content[0], "")
+	return out, positions, nil
+}
+
+var tomlTableHeader = regexp.MustCompile(`^\[\[?\s*([^\]]+?)\s*\]\]?`)
+
+func decodeTOML(data []byte) ([]byte, map[string]keyPosition, *Problem) {
+	var value map[string]any
+	if _, err := toml.Decode(string(data), &value); err != nil {
+		p := &Problem{Severity: SeverityError, Message: err.Error()}
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			p.Message = parseErr.Message
+			p.Line, p.Column = parseErr.Position.Line, parseErr.Position.Col
+		}
+		return nil, nil, p
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, &Problem{Severity: SeverityError, Line: 1, Column: 1, Message: fmt.Sprintf("unsupported value: %v", err)}
+	}
+
+	// TOML has no position information for keys, so find them line by line
+	positions := map[string]keyPosition{}
+	table := ""
+	arrays := map[string]int{}
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		column := len(line) - len(strings.TrimLeft(line, " \t")) + 1
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			m := tomlTableHeader.FindStringSubmatch(trimmed)
+			if m == nil {
+				continue
+			}
+			name := arrayTablePath(unquoteKey(m[1]), arrays)
+			table = name
+			if strings.HasPrefix(trimmed, "[[") {
+				positions[name] = keyPosition{i + 1, column}
+				table = fmt.Sprintf("%s[%d]", name, arrays[name])
+				arrays[name]++
+			}
+			positions[table] = keyPosition{i + 1, column}
+		default:
+			key, _, ok := strings.Cut(trimmed, "=")
+			if ok {
+				positions[joinPath(table, unquoteKey(strings.TrimSpace(key)))] = keyPosition{i + 1, column}
+			}
+		}
+	}
+	return out, positions, nil
+}
+
+// arrayTablePath refers the sub-tables of an array of tables, such as [hooks.env] after [[hooks]],
+// to the array's last item: hooks[0].env
+func arrayTablePath(name string, arrays map[string]int) string {
+	for array, count := range arrays {
+		if rest, ok := strings.CutPrefix(name, array+"."); ok {
+			return fmt.Sprintf("%s[%d].%s", array, count-1, rest)
+		}
+	}
+	return name
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func unquoteKey(key string) string {
+	if s, err := strconv.Unquote(key); err == nil {
+		return s
+	}
+	return strings.Trim(key, "'")
+}
+
+// encodeConfigAs returns the config as written by SaveConfig in the given format
+func encodeConfigAs(config *Config, format Format) ([]byte, error) {
+	data, err := encodeConfig(config)
+	if err != nil || format == FormatJSON {
+		return data, err
+	}
+
+	node, err := jsonToNode(json.NewDecoder(bytes.NewReader(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert config: %w", err)
+	}
+	var buf bytes.Buffer
+	switch format {
+	case FormatYAML:
+		doc := &yaml.Node{Kind: yaml.DocumentNode, HeadComment: fileHeader, Content: []*yaml.Node{node}}
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("failed to encode config to YAML: %w", err)
+		}
+	case FormatTOML:
+		fmt.Fprintf(&buf, "# %s\n\n", fileHeader)
+		writeTOMLTable(&buf, node, "")
+	default:
+		return nil, fmt.Errorf("unsupported config format '%s'", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonToNode converts a JSON document into a YAML node, keeping the order of keys
+func jsonToNode(dec *json.Decoder) (*yaml.Node, error) {
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		kind, end := yaml.MappingNode, json.Delim('}')
+		if v == '[' {
+			kind, end = yaml.SequenceNode, ']'
+		}
+		node := &yaml.Node{Kind: kind}
+		for dec.More() {
+			if kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := jsonToNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if tok, err := dec.Token(); err != nil || tok != end {
+			return nil, fmt.Errorf("unterminated %v", v)
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+// writeTOMLTable writes the keys of a mapping node, followed by its nested tables and arrays of tables
+func writeTOMLTable(buf *bytes.Buffer, node *yaml.Node, name string) {
+	var tables, arrays []int
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		switch {
+		case value.Kind == yaml.MappingNode:
+			tables = append(tables, i)
+		case isTableArray(value):
+			arrays = append(arrays, i)
+		case value.Tag == "!!null":
+		default:
+			fmt.Fprintf(buf, "%s = %s\n", tomlKey(node.Content[i].Value), tomlValue(value))
+		}
+	}
+	for _, i := range tables {
+		table := joinPath(name, tomlKey(node.Content[i].Value))
+		fmt.Fprintf(buf, "\n[%s]\n", table)
+		writeTOMLTable(buf, node.Content[i+1], table)
+	}
+	for _, i := range arrays {
+		table := joinPath(name, tomlKey(node.Content[i].Value))
+		for _, item := range node.Content[i+1].Content {
+			fmt.Fprintf(buf, "\n[[%s]]\n", table)
+			writeTOMLTable(buf, item, table)
+		}
+	}
+}
+
+// isTableArray reports whether node is a non-empty sequence of mappings, such as the hooks
+func isTableArray(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(key string) string {
+	if bareTOMLKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlValue formats a scalar, sequence or inline table
+func tomlValue(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		items := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			items[i] = tomlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case yaml.MappingNode:
+		var pairs []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i+1].Tag != "!!null" {
+				pairs = append(pairs, tomlKey(node.Content[i].Value)+" = "+tomlValue(node.Content[i+1]))
+			}
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+	if node.Tag == "!!str" {
+		return tomlString(node.Value)
+	}
+	return node.Value
+}
+
+// tomlString quotes s as a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const yamlConfig = `# London
+latitude: 51.5
+longitude: -0.12
+method: egyptian # by name
+adjustments:
+  FajrAdj: 2
+`
+
+const tomlConfig = `# London
+latitude = 51.5
+longitude = -0.12
+method = "egyptian"
+
+[adjustments]
+FajrAdj = 2
+`
+
+// writeConfigFile writes content to name, a path or a file name in a new temporary directory,
+// and returns its path
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.TempDir(), name)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]Format{
+		"config.json": FormatJSON,
+		"config.yaml": FormatYAML,
+		"config.YML":  FormatYAML,
+		"config.toml": FormatTOML,
+	}
+	for path, expected := range tests {
+		if got, err := FormatOf(path); err != nil || got != expected {
+			t.Errorf("FormatOf(%s) = %s, %v, expected %s", path, got, err, expected)
+		}
+	}
+	if _, err := FormatOf("config.ini"); err == nil {
+		t.Error("expected error for unsupported extension")
+	}
+}
+
+func TestPathWithFormat(t *testing.T) {
+	if got := PathWithFormat("/a/config.json", FormatTOML); got != "/a/config.toml" {
+		t.Errorf("unexpected path %s", got)
+	}
+}
+
+func TestLoadFromFile_YAMLAndTOML(t *testing.T) {
+	for name, content := range map[string]string{"config.yaml": yamlConfig, "config.toml": tomlConfig} {
+		cfg, err := loadFromFile(writeConfigFile(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if cfg.Latitude != 51.5 || *cfg.Method != 2 || cfg.Adjustments.FajrAdj != 2 {
+			t.Errorf("%s: unexpected config %+v", name, cfg)
+		}
+	}
+}
+
+func TestCheckDataAs_Positions(t *testing.T) {
+	tests := []struct {
+		format  Format
+		content string
+		line    int
+		column  int
+	}{
+		{FormatYAML, "latitude: 1\nlongitude: 2\nadjustments:\n  Bogus: 1\n", 4, 3},
+		{FormatTOML, "latitude = 1\nlongitude = 2\n\n[adjustments]\nBogus = 1\n", 5, 1},
+	}
+	for _, tt := range tests {
+		_, problems := CheckDataAs([]byte(tt.content), tt.format)
+		if len(problems) != 1 {
+			t.Fatalf("%s: expected one problem, got %v", tt.format, problems)
+		}
+		p := problems[0]
+		if p.Field != "adjustments.Bogus" || p.Line != tt.line || p.Column != tt.column {
+			t.Errorf("%s: expected adjustments.Bogus at %d:%d, got %+v", tt.format, tt.line, tt.column, p)
+		}
+	}
+}
+
+func TestCheckDataAs_ParseErrors(t *testing.T) {
+	tests := map[Format]string{
+		FormatYAML: "latitude: 1\nlongitude: 2\n  bad: 3\n",
+		FormatTOML: "latitude = 1\nlongitude =\n",
+	}
+	for format, content := range tests {
+		_, problems := CheckDataAs([]byte(content), format)
+		if len(problems) != 1 || problems[0].Severity != SeverityError || problems[0].Line < 2 {
+			t.Errorf("%s: expected a parse error from line 2 on, got %v", format, problems)
+		}
+	}
+
+	_, problems := CheckDataAs([]byte("- 1\n- 2\n"), FormatYAML)
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "mapping") {
+		t.Errorf("expected error for a YAML sequence, got %v", problems)
+	}
+}
+
+func TestSaveConfig_YAMLAndTOML(t *testing.T) {
+	method, madhab := Method(2), Madhab(1)
+	original := &Config{
+		Version:         CurrentVersion,
+		Latitude:        51.5,
+		Longitude:       -0.12,
+		Method:          &method,
+		Madhab:          &madhab,
+		Adjustments:     &PrayerAdjustments{FajrAdj: 2, IshaAdj: -3},
+		HighlightColour: `say "hi"`,
+		Hooks: []Hook{
+			{Event: "at:fajr", Command: "notify-send", Args: []string{"{{.Prayer}}"}, Env: map[string]string{"LANG": "C"}},
+			{Event: "day:start", Command: "true", Timeout: "5s"},
+		},
+	}
+	for _, format := range []Format{FormatYAML, FormatTOML} {
+		path := filepath.Join(t.TempDir(), "config."+string(format))
+		if err := SaveConfig(original, path); err != nil {
+			t.Fatalf("%s: expected no error, got %v", format, err)
+		}
+		data, _ := os.ReadFile(path)
+		if !strings.HasPrefix(string(data), "# "+fileHeader) || !strings.Contains(string(data), "egyptian") {
+			t.Errorf("%s: expected header comment and method name, got\n%s", format, data)
+		}
+
+		loaded, err := loadFromFile(path)
+		if err != nil {
+			t.Fatalf("%s: expected no error reloading, got %v", format, err)
+		}
+		if !reflect.DeepEqual(loaded, original) {
+			t.Errorf("%s: expected %+v after reload, got %+v", format, original, loaded)
+		}
+		if format == FormatTOML && strings.Count(string(data), "\n[[hooks]]\n") != 2 {
+			t.Errorf("expected the hooks as an array of tables, got\n%s", data)
+		}
+	}
+}
+
+func TestGetConfigPath_Discovery(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping Unix path test on Windows")
+	}
+	originalGetEnv := getEnv
+	defer func() { getEnv = originalGetEnv }()
+	home := t.TempDir()
+	getEnv = func(key string) string {
+		if key == "XDG_CONFIG_HOME" {
+			return home
+		}
+		return ""
+	}
+	dir := filepath.Join(home, "salah-cli")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.yml"), []byte(yamlConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := GetConfigPath()
+	if err != nil || path != filepath.Join(dir, "config.yml") {
+		t.Errorf("expected config.yml, got %s, %v", path, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte(tomlConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetConfigPath(); err == nil || !strings.Contains(err.Error(), "several config files") {
+		t.Errorf("expected error for several config files, got %v", err)
+	}
+}
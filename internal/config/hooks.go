@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"salah-cli/pkg/salah"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Hook runs a command on a prayer time event. Command, Args and the Env values are templates
+// (see HookData).
+type Hook struct {
+	Event   string            `json:"event" doc:"Event running the hook: before:<prayer>:<duration>, at:<prayer>, end:<prayer> or day:start" example:"before:maghrib:5m"`
+	Command string            `json:"command" doc:"Shell command, or the program run with args when they are given" example:"./pause-builds.sh"`
+	Args    []string          `json:"args,omitempty" doc:"Arguments passed to command, which then runs without a shell" example:"[\"{{.Prayer}}\", \"{{.Time}}\"]"`
+	Env     map[string]string `json:"env,omitempty" doc:"Environment variables set for the command" example:"{\"PRAYER\": \"{{.Prayer}}\"}"`
+	Timeout string            `json:"timeout,omitempty" doc:"Time after which the command is stopped" default:"30s" example:"2m"`
+}
+
+// DefaultHookTimeout is the timeout of hooks that don't set one
+const DefaultHookTimeout = 30 * time.Second
+
+// HookKind is the kind of event running a hook
+type HookKind string
+
+const (
+	// HookBefore events occur a duration before a prayer time
+	HookBefore HookKind = "before"
+	// HookAt events occur at a prayer time
+	HookAt HookKind = "at"
+	// HookEnd events occur when the window of a prayer ends, e.g. Fajr at sunrise
+	HookEnd HookKind = "end"
+	// HookDayStart events occur at midnight
+	HookDayStart HookKind = "day"
+)
+
+// HookEvent is a parsed hook event
+type HookEvent struct {
+	Kind   HookKind
+	Prayer salah.Prayer
+	// Before is the time before the prayer of HookBefore events
+	Before time.Duration
+}
+
+// ParseHookEvent parses an event such as "before:maghrib:5m", "at:fajr", "end:isha" or "day:start"
+func ParseHookEvent(s string) (HookEvent, error) {
+	parts := strings.Split(s, ":")
+	invalid := fmt.Errorf("invalid hook event '%s', expected before:<prayer>:<duration>, at:<prayer>, end:<prayer> or day:start", s)
+
+	switch kind := HookKind(parts[0]); {
+	case kind == HookDayStart && len(parts) == 2 && parts[1] == "start":
+		return HookEvent{Kind: HookDayStart, Prayer: salah.NoPrayer}, nil
+	case kind == HookBefore && len(parts) == 3, (kind == HookAt || kind == HookEnd) && len(parts) == 2:
+		prayer, err := salah.ParsePrayer(parts[1])
+		if err != nil {
+			return HookEvent{}, fmt.Errorf("%w: %w", invalid, err)
+		}
+		if kind == HookEnd && prayer == salah.Sunrise {
+			return HookEvent{}, fmt.Errorf("%w: sunrise has no window", invalid)
+		}
+		event := HookEvent{Kind: kind, Prayer: prayer}
+		if kind == HookBefore {
+			if event.Before, err = time.ParseDuration(parts[2]); err != nil || event.Before <= 0 {
+				return HookEvent{}, fmt.Errorf("%w: '%s' is not a positive duration", invalid, parts[2])
+			}
+		}
+		return event, nil
+	}
+	return HookEvent{}, invalid
+}
+
+// TimeoutDuration returns the hook's timeout, DefaultHookTimeout if it sets none
+func (h Hook) TimeoutDuration() (time.Duration, error) {
+	if h.Timeout == "" {
+		return DefaultHookTimeout, nil
+	}
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("timeout '%s' is not a positive duration", h.Timeout)
+	}
+	return d, nil
+}
+
+// HookData is the data available to the templates of a hook
+type HookData struct {
+	// Event is the event as written in the config
+	Event string
+	// Prayer is the prayer of the event, empty for day:start
+	Prayer string
+	// Time is the time of the prayer, or midnight for day:start. Format it with e.g.
+	// {{.Time.Format "15:04"}}
+	Time time.Time
+	// Latitude and Longitude are the configured location
+	Latitude, Longitude float64
+}
+
+// ParseHookTemplate parses one of the templates of a hook
+func ParseHookTemplate(text string) (*template.Template, error) {
+	return template.New("hook").Option("missingkey=error").Parse(text)
+}
+
+// hookProblems checks the hook at index i of the config
+func hookProblems(i int, h Hook, add func(severity Severity, field string, err error)) {
+	field := fmt.Sprintf("hooks[%d]", i)
+	if _, err := ParseHookEvent(h.Event); err != nil {
+		add(SeverityError, field+".event", err)
+	}
+	if strings.TrimSpace(h.Command) == "" {
+		add(SeverityError, field+".command", fmt.Errorf("%s.command is required", field))
+	}
+	if _, err := h.TimeoutDuration(); err != nil {
+		add(SeverityError, field+".timeout", fmt.Errorf("%s.%w", field, err))
+	}
+
+	type hookTemplate struct{ field, text string }
+	templates := []hookTemplate{{field + ".command", h.Command}}
+	for j, arg := range h.Args {
+		templates = append(templates, hookTemplate{fmt.Sprintf("%s.args[%d]", field, j), arg})
+	}
+	for _, name := range slices.Sorted(maps.Keys(h.Env)) {
+		templates = append(templates, hookTemplate{field + ".env." + name, h.Env[name]})
+	}
+	for _, t := range templates {
+		if _, err := ParseHookTemplate(t.text); err != nil {
+			add(SeverityError, t.field, fmt.Errorf("invalid template in %s: %w", t.field, err))
+		}
+	}
+}
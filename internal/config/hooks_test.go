@@ -0,0 +1,64 @@
+package config
+
+import (
+	"salah-cli/pkg/salah"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHookEvent(t *testing.T) {
+	valid := map[string]HookEvent{
+		"before:maghrib:5m": {Kind: HookBefore, Prayer: salah.Maghrib, Before: 5 * time.Minute},
+		"at:Fajr":           {Kind: HookAt, Prayer: salah.Fajr},
+		"at:sunrise":        {Kind: HookAt, Prayer: salah.Sunrise},
+		"end:isha":          {Kind: HookEnd, Prayer: salah.Isha},
+		"day:start":         {Kind: HookDayStart, Prayer: salah.NoPrayer},
+	}
+	for s, want := range valid {
+		got, err := ParseHookEvent(s)
+		if err != nil || got != want {
+			t.Errorf("ParseHookEvent(%q) = %+v, %v; want %+v", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "at", "at:tahajjud", "before:asr", "before:asr:-5m", "before:asr:soon", "end:sunrise", "day:end", "at:fajr:5m"} {
+		if _, err := ParseHookEvent(s); err == nil {
+			t.Errorf("ParseHookEvent(%q): expected an error", s)
+		}
+	}
+}
+
+func TestHook_TimeoutDuration(t *testing.T) {
+	if d, err := (Hook{}).TimeoutDuration(); err != nil || d != DefaultHookTimeout {
+		t.Errorf("expected the default timeout, got %v %v", d, err)
+	}
+	if d, err := (Hook{Timeout: "2m"}).TimeoutDuration(); err != nil || d != 2*time.Minute {
+		t.Errorf("expected 2m, got %v %v", d, err)
+	}
+	if _, err := (Hook{Timeout: "0s"}).TimeoutDuration(); err == nil {
+		t.Error("expected an error for a zero timeout")
+	}
+}
+
+func TestCheckData_Hooks(t *testing.T) {
+	cfg, problems := CheckData([]byte(`{"latitude": 1, "longitude": 2, "hooks": [
+  {"event": "at:fajr", "command": "notify-send {{.Prayer}}", "env": {"AT": "{{.Time.Format \"15:04\"}}"}},
+  {"event": "at:noon", "command": "", "args": ["{{.Prayer"], "timeout": "forever"}
+]}`))
+	if len(cfg.Hooks) != 2 || cfg.Hooks[0].Env["AT"] == "" {
+		t.Fatalf("expected the hooks to be decoded, got %+v", cfg.Hooks)
+	}
+
+	var fields []string
+	for _, p := range problems {
+		fields = append(fields, p.Field)
+	}
+	want := "hooks[1].event hooks[1].command hooks[1].timeout hooks[1].args[0]"
+	if strings.Join(fields, " ") != want {
+		t.Errorf("expected problems in %s, got %v", want, problems)
+	}
+	if problems[0].Line != 3 {
+		t.Errorf("expected the event problem on line 3, got %v", problems[0])
+	}
+}
@@ -0,0 +1,353 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layer is a source of config values. Each layer overrides the ones before it.
+type Layer string
+
+const (
+	LayerDefault Layer = "default"
+	LayerSystem  Layer = "system"
+	LayerUser    Layer = "user"
+	LayerEnv     Layer = "env"
+	LayerFlag    Layer = "flag"
+)
+
+// EnvPrefix prefixes the environment variables overriding config options, e.g. SALAH_LATITUDE
+// or SALAH_ADJUSTMENTS_FAJRADJ
+const EnvPrefix = "SALAH_"
+
+// ErrNoConfig is returned by Resolve when there is no config file and the location isn't set
+// otherwise
+var ErrNoConfig = errors.New("no config file found")
+
+// For testability, allow overriding the directory of the system-wide config file
+var getSystemConfigDir = func() string {
+	if getOS() == "windows" {
+		if programData := getEnv("ProgramData"); programData != "" {
+			return filepath.Join(programData, AppName)
+		}
+		return ""
+	}
+	return filepath.Join("/etc", AppName)
+}
+
+// SetSystemConfigDir makes Resolve look for the system-wide config file in dir, so tests of
+// other packages don't read the machine's. It returns a function restoring the default.
+func SetSystemConfigDir(dir string) (restore func()) {
+	original := getSystemConfigDir
+	getSystemConfigDir = func() string { return dir }
+	return func() { getSystemConfigDir = original }
+}
+
+// OverrideFlag is a command-line flag overriding a config option
+type OverrideFlag struct {
+	Name  string
+	Key   string
+	Usage string
+}
+
+// OverrideFlags lists the flags accepted by the commands that load the config
+var OverrideFlags = []OverrideFlag{
+	{"lat", "latitude", "latitude in degrees, overriding the config"},
+	{"lon", "longitude", "longitude in degrees, overriding the config"},
+	{"method", "method", "calculation method name or number, overriding the config"},
+	{"madhab", "madhab", "madhab (shafi or hanafi), overriding the config"},
+}
+
+// Overrides holds the OverrideFlags given on the command line, by flag name
+type Overrides map[string]string
+
+// AddFlags registers OverrideFlags on fs, storing the values given in o
+func (o Overrides) AddFlags(fs *flag.FlagSet) {
+	for _, f := range OverrideFlags {
+		fs.Func(f.Name, f.Usage, func(value string) error {
+			o[f.Name] = value
+			return nil
+		})
+	}
+}
+
+// Origin is where an effective config value came from
+type Origin struct {
+	Layer Layer
+	// Source is the file, environment variable or flag that set the value, empty for defaults
+	Source string
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return string(o.Layer)
+	}
+	return fmt.Sprintf("%s (%s)", o.Layer, o.Source)
+}
+
+// Setting is the effective value of a config option
+type Setting struct {
+	// Key is the option's dotted path, e.g. "adjustments.FajrAdj"
+	Key string
+	// Value is the value as JSON, or a description of the default when no layer sets it
+	Value  string
+	Origin Origin
+}
+
+// SecretMask is shown instead of the values of secret options
+const SecretMask = `"********"`
+
+// Masked returns the setting with the values of secret options, like mqtt.password or the
+// secret of a webhook, replaced by SecretMask
+func (s Setting) Masked() Setting {
+	doc, ok := leafDoc(s.Key)
+	if !ok || s.Origin.Layer == LayerDefault {
+		return s
+	}
+	if doc.Secret {
+		s.Value = SecretMask
+		return s
+	}
+	secrets := map[string]bool{}
+	for _, item := range doc.Items {
+		if item.Secret {
+			secrets[item.Key] = true
+		}
+	}
+	if len(secrets) == 0 {
+		return s
+	}
+	node, err := jsonToNode(json.NewDecoder(strings.NewReader(s.Value)))
+	if err != nil {
+		s.Value = SecretMask
+		return s
+	}
+	for _, item := range node.Content {
+		for i := 0; item.Kind == yaml.MappingNode && i+1 < len(item.Content); i += 2 {
+			if secrets[item.Content[i].Value] {
+				item.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "********"}
+			}
+		}
+	}
+	var indented, compact bytes.Buffer
+	writeJSONNode(&indented, node, "")
+	if json.Compact(&compact, indented.Bytes()) != nil {
+		s.Value = SecretMask
+		return s
+	}
+	s.Value = compact.String()
+	return s
+}
+
+// Resolved is the config built from every layer
+type Resolved struct {
+	Config *Config
+	// Settings lists every option in the order of Docs
+	Settings []Setting
+	// Files lists the config files read, lowest precedence first
+	Files []string
+}
+
+// Resolve builds the effective config from, in increasing order of precedence: built-in defaults,
+// the system config file, the user config file, SALAH_* environment variables and overrides
+func Resolve(overrides Overrides) (*Resolved, error) {
+	return resolve(overrides, true)
+}
+
+// ResolveSettings is Resolve for commands that don't use the configured location: there need
+// not be a config file nor a location, latitude and longitude being left at zero when unset
+func ResolveSettings(overrides Overrides) (*Resolved, error) {
+	return resolve(overrides, false)
+}
+
+func resolve(overrides Overrides, needLocation bool) (*Resolved, error) {
+	leaves := leafDocs(Docs(), "")
+	values := map[string]json.RawMessage{}
+	origins := map[string]Origin{}
+	resolved := &Resolved{}
+
+	userPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	files := []Origin{{LayerUser, userPath}}
+	if dir := getSystemConfigDir(); dir != "" {
+		systemPath, err := findConfigFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		files = append([]Origin{{LayerSystem, systemPath}}, files...)
+	}
+	for _, file := range files {
+		raw, err := readLayer(file.Source)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		resolved.Files = append(resolved.Files, file.Source)
+		for key, value := range flatten(raw) {
+			values[key], origins[key] = value, file
+		}
+	}
+
+	types := map[string]string{}
+	for _, d := range leaves {
+		types[d.Key] = d.Type
+		name := EnvName(d.Key)
+		if value := getEnv(name); value != "" {
+			values[d.Key], origins[d.Key] = argValue(value, d.Type), Origin{LayerEnv, name}
+		}
+	}
+	for _, f := range OverrideFlags {
+		if value, ok := overrides[f.Name]; ok {
+			values[f.Key], origins[f.Key] = argValue(value, types[f.Key]), Origin{LayerFlag, "--" + f.Name}
+		}
+	}
+
+	if needLocation && len(resolved.Files) == 0 && (origins["latitude"].Layer == "" || origins["longitude"].Layer == "") {
+		return nil, fmt.Errorf("%w at %s, run 'salah-cli setup' or set %s and %s",
+			ErrNoConfig, userPath, EnvName("latitude"), EnvName("longitude"))
+	}
+
+	data, err := json.Marshal(unflatten(values))
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge config: %w", err)
+	}
+	cfg, problems := CheckData(data)
+	for _, p := range problems {
+		if p.Severity != SeverityError {
+			continue
+		}
+		if origin, ok := origins[p.Field]; ok {
+			return nil, fmt.Errorf("invalid config from %s: %s", origin, p.Message)
+		}
+		return nil, fmt.Errorf("invalid config: %s", p.Message)
+	}
+	resolved.Config = cfg
+
+	for _, d := range leaves {
+		setting := Setting{Key: d.Key, Value: defaultValue(d), Origin: Origin{Layer: LayerDefault}}
+		if value, ok := values[d.Key]; ok {
+			var buf bytes.Buffer
+			if json.Compact(&buf, value) == nil {
+				setting.Value = buf.String()
+			}
+			setting.Origin = origins[d.Key]
+		}
+		resolved.Settings = append(resolved.Settings, setting)
+	}
+	return resolved, nil
+}
+
+// EnvName returns the environment variable overriding the option with the given dotted path
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// readLayer reads and checks a config file, returning its values after migration
+func readLayer(path string) (map[string]json.RawMessage, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open config file %s: %w", path, err)
+	}
+	_, raw, problems := checkData(data, format)
+	if err := fileError(path, problems); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// leafDocs returns the options that hold a single value, keyed by dotted path
+func leafDocs(docs []FieldDoc, prefix string) []FieldDoc {
+	var leaves []FieldDoc
+	for _, d := range docs {
+		d.Key = prefix + d.Key
+		if len(d.Keys) > 0 {
+			leaves = append(leaves, leafDocs(d.Keys, d.Key+".")...)
+			continue
+		}
+		leaves = append(leaves, d)
+	}
+	return leaves
+}
+
+// flatten keys the values of a config by dotted path, so layers can override single keys of objects
+func flatten(raw map[string]json.RawMessage) map[string]json.RawMessage {
+	out := map[string]json.RawMessage{}
+	for key, value := range raw {
+		var object map[string]json.RawMessage
+		if bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) && json.Unmarshal(value, &object) == nil {
+			for child, v := range object {
+				out[key+"."+child] = v
+			}
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// unflatten reverses flatten
+func unflatten(values map[string]json.RawMessage) map[string]any {
+	out := map[string]any{}
+	for key, value := range values {
+		parent, child, nested := strings.Cut(key, ".")
+		if !nested {
+			out[key] = value
+			continue
+		}
+		object, _ := out[parent].(map[string]json.RawMessage)
+		if object == nil {
+			object = map[string]json.RawMessage{}
+			out[parent] = object
+		}
+		object[child] = value
+	}
+	return out
+}
+
+// argValue converts a flag or environment variable value to JSON. Numbers, booleans, arrays and
+// objects are kept for options that are not plain strings, anything else becomes a string.
+func argValue(value, typ string) json.RawMessage {
+	if typ != "string" {
+		var v any
+		if json.Unmarshal([]byte(value), &v) == nil {
+			switch v.(type) {
+			case float64, bool, []any, map[string]any:
+				return json.RawMessage(value)
+			}
+		}
+	}
+	quoted, _ := json.Marshal(value)
+	return quoted
+}
+
+// defaultValue describes the value of an option that no layer sets
+func defaultValue(d FieldDoc) string {
+	switch {
+	case d.Default == "":
+		return "(not set)"
+	case strings.Contains(d.Default, " "):
+		return "(" + d.Default + ")"
+	case strings.HasPrefix(d.Type, "string"):
+		return strconv.Quote(d.Default)
+	default:
+		return d.Default
+	}
+}
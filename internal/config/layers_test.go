@@ -0,0 +1,199 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"salah-cli/pkg/salah"
+	"strings"
+	"testing"
+)
+
+// setupLayers points the system and user config directories at temporary directories and
+// serves environment variables from env
+func setupLayers(t *testing.T, env map[string]string) (systemDir, userDir string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping Unix path test on Windows")
+	}
+	systemDir, home := t.TempDir(), t.TempDir()
+	userDir = filepath.Join(home, AppName)
+	if err := os.MkdirAll(userDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	originalGetEnv := getEnv
+	t.Cleanup(func() { getEnv = originalGetEnv })
+	t.Cleanup(SetSystemConfigDir(systemDir))
+	getEnv = func(key string) string {
+		if key == "XDG_CONFIG_HOME" {
+			return home
+		}
+		return env[key]
+	}
+	return systemDir, userDir
+}
+
+func settingsByKey(r *Resolved) map[string]Setting {
+	out := map[string]Setting{}
+	for _, s := range r.Settings {
+		out[s.Key] = s
+	}
+	return out
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	systemDir, userDir := setupLayers(t, map[string]string{
+		"SALAH_MADHAB":              "hanafi",
+		"SALAH_ADJUSTMENTS_ISHAADJ": "3",
+		"SALAH_LONGITUDE":           "-0.5",
+	})
+	writeConfigFile(t, filepath.Join(systemDir, "config.toml"), "latitude = 10\nlongitude = 20\nmethod = \"isna\"\nengine = \"native\"\n\n[adjustments]\nFajrAdj = 1\nDhuhrAdj = 4\n")
+	writeConfigFile(t, filepath.Join(userDir, "config.yaml"), "latitude: 51.5\nlongitude: -0.12\nadjustments:\n  FajrAdj: 2\n")
+
+	resolved, err := Resolve(Overrides{"method": "egyptian"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	cfg := resolved.Config
+	if cfg.Latitude != 51.5 || cfg.Longitude != -0.5 || *cfg.Method != 2 || *cfg.Madhab != 1 || cfg.Engine != "native" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if a := cfg.Adjustments; a.FajrAdj != 2 || a.DhuhrAdj != 4 || a.IshaAdj != 3 {
+		t.Errorf("expected adjustments merged across layers, got %+v", a)
+	}
+	if len(resolved.Files) != 2 {
+		t.Errorf("expected system and user files, got %v", resolved.Files)
+	}
+
+	settings := settingsByKey(resolved)
+	expected := map[string]string{
+		"latitude":               "user (" + filepath.Join(userDir, "config.yaml") + ")",
+		"longitude":              "env (SALAH_LONGITUDE)",
+		"method":                 "flag (--method)",
+		"engine":                 "system (" + filepath.Join(systemDir, "config.toml") + ")",
+		"adjustments.DhuhrAdj":   "system (" + filepath.Join(systemDir, "config.toml") + ")",
+		"adjustments.IshaAdj":    "env (SALAH_ADJUSTMENTS_ISHAADJ)",
+		"high_latitude_rule":     "default",
+		"adjustments.SunriseAdj": "default",
+	}
+	for key, origin := range expected {
+		if got := settings[key].Origin.String(); got != origin {
+			t.Errorf("%s: expected origin %s, got %s", key, origin, got)
+		}
+	}
+	if v := settings["method"].Value; v != `"egyptian"` {
+		t.Errorf("expected method value \"egyptian\", got %s", v)
+	}
+	if v := settings["high_latitude_rule"].Value; v != `"middle_of_the_night"` {
+		t.Errorf("expected default high_latitude_rule, got %s", v)
+	}
+}
+
+func TestResolve_EnvOnly(t *testing.T) {
+	setupLayers(t, map[string]string{"SALAH_LATITUDE": "21.4", "SALAH_LONGITUDE": "39.8", "SALAH_METHOD": "4"})
+	cfg, err := LoadWith(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Latitude != 21.4 || *cfg.Method != 4 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	setupLayers(t, map[string]string{"SALAH_MADHAB": "hanafi"})
+	resolved, err := ResolveSettings(Overrides{"method": "isna"})
+	if err != nil {
+		t.Fatalf("expected no error without a location, got %v", err)
+	}
+	if cfg := resolved.Config; cfg.Latitude != 0 || *cfg.Method != Method(salah.MethodNorthAmerica) || *cfg.Madhab != Madhab(salah.MadhabHanafi) {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		overrides Overrides
+		expected  string
+	}{
+		{"no config", nil, nil, "no config file found"},
+		{"invalid env", map[string]string{"SALAH_LATITUDE": "north", "SALAH_LONGITUDE": "1"}, nil, "env (SALAH_LATITUDE): latitude must be a number"},
+		{"invalid flag", map[string]string{"SALAH_LATITUDE": "1", "SALAH_LONGITUDE": "1"}, Overrides{"lat": "91"}, "flag (--lat): latitude must be between -90 and 90"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupLayers(t, tt.env)
+			_, err := Resolve(tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+			if errors.Is(err, ErrNoConfig) != (tt.name == "no config") {
+				t.Errorf("expected only a missing config to be ErrNoConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestResolve_InvalidFile(t *testing.T) {
+	systemDir, _ := setupLayers(t, nil)
+	path := filepath.Join(systemDir, "config.json")
+	writeConfigFile(t, path, "{\n  \"latitude\": 100\n}\n")
+	_, err := Resolve(nil)
+	if err == nil || !strings.Contains(err.Error(), path+":2:3") {
+		t.Errorf("expected error with position in %s, got %v", path, err)
+	}
+}
+
+func TestSetting_Masked(t *testing.T) {
+	user := Origin{Layer: LayerUser, Source: "config.json"}
+	tests := []struct {
+		setting Setting
+		want    string
+	}{
+		{Setting{Key: "mqtt.password", Value: `"hunter2"`, Origin: user}, SecretMask},
+		{Setting{Key: "mqtt.username", Value: `"ha"`, Origin: user}, `"ha"`},
+		{Setting{Key: "mqtt.password", Value: "", Origin: Origin{Layer: LayerDefault}}, ""},
+		{
+			Setting{Key: "webhooks", Value: `[{"url":"https://a.example","secret":"s3cret"},{"url":"https://b.example"}]`, Origin: user},
+			`[{"url":"https://a.example","secret":"********"},{"url":"https://b.example"}]`,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.setting.Masked(); got.Value != tt.want || got.Origin != tt.setting.Origin {
+			t.Errorf("%s: expected %s, got %s", tt.setting.Key, tt.want, got.Value)
+		}
+	}
+}
+
+func TestOverrides_AddFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	overrides := Overrides{}
+	overrides.AddFlags(fs)
+	if err := fs.Parse([]string{"--lat", "1.5", "--madhab", "hanafi"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 2 || overrides["lat"] != "1.5" || overrides["madhab"] != "hanafi" {
+		t.Errorf("unexpected overrides %v", overrides)
+	}
+}
+
+func TestArgValue(t *testing.T) {
+	tests := []struct{ value, typ, expected string }{
+		{"1.5", "number", "1.5"},
+		{"true", "boolean", "true"},
+		{"2", "string or integer", "2"},
+		{"isna", "string or integer", `"isna"`},
+		{"42", "string", `"42"`},
+		{"north", "number", `"north"`},
+	}
+	for _, tt := range tests {
+		if got := string(argValue(tt.value, tt.typ)); got != tt.expected {
+			t.Errorf("argValue(%q, %q) = %s, expected %s", tt.value, tt.typ, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"salah-cli/pkg/salah"
+	"strconv"
+)
+
+// CurrentVersion is the config schema version written by this release.
+// Configs without a version are version 1.
+const CurrentVersion = 2
+
+// migration upgrades a raw config by one version
+type migration struct {
+	description string
+	apply       func(raw map[string]json.RawMessage) error
+}
+
+// migrations[i] upgrades a config from version i+1 to version i+2
+var migrations = []migration{
+	{"store method, madhab and high_latitude_rule by name", migrateEnumNames},
+}
+
+// configVersion returns the version of a raw config
+func configVersion(raw map[string]json.RawMessage) (int, error) {
+	value, ok := raw["version"]
+	if !ok {
+		return 1, nil
+	}
+	var version int
+	if err := json.Unmarshal(value, &version); err != nil || version < 1 {
+		return 0, fmt.Errorf("version must be a positive integer (got %s)", value)
+	}
+	if version > CurrentVersion {
+		return 0, fmt.Errorf("config version %d is newer than this release supports (%d), upgrade salah-cli", version, CurrentVersion)
+	}
+	return version, nil
+}
+
+// migrateRaw upgrades a raw config in place to CurrentVersion, returning the version it
+// started at and a description of each migration applied
+func migrateRaw(raw map[string]json.RawMessage) (int, []string, error) {
+	from, err := configVersion(raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	var applied []string
+	for version := from; version < CurrentVersion; version++ {
+		m := migrations[version-1]
+		if err := m.apply(raw); err != nil {
+			return from, applied, fmt.Errorf("failed to migrate config from version %d: %w", version, err)
+		}
+		applied = append(applied, fmt.Sprintf("%d → %d: %s", version, version+1, m.description))
+	}
+	if from < CurrentVersion {
+		raw["version"] = json.RawMessage(strconv.Itoa(CurrentVersion))
+	}
+	return from, applied, nil
+}
+
+// migrateEnumNames replaces known numeric method, madhab and high_latitude_rule values with their names
+func migrateEnumNames(raw map[string]json.RawMessage) error {
+	rename := func(key string, names func(int) (string, bool)) {
+		var n int
+		if err := json.Unmarshal(raw[key], &n); err != nil {
+			return // absent, already a name or invalid, which validation reports
+		}
+		if name, ok := names(n); ok {
+			raw[key], _ = json.Marshal(name)
+		}
+	}
+	rename("method", func(n int) (string, bool) { name, ok := MethodNames[salah.Method(n)]; return name, ok })
+	rename("madhab", func(n int) (string, bool) { name, ok := MadhabNames[salah.Madhab(n)]; return name, ok })
+	rename("high_latitude_rule", func(n int) (string, bool) {
+		name, ok := HighLatitudeRuleNames[salah.HighLatitudeRule(n)]
+		return name, ok
+	})
+	return nil
+}
+
+// MigrationResult describes the upgrade of a config file to CurrentVersion
+type MigrationResult struct {
+	FromVersion int
+	Applied     []string
+	Before      []byte
+	After       []byte
+	// BackupPath is where the original file was copied, empty for a dry run or when nothing changed
+	BackupPath string
+}
+
+// Migrate upgrades the config file at path to CurrentVersion. The original file is kept
+// as path.vN.bak. With dryRun the file is left untouched and the result shows the changes.
+func Migrate(path string, dryRun bool) (*MigrationResult, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open config file %s: %w", path, err)
+	}
+	jsonData, _, problem := decodeDocument(before, format)
+	if problem != nil {
+		return nil, fmt.Errorf("invalid config in %s: %s", problem.At(path), problem.Message)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &raw); err != nil {
+		return nil, fmt.Errorf("invalid config in %s: %w", path, err)
+	}
+	from, applied, err := migrateRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := &MigrationResult{FromVersion: from, Applied: applied, Before: before, After: before}
+	if len(applied) == 0 {
+		return result, nil
+	}
+
+	cfg, problems := CheckDataAs(before, format)
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return nil, fmt.Errorf("invalid config in %s: %s", p.At(path), p.Message)
+		}
+	}
+	if result.After, err = encodeConfigAs(cfg, format); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	result.BackupPath = fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(result.BackupPath, before, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write backup %s: %w", result.BackupPath, err)
+	}
+	if err := SaveConfig(cfg, path); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// encodeConfig returns the config as written by SaveConfig to a JSON file
+func encodeConfig(config *Config) ([]byte, error) {
+	c := *config
+	c.Version = CurrentVersion
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(&c); err != nil {
+		return nil, fmt.Errorf("failed to encode config to JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
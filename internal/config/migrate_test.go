@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+const v1Config = `{
+  "latitude": 51.5,
+  "longitude": -0.12,
+  "method": 2,
+  "madhab": 1,
+  "high_latitude_rule": 9
+}
+`
+
+func TestCheckData_MigratesOldVersions(t *testing.T) {
+	cfg, problems := CheckData([]byte(`{"latitude": 1, "longitude": 2, "method": 7}`))
+	if len(problems) != 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+	if cfg.Version != CurrentVersion || *cfg.Method != 7 {
+		t.Errorf("expected version %d with method 7, got %+v", CurrentVersion, cfg)
+	}
+}
+
+func TestCheckData_UnsupportedVersion(t *testing.T) {
+	for _, input := range []string{`{"version": 99}`, `{"version": "two"}`, `{"version": 0}`} {
+		_, problems := CheckData([]byte(input))
+		if len(problems) != 1 || problems[0].Field != "version" || problems[0].Line != 1 {
+			t.Errorf("expected one version problem for %s, got %v", input, problems)
+		}
+	}
+}
+
+func TestMigrate_InvalidConfig(t *testing.T) {
+	path := writeConfigFile(t, "config.json", v1Config)
+	result, err := Migrate(path, true)
+	if err == nil {
+		t.Fatalf("expected error for invalid high_latitude_rule, got %+v", result)
+	}
+	if !strings.Contains(err.Error(), "high_latitude_rule") {
+		t.Errorf("expected high_latitude_rule error, got %v", err)
+	}
+}
+
+func TestMigrate_DryRun(t *testing.T) {
+	content := strings.Replace(v1Config, `"high_latitude_rule": 9`, `"high_latitude_rule": 2`, 1)
+	path := writeConfigFile(t, "config.json", content)
+
+	result, err := Migrate(path, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.FromVersion != 1 || len(result.Applied) != 1 || result.BackupPath != "" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	for _, want := range []string{`"version": 2`, `"method": "egyptian"`, `"madhab": "hanafi"`, `"high_latitude_rule": "seventh_of_the_night"`} {
+		if !bytes.Contains(result.After, []byte(want)) {
+			t.Errorf("expected migrated config to contain %s, got %s", want, result.After)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != content {
+		t.Errorf("expected dry run to leave the file untouched, got %s", data)
+	}
+}
+
+func TestMigrate_WritesBackup(t *testing.T) {
+	content := strings.Replace(v1Config, `"high_latitude_rule": 9`, `"high_latitude_rule": 2`, 1)
+	path := writeConfigFile(t, "config.json", content)
+
+	result, err := Migrate(path, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.BackupPath != path+".v1.bak" {
+		t.Errorf("unexpected backup path %s", result.BackupPath)
+	}
+	backup, _ := os.ReadFile(result.BackupPath)
+	if string(backup) != content {
+		t.Errorf("expected backup of original config, got %s", backup)
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, result.After) {
+		t.Errorf("expected migrated config to be written, got %s", data)
+	}
+
+	// migrating again is a no-op
+	result, err = Migrate(path, false)
+	if err != nil || len(result.Applied) != 0 {
+		t.Errorf("expected no migrations, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"salah-cli/pkg/salah"
+	"strconv"
+	"strings"
+)
+
+// Method is a calculation method, written to the config by name and read from a name or number
+type Method int
+
+// Madhab is the juristic method used for Asr, written to the config by name and read from a name or number
+type Madhab int
+
+// HighLatitudeRule is a high latitude rule, written to the config by name and read from a name or number
+type HighLatitudeRule int
+
+// MethodNames maps each calculation method to its config name
+var MethodNames = map[salah.Method]string{
+	salah.MethodOther:                 "other",
+	salah.MethodMuslimWorldLeague:     "muslim_world_league",
+	salah.MethodEgyptian:              "egyptian",
+	salah.MethodKarachi:               "karachi",
+	salah.MethodUmmAlQura:             "umm_al_qura",
+	salah.MethodDubai:                 "dubai",
+	salah.MethodMoonsightingCommittee: "moonsighting_committee",
+	salah.MethodNorthAmerica:          "north_america",
+	salah.MethodKuwait:                "kuwait",
+	salah.MethodQatar:                 "qatar",
+	salah.MethodSingapore:             "singapore",
+	salah.MethodUOIF:                  "uoif",
+}
+
+// methodAliases are alternative names accepted for calculation methods
+var methodAliases = map[string]salah.Method{
+	"mwl":          salah.MethodMuslimWorldLeague,
+	"isna":         salah.MethodNorthAmerica,
+	"moonsighting": salah.MethodMoonsightingCommittee,
+	"makkah":       salah.MethodUmmAlQura,
+}
+
+// MadhabNames maps each madhab to its config name
+var MadhabNames = map[salah.Madhab]string{
+	salah.MadhabShafi:  "shafi",
+	salah.MadhabHanafi: "hanafi",
+}
+
+// HighLatitudeRuleNames maps each high latitude rule to its config name
+var HighLatitudeRuleNames = map[salah.HighLatitudeRule]string{}
+
+func init() {
+	for _, r := range salah.HighLatitudeRules {
+		HighLatitudeRuleNames[r] = strings.ReplaceAll(r.String(), " ", "_")
+	}
+}
+
+// normaliseName lowercases a name and accepts spaces and dashes in place of underscores
+func normaliseName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// parseName looks up a name or number in names, returning the matching value
+func parseName[T ~int](kind, s string, names map[T]string, aliases map[string]T) (T, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return T(n), nil
+	}
+	s = normaliseName(s)
+	for v, name := range names {
+		if name == s {
+			return v, nil
+		}
+	}
+	if v, ok := aliases[s]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("unknown %s '%s'. Allowed: %s", kind, s, strings.Join(sortedNames(names), ", "))
+}
+
+// sortedNames returns the names in enum order
+func sortedNames[T ~int](names map[T]string) []string {
+	out := make([]string, 0, len(names))
+	for v := T(0); int(v) < len(names); v++ {
+		out = append(out, names[v])
+	}
+	return out
+}
+
+// unmarshalName decodes a JSON number or name
+func unmarshalName[T ~int](kind string, b []byte, names map[T]string, aliases map[string]T) (T, error) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var n int
+		if err := json.Unmarshal(b, &n); err != nil {
+			return 0, fmt.Errorf("%s must be a name or number (got %s)", kind, b)
+		}
+		return T(n), nil
+	}
+	return parseName(kind, s, names, aliases)
+}
+
+// marshalName encodes a known value by name, and any other value as a number
+func marshalName[T ~int](v T, names map[T]string) ([]byte, error) {
+	if name, ok := names[v]; ok {
+		return json.Marshal(name)
+	}
+	return json.Marshal(int(v))
+}
+
+// ParseMethod parses a calculation method name, alias or number
+func ParseMethod(s string) (Method, error) {
+	m, err := parseName("method", s, MethodNames, methodAliases)
+	return Method(m), err
+}
+
+// ParseMadhab parses a madhab name or number
+func ParseMadhab(s string) (Madhab, error) {
+	m, err := parseName[salah.Madhab]("madhab", s, MadhabNames, nil)
+	return Madhab(m), err
+}
+
+// ParseHighLatitudeRule parses a high latitude rule name or number
+func ParseHighLatitudeRule(s string) (HighLatitudeRule, error) {
+	r, err := parseName[salah.HighLatitudeRule]("high latitude rule", s, HighLatitudeRuleNames, nil)
+	return HighLatitudeRule(r), err
+}
+
+func (m Method) MarshalJSON() ([]byte, error) { return marshalName(salah.Method(m), MethodNames) }
+
+func (m *Method) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalName("method", b, MethodNames, methodAliases)
+	*m = Method(v)
+	return err
+}
+
+func (m Madhab) MarshalJSON() ([]byte, error) { return marshalName(salah.Madhab(m), MadhabNames) }
+
+func (m *Madhab) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalName[salah.Madhab]("madhab", b, MadhabNames, nil)
+	*m = Madhab(v)
+	return err
+}
+
+func (r HighLatitudeRule) MarshalJSON() ([]byte, error) {
+	return marshalName(salah.HighLatitudeRule(r), HighLatitudeRuleNames)
+}
+
+func (r *HighLatitudeRule) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalName[salah.HighLatitudeRule]("high latitude rule", b, HighLatitudeRuleNames, nil)
+	*r = HighLatitudeRule(v)
+	return err
+}
+
+// validateName checks that v is one of the known values
+func validateName[T ~int](field string, v *T, names map[T]string) error {
+	if v == nil {
+		return nil
+	}
+	if _, ok := names[*v]; !ok {
+		return fmt.Errorf("invalid %s %d. Allowed: %s (or 0-%d)", field, int(*v), strings.Join(sortedNames(names), ", "), len(names)-1)
+	}
+	return nil
+}
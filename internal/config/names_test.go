@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"salah-cli/pkg/salah"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshal_NamesAndNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		method   salah.Method
+		madhab   salah.Madhab
+		highLat  salah.HighLatitudeRule
+		hasError bool
+	}{
+		{`{"method": 2, "madhab": 1, "high_latitude_rule": 2}`, salah.MethodEgyptian, salah.MadhabHanafi, salah.HighLatitudeSeventhOfTheNight, false},
+		{`{"method": "isna", "madhab": "hanafi", "high_latitude_rule": "seventh_of_the_night"}`, salah.MethodNorthAmerica, salah.MadhabHanafi, salah.HighLatitudeSeventhOfTheNight, false},
+		{`{"method": "Muslim World League", "madhab": "SHAFI", "high_latitude_rule": "twilight-angle"}`, salah.MethodMuslimWorldLeague, salah.MadhabShafi, salah.HighLatitudeTwilightAngle, false},
+		{`{"method": "7", "madhab": "0", "high_latitude_rule": "auto"}`, salah.MethodNorthAmerica, salah.MadhabShafi, salah.HighLatitudeAuto, false},
+		{`{"method": "fastest"}`, 0, 0, 0, true},
+		{`{"madhab": true}`, 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		var cfg Config
+		err := json.Unmarshal([]byte(tt.input), &cfg)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("expected error for %s, got nil", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", tt.input, err)
+		}
+		if salah.Method(*cfg.Method) != tt.method || salah.Madhab(*cfg.Madhab) != tt.madhab || salah.HighLatitudeRule(*cfg.HighLatitudeRule) != tt.highLat {
+			t.Errorf("unexpected values for %s: %v %v %v", tt.input, *cfg.Method, *cfg.Madhab, *cfg.HighLatitudeRule)
+		}
+	}
+}
+
+func TestValidate_OutOfRange(t *testing.T) {
+	method := Method(42)
+	madhab := Madhab(2)
+	highLat := HighLatitudeRule(-1)
+	tests := []struct {
+		cfg      Config
+		contains string
+	}{
+		{Config{Method: &method}, "invalid method 42"},
+		{Config{Madhab: &madhab}, "invalid madhab 2"},
+		{Config{HighLatitudeRule: &highLat}, "invalid high_latitude_rule -1"},
+	}
+	for _, tt := range tests {
+		err := tt.cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.contains) {
+			t.Errorf("expected error containing %q, got %v", tt.contains, err)
+		}
+	}
+}
+
+func TestSaveConfig_RoundTripsNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"latitude": 51.5, "longitude": -0.12, "method": 7, "madhab": "hanafi", "high_latitude_rule": "auto"}`), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := loadFromFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := SaveConfig(cfg, path); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	for _, want := range []string{`"method": "north_america"`, `"madhab": "hanafi"`, `"high_latitude_rule": "auto"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected saved config to contain %s, got %s", want, data)
+		}
+	}
+
+	reloaded, err := loadFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to reload config: %v", err)
+	}
+	if *reloaded.Method != *cfg.Method || *reloaded.Madhab != *cfg.Madhab || *reloaded.HighLatitudeRule != *cfg.HighLatitudeRule {
+		t.Errorf("expected round trip to preserve values, got %+v", reloaded)
+	}
+}
+
+func TestMarshal_UnknownValueAsNumber(t *testing.T) {
+	data, err := json.Marshal(Method(42))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "42" {
+		t.Errorf("expected 42, got %s", data)
+	}
+}
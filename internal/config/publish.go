@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// MQTT is the broker prayer events are published to by 'salah-cli publish'
+type MQTT struct {
+	Broker   string `json:"broker" doc:"Broker address: host:port, tcp://host:port, or mqtts://host:port for TLS" example:"localhost:1883"`
+	Topic    string `json:"topic,omitempty" doc:"Topic prefix: events go to <topic>/event and the retained next prayer to <topic>/next" default:"salah-cli" example:"home/prayer"`
+	ClientID string `json:"client_id,omitempty" doc:"Client identifier sent to the broker" default:"salah-cli" example:"salah-cli-office"`
+	Username string `json:"username,omitempty" doc:"User name for the broker" example:"homeassistant"`
+	Password string `json:"password,omitempty" doc:"Password for the broker, along with username" example:"secret" secret:"true"`
+}
+
+// DefaultMQTTTopic prefixes the topics of an MQTT config without a topic
+const DefaultMQTTTopic = AppName
+
+// mqttSchemes are the URL schemes accepted for the broker, besides a bare host:port
+var mqttSchemes = []string{"tcp", "mqtt", "ssl", "tls", "mqtts"}
+
+// Address returns the broker's host:port and whether the connection uses TLS
+func (m *MQTT) Address() (string, bool, error) {
+	if !strings.Contains(m.Broker, "://") {
+		if !strings.Contains(m.Broker, ":") {
+			return "", false, fmt.Errorf("invalid mqtt.broker '%s', expected host:port", m.Broker)
+		}
+		return m.Broker, false, nil
+	}
+	u, err := url.Parse(m.Broker)
+	if err != nil || u.Host == "" || !slices.Contains(mqttSchemes, u.Scheme) {
+		return "", false, fmt.Errorf("invalid mqtt.broker '%s', expected host:port or a URL with scheme %s", m.Broker, strings.Join(mqttSchemes, ", "))
+	}
+	host := u.Host
+	secure := u.Scheme == "ssl" || u.Scheme == "tls" || u.Scheme == "mqtts"
+	if u.Port() == "" {
+		host += map[bool]string{false: ":1883", true: ":8883"}[secure]
+	}
+	return host, secure, nil
+}
+
+// Webhook is a URL prayer events are posted to by 'salah-cli publish'
+type Webhook struct {
+	URL    string `json:"url" doc:"URL prayer events are posted to as JSON" example:"https://ha.local/api/webhook/prayer"`
+	Secret string `json:"secret,omitempty" doc:"Key signing the body with HMAC-SHA256, sent in the X-Salah-Signature header" example:"s3cret" secret:"true"`
+}
+
+// publishProblems checks the MQTT broker and the webhooks
+func (c *Config) publishProblems(add func(severity Severity, field string, err error)) {
+	if c.MQTT != nil {
+		if _, _, err := c.MQTT.Address(); err != nil {
+			add(SeverityError, "mqtt.broker", err)
+		}
+		if strings.ContainsAny(c.MQTT.Topic, "#+") {
+			add(SeverityError, "mqtt.topic", fmt.Errorf("mqtt.topic '%s' cannot contain the wildcards # or +", c.MQTT.Topic))
+		}
+		if c.MQTT.Password != "" && c.MQTT.Username == "" {
+			add(SeverityError, "mqtt.password", fmt.Errorf("mqtt.password requires mqtt.username, MQTT brokers don't accept a password alone"))
+		}
+	}
+	for i, w := range c.Webhooks {
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(SeverityError, fmt.Sprintf("webhooks[%d].url", i), fmt.Errorf("invalid webhooks[%d].url '%s', expected an http or https URL", i, w.URL))
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMQTT_Address(t *testing.T) {
+	tests := []struct {
+		broker string
+		addr   string
+		secure bool
+	}{
+		{"localhost:1883", "localhost:1883", false},
+		{"tcp://broker.local", "broker.local:1883", false},
+		{"mqtt://broker.local:1884", "broker.local:1884", false},
+		{"mqtts://broker.local", "broker.local:8883", true},
+		{"ssl://10.0.0.2:9000", "10.0.0.2:9000", true},
+	}
+	for _, tt := range tests {
+		addr, secure, err := (&MQTT{Broker: tt.broker}).Address()
+		if err != nil || addr != tt.addr || secure != tt.secure {
+			t.Errorf("Address(%q) = %s %v %v; want %s %v", tt.broker, addr, secure, err, tt.addr, tt.secure)
+		}
+	}
+	for _, broker := range []string{"", "localhost", "http://broker.local", "tcp://"} {
+		if _, _, err := (&MQTT{Broker: broker}).Address(); err == nil {
+			t.Errorf("Address(%q): expected an error", broker)
+		}
+	}
+}
+
+func TestCheckData_Publish(t *testing.T) {
+	_, problems := CheckData([]byte(`{"latitude": 1, "longitude": 2,
+  "mqtt": {"broker": "localhost", "topic": "home/#", "password": "secret"},
+  "webhooks": [{"url": "https://ha.local/api/webhook/prayer"}, {"url": "ftp://ha.local"}]
+}`))
+	var fields []string
+	for _, p := range problems {
+		fields = append(fields, p.Field)
+	}
+	want := "mqtt.broker mqtt.topic mqtt.password webhooks[1].url"
+	if strings.Join(fields, " ") != want {
+		t.Errorf("expected problems in %s, got %v", want, problems)
+	}
+}
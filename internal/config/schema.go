@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// schemaKey is the key referencing a JSON Schema, tolerated in config files
+const schemaKey = "$schema"
+
+// SchemaFileName is the file the schema is written to by 'salah-cli config schema --write'
+const SchemaFileName = "config.schema.json"
+
+// jsonSchema is the subset of JSON Schema (draft 2020-12) used to describe the config
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Const                any                    `json:"const,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Examples             []any                  `json:"examples,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Not                  *jsonSchema            `json:"not,omitempty"`
+	If                   *jsonSchema            `json:"if,omitempty"`
+	Then                 *jsonSchema            `json:"then,omitempty"`
+}
+
+// schemaRanges are the bounds of numeric options that are errors when exceeded
+var schemaRanges = map[string][2]float64{
+	"version":   {1, CurrentVersion},
+	"latitude":  {-90, 90},
+	"longitude": {-180, 180},
+}
+
+// JSONSchema returns a JSON Schema for the config file, generated from the config reference
+func JSONSchema() ([]byte, error) {
+	schema := objectSchema(Docs())
+	for _, d := range Docs() {
+		if d.Required {
+			schema.Required = append(schema.Required, d.Key)
+		}
+	}
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = "salah-cli configuration"
+	schema.Description = "See 'salah-cli config-docs' for every option"
+	schema.Properties[schemaKey] = &jsonSchema{Type: "string", Description: "JSON Schema of this file"}
+
+	// isha_angle and isha_interval are mutually exclusive, and the timetable engine needs a file
+	schema.Not = &jsonSchema{Required: []string{"isha_angle", "isha_interval"}}
+	schema.If = &jsonSchema{
+		Properties: map[string]*jsonSchema{"engine": {Const: "timetable"}},
+		Required:   []string{"engine"},
+	}
+	schema.Then = &jsonSchema{Required: []string{"timetable_path"}}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(schema); err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// objectSchema describes an object with the given keys and no others. Keys of nested objects
+// are never required: missing adjustments are 0.
+func objectSchema(docs []FieldDoc) *jsonSchema {
+	closed := false
+	schema := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}, AdditionalProperties: &closed}
+	for _, d := range docs {
+		schema.Properties[d.Key] = fieldSchema(d)
+	}
+	return schema
+}
+
+func fieldSchema(d FieldDoc) *jsonSchema {
+	if len(d.Keys) > 0 {
+		schema := objectSchema(d.Keys)
+		schema.Description = d.Description
+		return schema
+	}
+	if len(d.Items) > 0 {
+		// unlike nested objects, array items have required keys: a hook needs an event and a command
+		items := objectSchema(d.Items)
+		for _, k := range d.Items {
+			if k.Required {
+				items.Required = append(items.Required, k.Key)
+			}
+		}
+		return &jsonSchema{Description: d.Description, Type: "array", Items: items}
+	}
+
+	schema := &jsonSchema{Description: d.Description, Type: d.Type}
+	if types := strings.Split(d.Type, " or "); len(types) > 1 {
+		schema.Type = types
+	}
+	if r, ok := schemaRanges[d.Key]; ok {
+		schema.Minimum, schema.Maximum = &r[0], &r[1]
+	}
+	schema.Enum = schemaEnum(d)
+	if d.Default != "" && !strings.Contains(d.Default, " ") {
+		schema.Default = d.Default
+		var v any
+		if d.Type != "string" && !strings.HasPrefix(d.Type, "string ") && json.Unmarshal([]byte(d.Default), &v) == nil {
+			schema.Default = v
+		}
+	}
+	var example any
+	if d.Example != "" && json.Unmarshal([]byte(d.Example), &example) == nil {
+		schema.Examples = []any{example}
+	}
+	return schema
+}
+
+// schemaEnum lists the allowed values of an option with a fixed set of values. Options given
+// by name also accept their aliases and numbers.
+func schemaEnum(d FieldDoc) []any {
+	switch d.Key {
+	case "method":
+		return nameEnum(MethodNames, methodAliases)
+	case "madhab":
+		return nameEnum(MadhabNames, nil)
+	case "high_latitude_rule":
+		return nameEnum(HighLatitudeRuleNames, nil)
+	}
+	var enum []any
+	for _, c := range d.Choices {
+		enum = append(enum, c.Value)
+	}
+	if d.Key == "highlight_colour" && len(enum) > 0 {
+		enum = append(enum, "") // only checked when highlighting is enabled
+	}
+	return enum
+}
+
+func nameEnum[T ~int](names map[T]string, aliases map[string]T) []any {
+	var enum []any
+	for _, name := range sortedNames(names) {
+		enum = append(enum, name)
+	}
+	sortedAliases := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		sortedAliases = append(sortedAliases, alias)
+	}
+	slices.Sort(sortedAliases)
+	for _, alias := range sortedAliases {
+		enum = append(enum, alias)
+	}
+	for v := range len(names) {
+		enum = append(enum, v)
+	}
+	return enum
+}
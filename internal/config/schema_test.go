@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var schema jsonSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	if !slices.Equal(schema.Required, []string{"latitude", "longitude"}) {
+		t.Errorf("expected latitude and longitude to be required, got %v", schema.Required)
+	}
+	if schema.AdditionalProperties == nil || *schema.AdditionalProperties {
+		t.Error("expected unknown keys to be rejected")
+	}
+	if _, ok := schema.Properties["$schema"]; !ok {
+		t.Error("expected $schema to be allowed")
+	}
+	if !slices.Equal(schema.Not.Required, []string{"isha_angle", "isha_interval"}) {
+		t.Errorf("expected isha_angle and isha_interval to be exclusive, got %+v", schema.Not)
+	}
+
+	lat := schema.Properties["latitude"]
+	if lat.Type != "number" || *lat.Minimum != -90 || *lat.Maximum != 90 {
+		t.Errorf("unexpected latitude schema %+v", lat)
+	}
+
+	method := schema.Properties["method"]
+	for _, want := range []any{"muslim_world_league", "isna", float64(0), float64(len(MethodNames) - 1)} {
+		if !slices.Contains(method.Enum, want) {
+			t.Errorf("expected method enum to contain %v, got %v", want, method.Enum)
+		}
+	}
+	if method.Default != "moonsighting_committee" {
+		t.Errorf("unexpected method default %v", method.Default)
+	}
+	if madhab := schema.Properties["madhab"]; !slices.Equal(madhab.Enum, []any{"shafi", "hanafi", float64(0), float64(1)}) {
+		t.Errorf("unexpected madhab enum %v", madhab.Enum)
+	}
+
+	adjustments := schema.Properties["adjustments"]
+	if adjustments.Properties["FajrAdj"].Type != "integer" || len(adjustments.Required) != 0 {
+		t.Errorf("unexpected adjustments schema %+v", adjustments)
+	}
+	if v := schema.Properties["version"]; v.Default != float64(1) || *v.Maximum != CurrentVersion {
+		t.Errorf("unexpected version schema %+v", v)
+	}
+	hooks := schema.Properties["hooks"]
+	if hooks.Type != "array" || !slices.Equal(hooks.Items.Required, []string{"event", "command"}) || hooks.Items.Properties["args"].Type != "array" {
+		t.Errorf("unexpected hooks schema %+v", hooks)
+	}
+}
+
+func TestCheckData_SchemaKey(t *testing.T) {
+	cfg, problems := CheckData([]byte(`{"$schema": "./config.schema.json", "latitude": 1, "longitude": 2}`))
+	if len(problems) != 0 {
+		t.Fatalf("expected $schema to be tolerated, got %v", problems)
+	}
+	if cfg.Schema != "./config.schema.json" {
+		t.Errorf("expected schema reference to be kept, got %q", cfg.Schema)
+	}
+	for _, d := range Docs() {
+		if d.Key == "$schema" {
+			t.Error("expected $schema to be left out of the reference")
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"salah-cli/internal/util"
+	"salah-cli/pkg/salah"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// ErrSetupCancelled is returned by SetupConfig when the user declines to save
+var ErrSetupCancelled = errors.New("setup cancelled, config left unchanged")
+
+// setupAnswers holds the values entered in the setup form. The fields are exported so the
+// preview notices when they change.
+type setupAnswers struct {
+	Latitude, Longitude   string
+	Method                Method
+	Madhab                Madhab
+	HighLatitudeRule      HighLatitudeRule
+	FajrAngle, IshaAngle  string
+	IshaInterval          string
+	Adjustments           [6]string
+	MethodAdjustments     [6]string
+	IshaEnd               string
+	Engine, TimetablePath string
+	EnableCountdown       bool
+	EnableHighlighting    bool
+	HighlightColour       string
+	Format                Format
+	// the hooks and integrations are kept as they are, they are edited in the config file
+	Hooks    []Hook
+	MQTT     *MQTT
+	Webhooks []Webhook
+}
+
+// newSetupAnswers pre-fills the setup form from an existing config
+func newSetupAnswers(c *Config, format Format) *setupAnswers {
+	a := &setupAnswers{
+		Method:             Method(salah.DefaultMethod),
+		Madhab:             Madhab(salah.MadhabShafi),
+		HighLatitudeRule:   HighLatitudeRule(salah.HighLatitudeMiddleOfTheNight),
+		IshaEnd:            c.IshaEnd,
+		Engine:             c.Engine,
+		TimetablePath:      c.TimetablePath,
+		EnableCountdown:    c.EnableCountdown,
+		EnableHighlighting: c.EnableHighlighting,
+		HighlightColour:    c.HighlightColour,
+		Hooks:              c.Hooks,
+		MQTT:               c.MQTT,
+		Webhooks:           c.Webhooks,
+		Format:             format,
+	}
+	if c.Latitude != 0 || c.Longitude != 0 {
+		a.Latitude, a.Longitude = formatFloat(&c.Latitude), formatFloat(&c.Longitude)
+	}
+	if c.Method != nil {
+		a.Method = *c.Method
+	}
+	if c.Madhab != nil {
+		a.Madhab = *c.Madhab
+	}
+	if c.HighLatitudeRule != nil {
+		a.HighLatitudeRule = *c.HighLatitudeRule
+	}
+	a.FajrAngle, a.IshaAngle = formatFloat(c.FajrAngle), formatFloat(c.IshaAngle)
+	if c.IshaInterval != nil {
+		a.IshaInterval = strconv.Itoa(*c.IshaInterval)
+	}
+	for i, v := range c.Adjustments.values() {
+		if v != 0 {
+			a.Adjustments[i] = strconv.Itoa(v)
+		}
+	}
+	for i, v := range c.MethodAdjustments.values() {
+		a.MethodAdjustments[i] = strconv.Itoa(v)
+	}
+	if a.IshaEnd == "" {
+		a.IshaEnd = IshaEnds[0]
+	}
+	if a.Engine == "" {
+		a.Engine = Engines[0]
+	}
+	if a.HighlightColour == "" {
+		a.HighlightColour = util.DefaultColour
+	}
+	return a
+}
+
+// config builds the config from the answers, returning an error if it is invalid
+func (a *setupAnswers) config() (*Config, error) {
+	c := &Config{
+		Version:            CurrentVersion,
+		EnableCountdown:    a.EnableCountdown,
+		EnableHighlighting: a.EnableHighlighting,
+		Hooks:              a.Hooks,
+		MQTT:               a.MQTT,
+		Webhooks:           a.Webhooks,
+	}
+	var err error
+	if c.Latitude, err = parseRequiredFloat("latitude", a.Latitude); err != nil {
+		return nil, err
+	}
+	if c.Longitude, err = parseRequiredFloat("longitude", a.Longitude); err != nil {
+		return nil, err
+	}
+	method, madhab, rule := a.Method, a.Madhab, a.HighLatitudeRule
+	c.Method, c.Madhab, c.HighLatitudeRule = &method, &madhab, &rule
+	if c.FajrAngle, err = parseOptionalFloat("Fajr angle", a.FajrAngle); err != nil {
+		return nil, err
+	}
+	if c.IshaAngle, err = parseOptionalFloat("Isha angle", a.IshaAngle); err != nil {
+		return nil, err
+	}
+	if c.IshaInterval, err = parseOptionalInt("Isha interval", a.IshaInterval); err != nil {
+		return nil, err
+	}
+	if c.Adjustments, err = parseAdjustments(a.Adjustments); err != nil {
+		return nil, err
+	}
+	if c.MethodAdjustments, err = parseAdjustments(a.MethodAdjustments); err != nil {
+		return nil, err
+	}
+	if a.IshaEnd != IshaEnds[0] {
+		c.IshaEnd = a.IshaEnd
+	}
+	if a.Engine != Engines[0] {
+		c.Engine = a.Engine
+	}
+	if c.Engine == "timetable" {
+		c.TimetablePath = strings.TrimSpace(a.TimetablePath)
+	}
+	if c.EnableHighlighting {
+		c.HighlightColour = a.HighlightColour
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetupConfig asks for every option interactively, starting from existing, and returns the new
+// config with the file format chosen. existingPath is the config file that will be replaced, empty
+// if there is none; the user confirms before it is overwritten. preview describes the prayer times
+// of a config, shown before saving.
+func SetupConfig(existing *Config, existingPath string, preview func(*Config) string) (*Config, Format, error) {
+	format := FormatJSON
+	if existingPath != "" {
+		if f, err := FormatOf(existingPath); err == nil {
+			format = f
+		}
+	}
+	a := newSetupAnswers(existing, format)
+	save := true
+
+	// no placeholders, which crash the text input when the form is narrow
+	adjustmentFields := func(values *[6]string) []huh.Field {
+		fields := make([]huh.Field, len(adjustmentKeys))
+		for i, key := range adjustmentKeys {
+			fields[i] = huh.NewInput().
+				Title(strings.TrimSuffix(key, "Adj") + " (minutes)").
+				Value(&values[i]).
+				Validate(func(s string) error {
+					_, err := parseOptionalInt(key, s)
+					return err
+				})
+		}
+		return fields
+	}
+
+	confirmTitle := "Save this config?"
+	if existingPath != "" {
+		confirmTitle = fmt.Sprintf("Overwrite %s?", existingPath)
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Enter your latitude:").
+				Value(&a.Latitude).
+				Validate(func(s string) error {
+					lat, err := parseRequiredFloat("latitude", s)
+					if err != nil {
+						return err
+					}
+					return validateLatitude(lat)
+				}),
+			huh.NewInput().
+				Title("Enter your longitude:").
+				Value(&a.Longitude).
+				Validate(func(s string) error {
+					lon, err := parseRequiredFloat("longitude", s)
+					if err != nil {
+						return err
+					}
+					return validateLongitude(lon)
+				}),
+		).Title("Location"),
+
+		huh.NewGroup(
+			huh.NewSelect[Method]().
+				Title("Choose your calculation method").
+				Options(methodOptions()...).
+				Value(&a.Method),
+			huh.NewSelect[Madhab]().
+				Title("Choose your Madhab").
+				Options(
+					huh.NewOption("Shafi/Hanbali/Maliki", Madhab(salah.MadhabShafi)),
+					huh.NewOption("Hanafi", Madhab(salah.MadhabHanafi)),
+				).
+				Value(&a.Madhab),
+			huh.NewSelect[HighLatitudeRule]().
+				Title("Choose the high latitude rule").
+				Description("Bounds Fajr and Isha where twilight lasts long").
+				Options(highLatitudeRuleOptions()...).
+				Value(&a.HighLatitudeRule),
+			huh.NewSelect[string]().
+				Title("When does Isha end?").
+				Options(
+					huh.NewOption("At Fajr", "fajr"),
+					huh.NewOption("At Islamic midnight", "midnight"),
+				).
+				Value(&a.IshaEnd),
+		).Title("Calculation"),
+
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Fajr angle").
+				Value(&a.FajrAngle).
+				Validate(func(s string) error {
+					_, err := parseOptionalFloat("Fajr angle", s)
+					return err
+				}),
+			huh.NewInput().
+				Title("Isha angle").
+				Value(&a.IshaAngle).
+				Validate(func(s string) error {
+					_, err := parseOptionalFloat("Isha angle", s)
+					return err
+				}),
+			huh.NewInput().
+				Title("Isha interval after Maghrib (minutes)").
+				Value(&a.IshaInterval).
+				Validate(func(s string) error {
+					if _, err := parseOptionalInt("Isha interval", s); err != nil {
+						return err
+					}
+					if strings.TrimSpace(s) != "" && strings.TrimSpace(a.IshaAngle) != "" {
+						return fmt.Errorf("clear the Isha angle to use an interval")
+					}
+					return nil
+				}),
+		).Title("Custom angles").Description("Leave empty to use the method's values"),
+
+		huh.NewGroup(adjustmentFields(&a.Adjustments)...).
+			Title("Adjustments").Description("Minutes added to each prayer time, leave empty for none"),
+
+		huh.NewGroup(adjustmentFields(&a.MethodAdjustments)...).
+			Title("Method adjustments").Description("Replace the method's own adjustments, leave empty to keep them"),
+
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Calculation engine").
+				Options(huh.NewOptions(Engines...)...).
+				Value(&a.Engine),
+		).Title("Engine"),
+
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Timetable CSV").
+				Description("date,fajr,sunrise,dhuhr,asr,maghrib,isha").
+				Value(&a.TimetablePath).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return fmt.Errorf("value can't be empty")
+					}
+					return nil
+				}),
+		).WithHideFunc(func() bool { return a.Engine != "timetable" }),
+
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Show a countdown to the next prayer?").
+				Value(&a.EnableCountdown),
+			huh.NewConfirm().
+				Title("Highlight the current prayer?").
+				Value(&a.EnableHighlighting),
+		).Title("Display"),
+
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Highlight colour").
+				Options(huh.NewOptions(colourNames()...)...).
+				Value(&a.HighlightColour),
+		).WithHideFunc(func() bool { return !a.EnableHighlighting }),
+
+		huh.NewGroup(
+			huh.NewSelect[Format]().
+				Title("Choose the config file format").
+				Options(
+					huh.NewOption("JSON", FormatJSON),
+					huh.NewOption("YAML (supports comments)", FormatYAML),
+					huh.NewOption("TOML (supports comments)", FormatTOML),
+				).
+				Value(&a.Format),
+			huh.NewNote().
+				Title("Today's prayer times").
+				DescriptionFunc(func() string {
+					cfg, err := a.config()
+					if err != nil {
+						return "⚠ " + err.Error()
+					}
+					return preview(cfg)
+				}, a),
+			huh.NewConfirm().
+				Title(confirmTitle).
+				Affirmative("Save").
+				Negative("Cancel").
+				Value(&save).
+				Validate(func(ok bool) error {
+					if !ok {
+						return nil
+					}
+					_, err := a.config()
+					return err
+				}),
+		).Title("Review"),
+	)
+
+	// plain prompts for screen readers, as recommended by huh
+	form.WithAccessible(getEnv("ACCESSIBLE") != "")
+	if err := form.Run(); err != nil {
+		return nil, "", fmt.Errorf("failed to setup config: %s", err.Error())
+	}
+	if !save {
+		return nil, "", ErrSetupCancelled
+	}
+	config, err := a.config()
+	if err != nil {
+		return nil, "", err
+	}
+	return config, a.Format, nil
+}
+
+// methodOptions lists every calculation method for selection in a form
+func methodOptions() []huh.Option[Method] {
+	options := make([]huh.Option[Method], 0, len(salah.Methods))
+	for _, m := range salah.Methods {
+		options = append(options, huh.NewOption(m.String(), Method(m)))
+	}
+	return options
+}
+
+// highLatitudeRuleOptions lists every high latitude rule for selection in a form
+func highLatitudeRuleOptions() []huh.Option[HighLatitudeRule] {
+	options := make([]huh.Option[HighLatitudeRule], 0, len(salah.HighLatitudeRules))
+	for _, r := range salah.HighLatitudeRules {
+		options = append(options, huh.NewOption(r.String(), HighLatitudeRule(r)))
+	}
+	return options
+}
+
+// colourNames lists the colours available for highlighting
+func colourNames() []string {
+	var names []string
+	for name := range util.AnsiColors {
+		if name != "reset" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func parseRequiredFloat(name, s string) (float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, fmt.Errorf("%s can't be empty", name)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+	return f, nil
+}
+
+func parseOptionalFloat(name, s string) (*float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	f, err := parseRequiredFloat(name, s)
+	return &f, err
+}
+
+func parseOptionalInt(name, s string) (*int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a whole number", name)
+	}
+	return &n, nil
+}
+
+// parseAdjustments returns nil when every adjustment is empty
+func parseAdjustments(values [6]string) (*PrayerAdjustments, error) {
+	var ints [6]int
+	set := false
+	for i, s := range values {
+		n, err := parseOptionalInt(adjustmentKeys[i], s)
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			ints[i], set = *n, true
+		}
+	}
+	if !set {
+		return nil, nil
+	}
+	return &PrayerAdjustments{
+		FajrAdj: ints[0], SunriseAdj: ints[1], DhuhrAdj: ints[2],
+		AsrAdj: ints[3], MaghribAdj: ints[4], IshaAdj: ints[5],
+	}, nil
+}
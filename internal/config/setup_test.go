@@ -0,0 +1,83 @@
+package config
+
+import (
+	"reflect"
+	"salah-cli/pkg/salah"
+	"strings"
+	"testing"
+)
+
+func TestSetupAnswers_RoundTrip(t *testing.T) {
+	method, madhab, rule := Method(7), Madhab(1), HighLatitudeRule(4)
+	fajr, interval := 18.5, 90
+	existing := &Config{
+		Version:            CurrentVersion,
+		Latitude:           51.5,
+		Longitude:          -0.12,
+		Method:             &method,
+		Madhab:             &madhab,
+		HighLatitudeRule:   &rule,
+		FajrAngle:          &fajr,
+		IshaInterval:       &interval,
+		Adjustments:        &PrayerAdjustments{FajrAdj: 2, IshaAdj: -3},
+		MethodAdjustments:  &PrayerAdjustments{DhuhrAdj: 1},
+		IshaEnd:            "midnight",
+		Engine:             "timetable",
+		TimetablePath:      "/tmp/times.csv",
+		EnableCountdown:    true,
+		EnableHighlighting: true,
+		HighlightColour:    "cyan",
+		Hooks:              []Hook{{Event: "at:fajr", Command: "notify-send Fajr"}},
+		MQTT:               &MQTT{Broker: "localhost:1883"},
+		Webhooks:           []Webhook{{URL: "https://example.com/hook", Secret: "s"}},
+	}
+
+	a := newSetupAnswers(existing, FormatTOML)
+	if a.Latitude != "51.5" || a.FajrAngle != "18.5" || a.IshaAngle != "" || a.Adjustments[0] != "2" || a.Adjustments[1] != "" || a.Format != FormatTOML {
+		t.Errorf("unexpected answers %+v", a)
+	}
+	cfg, err := a.config()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(cfg, existing) {
+		t.Errorf("expected %+v, got %+v", existing, cfg)
+	}
+}
+
+func TestSetupAnswers_Defaults(t *testing.T) {
+	a := newSetupAnswers(&Config{}, FormatJSON)
+	if a.Latitude != "" || a.Engine != "adhan" || a.IshaEnd != "fajr" || a.HighlightColour != "green" {
+		t.Errorf("unexpected answers %+v", a)
+	}
+	if _, err := a.config(); err == nil || !strings.Contains(err.Error(), "latitude") {
+		t.Errorf("expected latitude error, got %v", err)
+	}
+
+	a.Latitude, a.Longitude, a.HighlightColour = "21.4", "39.8", "red"
+	cfg, err := a.config()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *cfg.Method != Method(salah.DefaultMethod) || cfg.Adjustments != nil || cfg.MethodAdjustments != nil || cfg.Engine != "" || cfg.IshaEnd != "" || cfg.HighlightColour != "" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestSetupAnswers_Invalid(t *testing.T) {
+	tests := map[string]func(a *setupAnswers){
+		"latitude":    func(a *setupAnswers) { a.Latitude = "100" },
+		"fajr angle":  func(a *setupAnswers) { a.FajrAngle = "high" },
+		"adjustment":  func(a *setupAnswers) { a.Adjustments[2] = "1.5" },
+		"conflict":    func(a *setupAnswers) { a.IshaAngle, a.IshaInterval = "17", "90" },
+		"timetable":   func(a *setupAnswers) { a.Engine = "timetable" },
+		"isha number": func(a *setupAnswers) { a.IshaInterval = "soon" },
+	}
+	for name, change := range tests {
+		a := newSetupAnswers(&Config{Latitude: 1, Longitude: 1}, FormatJSON)
+		change(a)
+		if _, err := a.config(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	// maxAdjustment is the largest per-prayer adjustment, in minutes, not reported as suspicious
+	maxAdjustment = 60
+	// maxAngle is the largest Fajr or Isha angle, in degrees, not reported as suspicious
+	maxAngle = 30.0
+	// maxIshaInterval is the largest Isha interval, in minutes, not reported as suspicious
+	maxIshaInterval = 180
+)
+
+// Severity is how serious a config problem is
+type Severity string
+
+const (
+	// SeverityError problems prevent the config from being loaded
+	SeverityError Severity = "error"
+	// SeverityWarning problems are reported but the config is still used
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a single issue found in a config file.
+// Line and Column are 1-based and zero when the position is unknown.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Field    string   `json:"field,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// At returns the location of the problem in the file at path, as path:line:column
+func (p Problem) At(path string) string {
+	if p.Line == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s:%d:%d", path, p.Line, p.Column)
+}
+
+// String formats the problem as "line:column: severity: message"
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.Severity, p.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", p.Line, p.Column, p.Severity, p.Message)
+}
+
+// HasErrors reports whether any of the problems is an error
+func HasErrors(problems []Problem) bool {
+	return slices.ContainsFunc(problems, func(p Problem) bool { return p.Severity == SeverityError })
+}
+
+// CheckFile reads and checks the config file at path
+func CheckFile(path string) (*Config, []Problem, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open config file %s: %w", path, err)
+	}
+	cfg, problems := CheckDataAs(data, format)
+	return cfg, problems, nil
+}
+
+// CheckData decodes a JSON config and returns every problem found (see CheckDataAs)
+func CheckData(data []byte) (*Config, []Problem) {
+	return CheckDataAs(data, FormatJSON)
+}
+
+// CheckDataAs decodes a config in the given format and returns every problem found, with the
+// position of the offending key where known. The config is nil if the document could not be parsed.
+func CheckDataAs(data []byte, format Format) (*Config, []Problem) {
+	cfg, _, problems := checkData(data, format)
+	return cfg, problems
+}
+
+// checkData implements CheckDataAs, also returning the document's top-level values after migration
+func checkData(data []byte, format Format) (*Config, map[string]json.RawMessage, []Problem) {
+	jsonData, positions, problem := decodeDocument(data, format)
+	if problem != nil {
+		return nil, nil, []Problem{*problem}
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &raw); err != nil {
+		return nil, nil, []Problem{decodeProblem(jsonData, err)}
+	}
+	if raw == nil {
+		return nil, nil, []Problem{{Severity: SeverityError, Line: 1, Column: 1, Message: "config must be a JSON object"}}
+	}
+
+	known := knownKeys(Docs(), "")
+	var problems []Problem
+	add := func(p Problem) {
+		if pos, ok := positions[p.Field]; ok {
+			p.Line, p.Column = pos.line, pos.column
+		}
+		problems = append(problems, p)
+	}
+
+	// upgrade older configs before decoding; positions still refer to the original keys
+	if _, _, err := migrateRaw(raw); err != nil {
+		add(Problem{Severity: SeverityError, Field: "version", Message: err.Error()})
+		return nil, nil, problems
+	}
+
+	for path := range positions {
+		parent, _, nested := strings.Cut(path, ".")
+		if _, ok := raw[parent]; !ok {
+			continue // removed by a migration
+		}
+		if !known[path] && (!nested || known[parent]) && path != schemaKey {
+			add(Problem{Severity: SeverityError, Field: path, Message: fmt.Sprintf("unknown field '%s'", path)})
+		}
+	}
+
+	cfg := &Config{}
+	for key, value := range raw {
+		target, ok := fieldByKey(cfg, key)
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, target); err != nil {
+			field := key
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				if typeErr.Field != "" && typeErr.Field != key {
+					field = key + "." + typeErr.Field
+				}
+				err = fmt.Errorf("%s must be %s (got %s)", field, article(typeName(typeErr.Type)), typeErr.Value)
+			}
+			add(Problem{Severity: SeverityError, Field: field, Message: err.Error()})
+		}
+	}
+
+	for _, doc := range Docs() {
+		if _, ok := raw[doc.Key]; doc.Required && !ok {
+			add(Problem{Severity: SeverityWarning, Field: doc.Key, Message: fmt.Sprintf("%s is not set, using 0", doc.Key)})
+		}
+	}
+	for _, p := range cfg.Problems() {
+		add(p)
+	}
+
+	// report in file order, with problems of unknown position last
+	slices.SortStableFunc(problems, func(a, b Problem) int {
+		return cmp.Compare(positionKey(a), positionKey(b))
+	})
+	return cfg, raw, problems
+}
+
+// decodeProblem converts a JSON decoding error into a positioned problem
+func decodeProblem(data []byte, err error) Problem {
+	p := Problem{Severity: SeverityError, Message: strings.TrimPrefix(err.Error(), "json: ")}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		p.Line, p.Column = position(data, max(syntaxErr.Offset-1, 0))
+	case errors.As(err, &typeErr):
+		p.Line, p.Column = position(data, max(typeErr.Offset-1, 0))
+		p.Message = fmt.Sprintf("config must be a JSON object (got %s)", typeErr.Value)
+	}
+	return p
+}
+
+// keyOffsets returns the byte offset of every object key in data, by dotted path
+func keyOffsets(data []byte) map[string]int64 {
+	offsets := map[string]int64{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var walk func(prefix string) error
+	walk = func(prefix string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				start := skipSeparators(data, dec.InputOffset())
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				path := key.(string)
+				if prefix != "" {
+					path = prefix + "." + path
+				}
+				offsets[path] = start
+				if err := walk(path); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", prefix, i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+	_ = walk("")
+	return offsets
+}
+
+// skipSeparators returns the offset of the next token after whitespace, commas and colons
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,:", rune(data[offset])) {
+		offset++
+	}
+	return offset
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (line, column int) {
+	before := data[:min(offset, int64(len(data)))]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+func positionKey(p Problem) int {
+	if p.Line == 0 {
+		return math.MaxInt
+	}
+	return p.Line<<16 | p.Column
+}
+
+// knownKeys returns the dotted path of every documented option
+func knownKeys(docs []FieldDoc, prefix string) map[string]bool {
+	known := map[string]bool{}
+	for _, d := range docs {
+		known[prefix+d.Key] = true
+		for k := range knownKeys(d.Keys, prefix+d.Key+".") {
+			known[k] = true
+		}
+	}
+	return known
+}
+
+// fieldByKey returns a pointer to the Config field with the given JSON name
+func fieldByKey(cfg *Config, key string) (any, bool) {
+	v := reflect.ValueOf(cfg).Elem()
+	for i := range v.NumField() {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if name == key {
+			return v.Field(i).Addr().Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// article prefixes a type name with "a" or "an"
+func article(typ string) string {
+	if strings.ContainsRune("aeiou", rune(typ[0])) {
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+// checkAngle reports an angle outside 0 to maxAngle degrees
+func checkAngle(field string, angle *float64) error {
+	if angle != nil && (*angle <= 0 || *angle > maxAngle) {
+		return fmt.Errorf("%s of %g° is outside 0 to %g°", field, *angle, maxAngle)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckData_CollectsEveryProblem(t *testing.T) {
+	data := `{
+  "latitude": 95,
+  "longitude": -0.12,
+  "method": 42,
+  "isha_angle": 40,
+  "isha_interval": 90,
+  "colour": "red",
+  "adjustments": {"FajrAdj": 90, "Foo": 1},
+  "madhab": true
+}`
+	_, problems := CheckData([]byte(data))
+
+	expected := []Problem{
+		{Severity: SeverityError, Field: "latitude", Line: 2, Column: 3},
+		{Severity: SeverityError, Field: "method", Line: 4, Column: 3},
+		{Severity: SeverityWarning, Field: "isha_angle", Line: 5, Column: 3},
+		{Severity: SeverityError, Field: "isha_interval", Line: 6, Column: 3},
+		{Severity: SeverityError, Field: "colour", Line: 7, Column: 3},
+		{Severity: SeverityWarning, Field: "adjustments.FajrAdj", Line: 8, Column: 19},
+		{Severity: SeverityError, Field: "adjustments.Foo", Line: 8, Column: 34},
+		{Severity: SeverityError, Field: "madhab", Line: 9, Column: 3},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		got := problems[i]
+		if got.Severity != want.Severity || got.Field != want.Field || got.Line != want.Line || got.Column != want.Column {
+			t.Errorf("problem %d: expected %s %s at %d:%d, got %s %s at %d:%d (%s)", i,
+				want.Severity, want.Field, want.Line, want.Column, got.Severity, got.Field, got.Line, got.Column, got.Message)
+		}
+	}
+	if !HasErrors(problems) {
+		t.Error("expected HasErrors to be true")
+	}
+}
+
+func TestCheckData_Valid(t *testing.T) {
+	cfg, problems := CheckData([]byte(`{"latitude": 51.5, "longitude": -0.12, "method": "isna", "adjustments": {"FajrAdj": 2}}`))
+	if len(problems) != 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+	if cfg == nil || cfg.Latitude != 51.5 || cfg.Adjustments.FajrAdj != 2 {
+		t.Errorf("expected decoded config, got %+v", cfg)
+	}
+}
+
+func TestCheckData_Warnings(t *testing.T) {
+	_, problems := CheckData([]byte(`{"longitude": 10, "fajr_angle": -3, "method_adjustments": {"IshaAdj": -75}}`))
+	if HasErrors(problems) {
+		t.Fatalf("expected only warnings, got %v", problems)
+	}
+	fields := map[string]bool{}
+	for _, p := range problems {
+		fields[p.Field] = true
+	}
+	for _, want := range []string{"latitude", "fajr_angle", "method_adjustments.IshaAdj"} {
+		if !fields[want] {
+			t.Errorf("expected warning for %s, got %v", want, problems)
+		}
+	}
+}
+
+func TestCheckData_SyntaxError(t *testing.T) {
+	cfg, problems := CheckData([]byte("{\n  \"latitude\": 1,\n}"))
+	if cfg != nil {
+		t.Errorf("expected nil config, got %+v", cfg)
+	}
+	if len(problems) != 1 || problems[0].Line != 3 || problems[0].Column != 1 {
+		t.Fatalf("expected one problem at 3:1, got %v", problems)
+	}
+
+	_, problems = CheckData([]byte(`[1, 2]`))
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "JSON object") {
+		t.Errorf("expected object error, got %v", problems)
+	}
+}
+
+func TestCheckData_TypeError(t *testing.T) {
+	_, problems := CheckData([]byte(`{"latitude": "north", "adjustments": {"AsrAdj": "late"}}`))
+	messages := []string{"latitude must be a number (got string)", "adjustments.AsrAdj must be an integer (got string)"}
+	for _, want := range messages {
+		found := false
+		for _, p := range problems {
+			found = found || p.Message == want
+		}
+		if !found {
+			t.Errorf("expected problem %q, got %v", want, problems)
+		}
+	}
+}
+
+func TestLoadFromFile_ReportsPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{\n  \"latitude\": 1,\n  \"longitude\": 2,\n  \"engine\": \"abacus\"\n}"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	_, err := loadFromFile(path)
+	if err == nil || !strings.Contains(err.Error(), path+":4:3") {
+		t.Errorf("expected error at %s:4:3, got %v", path, err)
+	}
+}
+
+func TestProblem_String(t *testing.T) {
+	p := Problem{Severity: SeverityWarning, Line: 2, Column: 5, Message: "suspicious"}
+	if got := p.String(); got != "2:5: warning: suspicious" {
+		t.Errorf("unexpected string %q", got)
+	}
+	if got := p.At("config.json"); got != "config.json:2:5" {
+		t.Errorf("unexpected location %q", got)
+	}
+}
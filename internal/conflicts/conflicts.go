@@ -0,0 +1,118 @@
+package conflicts
+
+import (
+	"salah-cli/internal/calendar"
+	"salah-cli/pkg/salah"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultWindow is the time kept free for each prayer when no window is given
+	DefaultWindow = 20 * time.Minute
+	// MaxDays bounds the days checked
+	MaxDays = 366
+)
+
+// Options select the time kept free for each prayer
+type Options struct {
+	// Window is the time kept free for each prayer
+	Window time.Duration
+	// Delay starts the time kept free this long after the prayer time, e.g. at the iqamah
+	Delay time.Duration
+	// IshaEnd selects when the window of Isha ends, bounding the free slots suggested for it
+	IshaEnd salah.IshaEnd
+}
+
+// Slot is a span of time
+type Slot struct {
+	Start time.Time
+	End   time.Time
+}
+
+// overlaps reports whether the slot and the event share some time
+func (s Slot) overlaps(e calendar.Event) bool {
+	return e.Start.Before(s.End) && e.End.After(s.Start)
+}
+
+// Conflict is a prayer whose time is taken by calendar events
+type Conflict struct {
+	Prayer salah.Prayer
+	// Time is the prayer time and Block the time kept free for it
+	Time  time.Time
+	Block Slot
+	// WindowEnd is when the window of the prayer ends (see salah.Window)
+	WindowEnd time.Time
+	// Events are the busy events overlapping Block
+	Events []calendar.Event
+	// Free is the first slot of the window's length free of events in the prayer's window, nil
+	// when there is none
+	Free *Slot
+}
+
+// Find returns the conflicts between the busy events of a calendar and the obligatory prayers
+// of days days from from. Transparent, all-day and cancelled events are not busy.
+func Find(calculator *salah.Calculator, events []calendar.Event, from time.Time, days int, opts Options) ([]Conflict, error) {
+	var busy []calendar.Event
+	for _, e := range events {
+		if e.Opaque && !e.AllDay && !e.Cancelled {
+			busy = append(busy, e)
+		}
+	}
+
+	var conflicts []Conflict
+	for d := range days {
+		s, err := calculator.ForDate(from.AddDate(0, 0, d))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range salah.Obligatory {
+			t := s.Time(p)
+			block := Slot{Start: t.Add(opts.Delay), End: t.Add(opts.Delay + opts.Window)}
+			var taken []calendar.Event
+			for _, e := range busy {
+				if block.overlaps(e) {
+					taken = append(taken, e)
+				}
+			}
+			if len(taken) == 0 {
+				continue
+			}
+			w, err := calculator.Window(t, opts.IshaEnd)
+			if err != nil {
+				return nil, err
+			}
+			conflicts = append(conflicts, Conflict{
+				Prayer:    p,
+				Time:      t,
+				Block:     block,
+				WindowEnd: w.End,
+				Events:    taken,
+				Free:      freeSlot(busy, t, w.End, opts.Window),
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// freeSlot returns the first slot of length between start and end overlapping no busy event
+func freeSlot(busy []calendar.Event, start, end time.Time, length time.Duration) *Slot {
+	// a free slot starts at the start or when an event ends
+	candidates := []time.Time{start}
+	for _, e := range busy {
+		if e.End.After(start) && e.End.Before(end) {
+			candidates = append(candidates, e.End)
+		}
+	}
+	slices.SortFunc(candidates, time.Time.Compare)
+	for _, c := range candidates {
+		slot := Slot{Start: c, End: c.Add(length)}
+		if slot.End.After(end) {
+			return nil
+		}
+		if !slices.ContainsFunc(busy, slot.overlaps) {
+			return &slot
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package conflicts
+
+import (
+	"salah-cli/internal/calendar"
+	"salah-cli/pkg/salah"
+	"salah-cli/pkg/salah/salahtest"
+	"testing"
+	"time"
+)
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2025, 8, day, hour, minute, 0, 0, time.UTC)
+}
+
+func meeting(summary string, start, end time.Time) calendar.Event {
+	return calendar.Event{Summary: summary, Start: start, End: end, Opaque: true}
+}
+
+func TestFind(t *testing.T) {
+	events := []calendar.Event{
+		meeting("Planning", at(27, 12, 30), at(27, 13, 30)),
+		meeting("Review", at(27, 13, 30), at(27, 14, 0)),
+		meeting("Retro", at(28, 16, 0), at(28, 16, 40)),
+		{Summary: "Focus time", Start: at(28, 12, 0), End: at(28, 14, 0)},
+		{Summary: "Holiday", Start: at(29, 0, 0), End: at(30, 0, 0), Opaque: true, AllDay: true},
+	}
+	found, err := Find(salahtest.Calculator(t), events, at(27, 12, 0), 3, Options{Window: DefaultWindow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected Dhuhr on the 27th and Asr on the 28th, got %+v", found)
+	}
+
+	dhuhr := found[0]
+	if dhuhr.Prayer != salah.Dhuhr || !dhuhr.Block.End.Equal(at(27, 13, 20)) || !dhuhr.WindowEnd.Equal(at(27, 16, 30)) {
+		t.Errorf("unexpected conflict %+v", dhuhr)
+	}
+	if len(dhuhr.Events) != 1 || dhuhr.Events[0].Summary != "Planning" {
+		t.Errorf("expected Planning to conflict, got %+v", dhuhr.Events)
+	}
+	// free once Planning and then Review are over
+	if dhuhr.Free == nil || !dhuhr.Free.Start.Equal(at(27, 14, 0)) || !dhuhr.Free.End.Equal(at(27, 14, 20)) {
+		t.Errorf("expected a free slot at 14:00, got %+v", dhuhr.Free)
+	}
+
+	asr := found[1]
+	if asr.Prayer != salah.Asr || asr.Free == nil || !asr.Free.Start.Equal(at(28, 16, 40)) {
+		t.Errorf("expected Asr free after the retro, got %+v", asr)
+	}
+}
+
+func TestFind_Delay(t *testing.T) {
+	events := []calendar.Event{meeting("Planning", at(27, 12, 30), at(27, 13, 10))}
+	found, err := Find(salahtest.Calculator(t), events, at(27, 12, 0), 1, Options{Window: 15 * time.Minute, Delay: 15 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("expected no conflict with an iqamah after the meeting, got %+v", found)
+	}
+}
+
+func TestFind_NoFreeSlot(t *testing.T) {
+	events := []calendar.Event{meeting("Offsite", at(27, 9, 0), at(27, 17, 0))}
+	found, err := Find(salahtest.Calculator(t), events, at(27, 12, 0), 1, Options{Window: DefaultWindow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].Free != nil || found[1].Free == nil || !found[1].Free.Start.Equal(at(27, 17, 0)) {
+		t.Errorf("expected no free slot for Dhuhr and Asr free at 17:00, got %+v", found)
+	}
+}
@@ -0,0 +1,57 @@
+package diff
+
+import "testing"
+
+func TestUnified_Equal(t *testing.T) {
+	if got := Unified("a", "b", "x\ny\n", "x\ny\n"); got != "" {
+		t.Errorf("expected no diff, got %q", got)
+	}
+}
+
+func TestUnified_Change(t *testing.T) {
+	a := "{\n  \"latitude\": 1,\n  \"method\": 2\n}\n"
+	b := "{\n  \"version\": 2,\n  \"latitude\": 1,\n  \"method\": \"egyptian\"\n}\n"
+	expected := `--- old
++++ new
+@@ -1,4 +1,5 @@
+ {
++  "version": 2,
+   "latitude": 1,
+-  "method": 2
++  "method": "egyptian"
+ }
+`
+	if got := Unified("old", "new", a, b); got != expected {
+		t.Errorf("unexpected diff:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestUnified_SeparateHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n"
+	expected := `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++twelve
+`
+	if got := Unified("a", "b", a, b); got != expected {
+		t.Errorf("unexpected diff:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestUnified_FromEmpty(t *testing.T) {
+	expected := "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n"
+	if got := Unified("a", "b", "", "x\n"); got != expected {
+		t.Errorf("unexpected diff %q", got)
+	}
+}
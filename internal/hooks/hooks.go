@@ -0,0 +1,197 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"salah-cli/internal/config"
+	"salah-cli/pkg/salah"
+	"strconv"
+	"time"
+)
+
+// Occurrence is a hook due at a time
+type Occurrence struct {
+	Hook config.Hook
+	// At is when the hook runs
+	At   time.Time
+	Data config.HookData
+}
+
+// Time returns when the event occurs on the day of date
+func Time(calculator *salah.Calculator, event config.HookEvent, ishaEnd salah.IshaEnd, date time.Time) (at, prayerTime time.Time, err error) {
+	if event.Kind == config.HookDayStart {
+		y, m, d := date.In(calculator.Location()).Date()
+		midnight := time.Date(y, m, d, 0, 0, 0, 0, calculator.Location())
+		return midnight, midnight, nil
+	}
+
+	s, err := calculator.ForDate(date)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	prayerTime = s.Time(event.Prayer)
+	switch event.Kind {
+	case config.HookBefore:
+		return prayerTime.Add(-event.Before), prayerTime, nil
+	case config.HookEnd:
+		w, err := calculator.Window(prayerTime, ishaEnd)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return w.End, prayerTime, nil
+	}
+	return prayerTime, prayerTime, nil
+}
+
+// NextOf returns the first occurrence of a hook after after
+func NextOf(calculator *salah.Calculator, hook config.Hook, ishaEnd salah.IshaEnd, after time.Time) (Occurrence, error) {
+	event, err := config.ParseHookEvent(hook.Event)
+	if err != nil {
+		return Occurrence{}, err
+	}
+	// the previous day's end:isha, or an Isha after midnight, can still be ahead, as can the
+	// next day's before events
+	for d := -1; d <= 2; d++ {
+		at, prayerTime, err := Time(calculator, event, ishaEnd, after.AddDate(0, 0, d))
+		if err != nil && d < 0 {
+			continue // e.g. a timetable starting today
+		}
+		if err != nil {
+			return Occurrence{}, err
+		}
+		if at.After(after) {
+			data := config.HookData{
+				Event:     hook.Event,
+				Time:      prayerTime,
+				Latitude:  calculator.Latitude(),
+				Longitude: calculator.Longitude(),
+			}
+			if event.Prayer != salah.NoPrayer {
+				data.Prayer = event.Prayer.String()
+			}
+			return Occurrence{Hook: hook, At: at, Data: data}, nil
+		}
+	}
+	return Occurrence{}, fmt.Errorf("no occurrence of %s found after %s", hook.Event, after.Format(time.RFC3339))
+}
+
+// Next returns the hooks occurring first after after, all due at the same time
+func Next(calculator *salah.Calculator, hooks []config.Hook, ishaEnd salah.IshaEnd, after time.Time) ([]Occurrence, error) {
+	var next []Occurrence
+	for _, h := range hooks {
+		o, err := NextOf(calculator, h, ishaEnd, after)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(next) == 0 || o.At.Before(next[0].At):
+			next = []Occurrence{o}
+		case o.At.Equal(next[0].At):
+			next = append(next, o)
+		}
+	}
+	return next, nil
+}
+
+// Run runs the hook of an occurrence, stopping it after its timeout. Each line of its output is
+// logged, prefixed with the event.
+func Run(ctx context.Context, o Occurrence, logger *log.Logger) error {
+	timeout, err := o.Hook.TimeoutDuration()
+	if err != nil {
+		return err
+	}
+	command, err := render(o.Hook.Command, o.Data)
+	if err != nil {
+		return err
+	}
+	args := make([]string, len(o.Hook.Args))
+	for i, arg := range o.Hook.Args {
+		if args[i], err = render(arg, o.Data); err != nil {
+			return err
+		}
+	}
+	env := append(os.Environ(),
+		"SALAH_HOOK_EVENT="+o.Data.Event,
+		"SALAH_HOOK_PRAYER="+o.Data.Prayer,
+		"SALAH_HOOK_TIME="+o.Data.Time.Format(time.RFC3339),
+		"SALAH_HOOK_LATITUDE="+strconv.FormatFloat(o.Data.Latitude, 'f', -1, 64),
+		"SALAH_HOOK_LONGITUDE="+strconv.FormatFloat(o.Data.Longitude, 'f', -1, 64),
+	)
+	for name, value := range o.Hook.Env {
+		value, err := render(value, o.Data)
+		if err != nil {
+			return err
+		}
+		env = append(env, name+"="+value)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	switch {
+	case len(args) > 0:
+		cmd = exec.CommandContext(ctx, command, args...)
+	case runtime.GOOS == "windows":
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	default:
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+	cmd.Env = env
+	out := &lineLogger{logger: logger, prefix: o.Hook.Event}
+	cmd.Stdout, cmd.Stderr = out, out
+	// don't wait for children keeping the output open after the command is stopped
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	out.flush()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// render executes a hook template
+func render(text string, data config.HookData) (string, error) {
+	t, err := config.ParseHookTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// lineLogger logs the output of a hook line by line
+type lineLogger struct {
+	logger  *log.Logger
+	prefix  string
+	pending []byte
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.pending = append(l.pending, p...)
+	for {
+		i := bytes.IndexByte(l.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		l.logger.Printf("%s: %s", l.prefix, bytes.TrimRight(l.pending[:i], "\r"))
+		l.pending = l.pending[i+1:]
+	}
+}
+
+// flush logs the last line of output when it doesn't end with a newline
+func (l *lineLogger) flush() {
+	if len(l.pending) > 0 {
+		l.logger.Printf("%s: %s", l.prefix, l.pending)
+		l.pending = nil
+	}
+}
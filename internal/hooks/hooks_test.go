@@ -0,0 +1,107 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"runtime"
+	"salah-cli/internal/config"
+	"salah-cli/pkg/salah"
+	"salah-cli/pkg/salah/salahtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextOf(t *testing.T) {
+	calculator := salahtest.Calculator(t)
+	now := time.Date(2025, 3, 9, 18, 0, 0, 0, time.UTC)
+	day := func(d, h, m int) time.Time { return time.Date(2025, 3, d, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		event   string
+		ishaEnd salah.IshaEnd
+		at      time.Time
+		prayer  string
+	}{
+		{"before:maghrib:5m", salah.IshaEndFajr, day(9, 18, 55), "Maghrib"},
+		{"before:fajr:10h", salah.IshaEndFajr, day(9, 19, 0), "Fajr"},
+		{"at:asr", salah.IshaEndFajr, day(10, 16, 30), "Asr"},
+		{"end:fajr", salah.IshaEndFajr, day(10, 6, 30), "Fajr"},
+		{"end:isha", salah.IshaEndFajr, day(10, 5, 0), "Isha"},
+		{"end:isha", salah.IshaEndMidnight, day(10, 0, 0), "Isha"},
+		{"day:start", salah.IshaEndFajr, day(10, 0, 0), ""},
+	}
+	for _, tt := range tests {
+		o, err := NextOf(calculator, config.Hook{Event: tt.event}, tt.ishaEnd, now)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.event, err)
+		}
+		if !o.At.Equal(tt.at) || o.Data.Prayer != tt.prayer || o.Data.Event != tt.event || o.Data.Latitude != 51.5 {
+			t.Errorf("%s: got %v %+v, want %v %s", tt.event, o.At, o.Data, tt.at, tt.prayer)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	hooks := []config.Hook{
+		{Event: "at:isha", Command: "a"},
+		{Event: "at:maghrib", Command: "b"},
+		{Event: "before:isha:90m", Command: "c"},
+	}
+	next, err := Next(salahtest.Calculator(t), hooks, salah.IshaEndFajr, time.Date(2025, 3, 9, 18, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next) != 2 || next[0].Hook.Command != "b" || next[1].Hook.Command != "c" {
+		t.Errorf("expected the Maghrib hooks, got %+v", next)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use /bin/sh")
+	}
+	data := config.HookData{Event: "at:fajr", Prayer: "Fajr", Time: time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC), Latitude: 51.5}
+	run := func(hook config.Hook) (string, error) {
+		var buf bytes.Buffer
+		err := Run(context.Background(), Occurrence{Hook: hook, Data: data}, log.New(&buf, "", 0))
+		return buf.String(), err
+	}
+
+	out, err := run(config.Hook{
+		Event:   "at:fajr",
+		Command: `echo "{{.Prayer}} at {{.Time.Format "15:04"}}"; echo "$AT $SALAH_HOOK_LATITUDE" >&2; printf last`,
+		Env:     map[string]string{"AT": "{{.Event}}"},
+	})
+	if err != nil || out != "at:fajr: Fajr at 05:00\nat:fajr: at:fajr 51.5\nat:fajr: last\n" {
+		t.Errorf("unexpected output %q, %v", out, err)
+	}
+
+	out, err = run(config.Hook{Event: "at:fajr", Command: "echo", Args: []string{"{{.Prayer}};", "$HOME"}})
+	if err != nil || out != "at:fajr: Fajr; $HOME\n" {
+		t.Errorf("expected args to be passed without a shell, got %q, %v", out, err)
+	}
+
+	if _, err := run(config.Hook{Event: "at:fajr", Command: "exit 3"}); err == nil {
+		t.Error("expected an error for a failing command")
+	}
+
+	start := time.Now()
+	_, err = run(config.Hook{Event: "at:fajr", Command: "sleep 5", Timeout: "100ms"})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") || time.Since(start) > 3*time.Second {
+		t.Errorf("expected the command to time out, got %v after %s", err, time.Since(start))
+	}
+}
+
+func TestNextOf_AfterMidnight(t *testing.T) {
+	// the window of the previous day's Isha ends at today's Fajr
+	now := time.Date(2025, 3, 9, 2, 0, 0, 0, time.UTC)
+	o, err := NextOf(salahtest.Calculator(t), config.Hook{Event: "end:isha"}, salah.IshaEndFajr, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC); !o.At.Equal(want) || o.Data.Time.Day() != 8 {
+		t.Errorf("expected the end of yesterday's Isha at %v, got %v %+v", want, o.At, o.Data)
+	}
+}
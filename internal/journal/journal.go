@@ -0,0 +1,307 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"salah-cli/internal/config"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	DefaultJournalFileName = "journal.json"
+	DateLayout             = "2006-01-02"
+)
+
+// Prayers lists the obligatory prayers tracked by the journal, in daily order
+var Prayers = []string{"Fajr", "Dhuhr", "Asr", "Maghrib", "Isha"}
+
+// Entry records a single completed prayer
+type Entry struct {
+	Prayer   string    `json:"prayer"`
+	Date     string    `json:"date"`
+	LoggedAt time.Time `json:"logged_at"`
+	Late     bool      `json:"late,omitempty"`
+	Jamaah   bool      `json:"jamaah,omitempty"`
+}
+
+// Journal holds every logged prayer
+type Journal struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Window is the time during which a prayer can be performed: it is due from Start and only
+// missed once End has passed
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ScheduleFunc returns the window of each prayer on the given date, keyed by prayer name
+type ScheduleFunc func(date time.Time) (map[string]Window, error)
+
+// Missed is a prayer whose window has ended without it being logged
+type Missed struct {
+	Prayer string
+	Date   string
+	Due    time.Time
+}
+
+// PrayerStats summarises how consistently a single prayer was logged
+type PrayerStats struct {
+	Prayer        string
+	Due           int
+	Prayed        int
+	OnTime        int
+	Jamaah        int
+	CurrentStreak int
+	LongestStreak int
+}
+
+// OnTimePercent returns the share of due prayers that were logged on time
+func (s PrayerStats) OnTimePercent() float64 {
+	if s.Due == 0 {
+		return 0
+	}
+	return float64(s.OnTime) / float64(s.Due) * 100
+}
+
+// ParsePrayer normalises a user supplied prayer name (case-insensitive)
+func ParsePrayer(name string) (string, error) {
+	for _, p := range Prayers {
+		if strings.EqualFold(p, name) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown prayer '%s'. Allowed: %v", name, Prayers)
+}
+
+// GetJournalPath returns the journal location, stored alongside the config file
+func GetJournalPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, DefaultJournalFileName), nil
+}
+
+// Load reads the journal from path, returning an empty journal if it does not exist yet
+func Load(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Journal{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read journal %s: %w", path, err)
+	}
+
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("error decoding journal %s: %w", path, err)
+	}
+	return &j, nil
+}
+
+// Save writes the journal to path, replacing any previous file atomically
+func Save(j *Journal, path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create journal directory %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, DefaultJournalFileName+".tmp.*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to move journal into place: %w", err)
+	}
+	return nil
+}
+
+// Find returns the entry for a prayer on a date, or nil if it was not logged
+func (j *Journal) Find(prayer, date string) *Entry {
+	for i := range j.Entries {
+		if j.Entries[i].Prayer == prayer && j.Entries[i].Date == date {
+			return &j.Entries[i]
+		}
+	}
+	return nil
+}
+
+// Add records an entry, replacing an existing entry for the same prayer and date.
+// It reports whether an existing entry was replaced.
+func (j *Journal) Add(e Entry) (bool, error) {
+	prayer, err := ParsePrayer(e.Prayer)
+	if err != nil {
+		return false, err
+	}
+	e.Prayer = prayer
+	if _, err := time.Parse(DateLayout, e.Date); err != nil {
+		return false, fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", e.Date)
+	}
+
+	if existing := j.Find(e.Prayer, e.Date); existing != nil {
+		*existing = e
+		return true, nil
+	}
+	j.Entries = append(j.Entries, e)
+	sort.SliceStable(j.Entries, func(a, b int) bool {
+		return j.Entries[a].Date < j.Entries[b].Date
+	})
+	return false, nil
+}
+
+// Start returns the date of the earliest entry, or false if the journal is empty
+func (j *Journal) Start(loc *time.Location) (time.Time, bool) {
+	if len(j.Entries) == 0 {
+		return time.Time{}, false
+	}
+	earliest := j.Entries[0].Date
+	for _, e := range j.Entries {
+		if e.Date < earliest {
+			earliest = e.Date
+		}
+	}
+	t, err := time.ParseInLocation(DateLayout, earliest, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// forEachDuePrayer calls fn for every prayer whose window has ended between from and now, in
+// chronological order. A prayer whose window is still open is not missed yet.
+func forEachDuePrayer(from, now time.Time, schedule ScheduleFunc, fn func(prayer, date string, due time.Time)) error {
+	loc := now.Location()
+	// iterate at midday to stay clear of DST transitions
+	day := time.Date(from.Year(), from.Month(), from.Day(), 12, 0, 0, 0, loc)
+	last := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, loc)
+
+	for !day.After(last) {
+		times, err := schedule(day)
+		if err != nil {
+			return fmt.Errorf("failed to get prayer times for %s: %w", day.Format(DateLayout), err)
+		}
+		for _, p := range Prayers {
+			w, ok := times[p]
+			if !ok || w.End.After(now) {
+				continue
+			}
+			fn(p, day.Format(DateLayout), w.Start)
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+// Outstanding returns every prayer whose window ended between from and now that has not been logged
+func (j *Journal) Outstanding(from, now time.Time, schedule ScheduleFunc) ([]Missed, error) {
+	var missed []Missed
+	err := forEachDuePrayer(from, now, schedule, func(prayer, date string, due time.Time) {
+		if j.Find(prayer, date) == nil {
+			missed = append(missed, Missed{Prayer: prayer, Date: date, Due: due})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return missed, nil
+}
+
+// Stats returns per-prayer completion statistics for prayers whose window ended between from and now
+func (j *Journal) Stats(from, now time.Time, schedule ScheduleFunc) ([]PrayerStats, error) {
+	byPrayer := make(map[string]*PrayerStats, len(Prayers))
+	for _, p := range Prayers {
+		byPrayer[p] = &PrayerStats{Prayer: p}
+	}
+
+	err := forEachDuePrayer(from, now, schedule, func(prayer, date string, _ time.Time) {
+		s := byPrayer[prayer]
+		s.Due++
+		e := j.Find(prayer, date)
+		if e == nil {
+			s.CurrentStreak = 0
+			return
+		}
+		s.Prayed++
+		if !e.Late {
+			s.OnTime++
+		}
+		if e.Jamaah {
+			s.Jamaah++
+		}
+		s.CurrentStreak++
+		if s.CurrentStreak > s.LongestStreak {
+			s.LongestStreak = s.CurrentStreak
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]PrayerStats, 0, len(Prayers))
+	for _, p := range Prayers {
+		out = append(out, *byPrayer[p])
+	}
+	return out, nil
+}
+
+// FormatOutstanding returns a human readable list of missed prayers
+func FormatOutstanding(missed []Missed, since time.Time) string {
+	if len(missed) == 0 {
+		return fmt.Sprintf("No outstanding prayers since %s", since.Format(DateLayout))
+	}
+
+	counts := make(map[string]int, len(Prayers))
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d outstanding prayer(s) since %s:\n", len(missed), since.Format(DateLayout))
+	for _, m := range missed {
+		counts[m.Prayer]++
+		fmt.Fprintf(&b, "  %s  %-7s (due %s)\n", m.Date, m.Prayer, m.Due.Local().Format("15:04"))
+	}
+
+	totals := make([]string, 0, len(Prayers))
+	for _, p := range Prayers {
+		totals = append(totals, fmt.Sprintf("%s %d", p, counts[p]))
+	}
+	b.WriteString(strings.Join(totals, " | "))
+	return b.String()
+}
+
+// FormatStats returns a table of per-prayer statistics
+func FormatStats(stats []PrayerStats, from, to time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Stats from %s to %s\n", from.Format(DateLayout), to.Format(DateLayout))
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Prayer\tPrayed\tOn time\tJamaah\tStreak\tBest")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d/%d\t%.0f%%\t%d\t%d\t%d\n",
+			s.Prayer, s.Prayed, s.Due, s.OnTimePercent(), s.Jamaah, s.CurrentStreak, s.LongestStreak)
+	}
+	_ = w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
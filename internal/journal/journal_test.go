@@ -0,0 +1,163 @@
+package journal
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedSchedule returns the same clock times for every day, Isha ending at the next Fajr
+func fixedSchedule(date time.Time) (map[string]Window, error) {
+	at := func(h, m int) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, date.Location())
+	}
+	return map[string]Window{
+		"Fajr":    {at(5, 0), at(6, 30)},
+		"Dhuhr":   {at(13, 0), at(16, 30)},
+		"Asr":     {at(16, 30), at(19, 0)},
+		"Maghrib": {at(19, 0), at(20, 30)},
+		"Isha":    {at(20, 30), at(29, 0)},
+	}, nil
+}
+
+func TestParsePrayer(t *testing.T) {
+	got, err := ParsePrayer("fAjR")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != "Fajr" {
+		t.Errorf("expected Fajr, got %q", got)
+	}
+	if _, err := ParsePrayer("sunrise"); err == nil {
+		t.Error("expected error for sunrise, got nil")
+	}
+}
+
+func TestAdd_ReplacesExistingEntry(t *testing.T) {
+	var j Journal
+	if replaced, err := j.Add(Entry{Prayer: "fajr", Date: "2025-08-27"}); err != nil || replaced {
+		t.Fatalf("expected fresh entry, got replaced=%v err=%v", replaced, err)
+	}
+	replaced, err := j.Add(Entry{Prayer: "Fajr", Date: "2025-08-27", Jamaah: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !replaced {
+		t.Error("expected existing entry to be replaced")
+	}
+	if len(j.Entries) != 1 || !j.Entries[0].Jamaah {
+		t.Errorf("unexpected entries: %+v", j.Entries)
+	}
+}
+
+func TestAdd_InvalidDate(t *testing.T) {
+	var j Journal
+	if _, err := j.Add(Entry{Prayer: "Fajr", Date: "27/08/2025"}); err == nil {
+		t.Fatal("expected error for invalid date, got nil")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", DefaultJournalFileName)
+
+	empty, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected missing journal to load empty, got %v", err)
+	}
+	if len(empty.Entries) != 0 {
+		t.Fatalf("expected empty journal, got %+v", empty.Entries)
+	}
+
+	j := &Journal{}
+	_, _ = j.Add(Entry{Prayer: "Asr", Date: "2025-08-27", Late: true})
+	if err := Save(j, path); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if e := loaded.Find("Asr", "2025-08-27"); e == nil || !e.Late {
+		t.Errorf("expected late Asr entry, got %+v", loaded.Entries)
+	}
+}
+
+func TestOutstanding(t *testing.T) {
+	from := time.Date(2025, 8, 26, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 8, 27, 14, 0, 0, 0, time.UTC) // after Dhuhr on the second day
+
+	j := &Journal{}
+	for _, p := range Prayers {
+		_, _ = j.Add(Entry{Prayer: p, Date: "2025-08-26"})
+	}
+	_, _ = j.Add(Entry{Prayer: "Dhuhr", Date: "2025-08-27"})
+
+	missed, err := j.Outstanding(from, now, fixedSchedule)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(missed) != 1 || missed[0].Prayer != "Fajr" || missed[0].Date != "2025-08-27" {
+		t.Errorf("expected only Fajr on 2025-08-27 outstanding, got %+v", missed)
+	}
+
+	// Asr is only missed once Maghrib comes
+	for _, tt := range []struct {
+		now  time.Time
+		want int
+	}{{time.Date(2025, 8, 27, 18, 59, 0, 0, time.UTC), 1}, {time.Date(2025, 8, 27, 19, 0, 0, 0, time.UTC), 2}} {
+		if missed, _ := j.Outstanding(from, tt.now, fixedSchedule); len(missed) != tt.want {
+			t.Errorf("at %s: expected %d outstanding, got %+v", tt.now.Format("15:04"), tt.want, missed)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	from := time.Date(2025, 8, 25, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 8, 27, 23, 0, 0, 0, time.UTC)
+
+	j := &Journal{}
+	_, _ = j.Add(Entry{Prayer: "Fajr", Date: "2025-08-25"})
+	// 2025-08-26 Fajr missed
+	_, _ = j.Add(Entry{Prayer: "Fajr", Date: "2025-08-27", Late: true, Jamaah: true})
+
+	stats, err := j.Stats(from, now, fixedSchedule)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	fajr := stats[0]
+	if fajr.Prayer != "Fajr" {
+		t.Fatalf("expected Fajr first, got %s", fajr.Prayer)
+	}
+	if fajr.Due != 3 || fajr.Prayed != 2 || fajr.OnTime != 1 || fajr.Jamaah != 1 {
+		t.Errorf("unexpected counts: %+v", fajr)
+	}
+	if fajr.CurrentStreak != 1 || fajr.LongestStreak != 1 {
+		t.Errorf("unexpected streaks: %+v", fajr)
+	}
+	if stats[1].Prayed != 0 || stats[1].CurrentStreak != 0 {
+		t.Errorf("expected no Dhuhr logged, got %+v", stats[1])
+	}
+	// the window of Isha on 2025-08-27 is still open
+	if isha := stats[4]; isha.Due != 2 {
+		t.Errorf("expected 2 Isha due, got %+v", isha)
+	}
+	_, _ = j.Add(Entry{Prayer: "Isha", Date: "2025-08-25"})
+	_, _ = j.Add(Entry{Prayer: "Isha", Date: "2025-08-26"})
+	if stats, _ := j.Stats(from, now, fixedSchedule); stats[4].CurrentStreak != 2 {
+		t.Errorf("expected the open Isha window to keep the streak, got %+v", stats[4])
+	}
+}
+
+func TestFormatOutstanding(t *testing.T) {
+	since := time.Date(2025, 8, 26, 0, 0, 0, 0, time.UTC)
+	if out := FormatOutstanding(nil, since); !strings.Contains(out, "No outstanding") {
+		t.Errorf("expected no outstanding message, got %q", out)
+	}
+
+	out := FormatOutstanding([]Missed{{Prayer: "Asr", Date: "2025-08-26"}}, since)
+	if !strings.Contains(out, "1 outstanding") || !strings.Contains(out, "Asr 1") {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
@@ -1,43 +1,88 @@
 package params
 
 import (
+	"fmt"
 	"salah-cli/internal/config"
-
-	"github.com/mnadev/adhango/pkg/calc"
+	"salah-cli/pkg/salah"
 )
 
-func BuildCalculationParams(config *config.Config) (*calc.CalculationParameters, error) {
-
-	var params *calc.CalculationParameters
+// BuildEngine returns the calculation engine selected in the config
+func BuildEngine(config *config.Config) (salah.Engine, error) {
+	switch config.Engine {
+	case "", "adhan":
+		return salah.AdhanEngine{}, nil
+	case "native":
+		return salah.NativeEngine{}, nil
+	case "timetable":
+		return salah.LoadTimetable(config.TimetablePath)
+	default:
+		return nil, fmt.Errorf("unknown engine '%s'", config.Engine)
+	}
+}
 
-	if config.Method == nil {
-		params = calc.GetMethodParameters(calc.MOON_SIGHTING_COMMITTEE)
-	} else {
-		params = calc.GetMethodParameters(calc.CalculationMethod(*config.Method))
+// BuildOptions converts the config into calculator options
+func BuildOptions(config *config.Config) ([]salah.Option, error) {
+	engine, err := BuildEngine(config)
+	if err != nil {
+		return nil, err
+	}
+	opts := []salah.Option{
+		salah.WithLocation(config.Latitude, config.Longitude),
+		salah.WithEngine(engine),
 	}
 
+	if config.Method != nil {
+		opts = append(opts, salah.WithMethod(salah.Method(*config.Method)))
+	}
 	if config.FajrAngle != nil {
-		params.FajrAngle = *config.FajrAngle
+		opts = append(opts, salah.WithFajrAngle(*config.FajrAngle))
 	}
 	if config.IshaAngle != nil {
-		params.IshaAngle = *config.IshaAngle
+		opts = append(opts, salah.WithIshaAngle(*config.IshaAngle))
 	}
 	if config.IshaInterval != nil {
-		params.IshaInterval = *config.IshaInterval
+		opts = append(opts, salah.WithIshaInterval(*config.IshaInterval))
 	}
 	if config.Madhab != nil {
-		params.Madhab = calc.AsrJuristicMethod(*config.Madhab)
+		opts = append(opts, salah.WithMadhab(salah.Madhab(*config.Madhab)))
 	}
 	if config.HighLatitudeRule != nil {
-		params.HighLatitudeRule = calc.HighLatitudeRule(*config.HighLatitudeRule)
+		opts = append(opts, salah.WithHighLatitudeRule(salah.HighLatitudeRule(*config.HighLatitudeRule)))
 	}
 	if config.Adjustments != nil {
-		params.Adjustments = *config.Adjustments
+		opts = append(opts, salah.WithAdjustments(toAdjustments(*config.Adjustments)))
 	}
 	if config.MethodAdjustments != nil {
-		params.MethodAdjustments = *config.MethodAdjustments
+		opts = append(opts, salah.WithMethodAdjustments(toAdjustments(*config.MethodAdjustments)))
+	}
+
+	return opts, nil
+}
+
+// BuildCalculator creates a prayer time calculator from the config
+func BuildCalculator(config *config.Config) (*salah.Calculator, error) {
+	opts, err := BuildOptions(config)
+	if err != nil {
+		return nil, err
 	}
+	return salah.New(opts...)
+}
 
-	return params, nil
+// IshaEnd returns when the Isha window ends as selected in the config
+func IshaEnd(config *config.Config) salah.IshaEnd {
+	if config.IshaEnd == "midnight" {
+		return salah.IshaEndMidnight
+	}
+	return salah.IshaEndFajr
+}
 
+func toAdjustments(a config.PrayerAdjustments) salah.Adjustments {
+	return salah.Adjustments{
+		Fajr:    a.FajrAdj,
+		Sunrise: a.SunriseAdj,
+		Dhuhr:   a.DhuhrAdj,
+		Asr:     a.AsrAdj,
+		Maghrib: a.MaghribAdj,
+		Isha:    a.IshaAdj,
+	}
 }
@@ -1,52 +1,56 @@
 package params
 
 import (
-	"reflect"
+	"os"
+	"path/filepath"
 	"salah-cli/internal/config"
+	"salah-cli/pkg/salah"
 	"testing"
-
-	calc "github.com/mnadev/adhango/pkg/calc"
 )
 
-func TestBuildCalculationParams_Defaults(t *testing.T) {
+func TestBuildCalculator_Defaults(t *testing.T) {
 	cfg := &config.Config{Latitude: 51.5, Longitude: -0.12}
 
-	params, err := BuildCalculationParams(cfg)
+	calculator, err := BuildCalculator(cfg)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if params == nil {
-		t.Fatal("expected params, got nil")
+	if calculator == nil {
+		t.Fatal("expected calculator, got nil")
 	}
 
-	if params.Method != calc.MOON_SIGHTING_COMMITTEE {
-		t.Errorf("expected default method %v, got %v", calc.MOON_SIGHTING_COMMITTEE, params.Method)
+	if calculator.Parameters().Method != salah.MethodMoonsightingCommittee {
+		t.Errorf("expected default method %v, got %v", salah.MethodMoonsightingCommittee, calculator.Parameters().Method)
+	}
+	if calculator.Latitude() != 51.5 || calculator.Longitude() != -0.12 {
+		t.Errorf("expected location 51.5,-0.12, got %v,%v", calculator.Latitude(), calculator.Longitude())
 	}
 }
 
-func TestBuildCalculationParams_WithMethod(t *testing.T) {
-	method := int(calc.MUSLIM_WORLD_LEAGUE)
+func TestBuildCalculator_WithMethod(t *testing.T) {
+	method := config.Method(salah.MethodMuslimWorldLeague)
 	cfg := &config.Config{Method: &method}
 
-	params, err := BuildCalculationParams(cfg)
+	calculator, err := BuildCalculator(cfg)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if params.Method != calc.MUSLIM_WORLD_LEAGUE {
-		t.Errorf("expected method %v, got %v", calc.MUSLIM_WORLD_LEAGUE, params.Method)
+	if calculator.Parameters().Method != salah.MethodMuslimWorldLeague {
+		t.Errorf("expected method %v, got %v", salah.MethodMuslimWorldLeague, calculator.Parameters().Method)
 	}
 }
 
-func TestBuildCalculationParams_AllOverrides(t *testing.T) {
-	method := int(calc.EGYPTIAN)
+func TestBuildCalculator_AllOverrides(t *testing.T) {
+	method := config.Method(salah.MethodEgyptian)
 	fajr := 18.5
 	isha := 17.0
 	interval := 90
-	madhab := int(calc.HANAFI)
-	highLat := int(calc.MIDDLE_OF_THE_NIGHT)
-	adj := calc.PrayerAdjustments{FajrAdj: 2, DhuhrAdj: 1}
+	madhab := config.Madhab(salah.MadhabHanafi)
+	highLat := config.HighLatitudeRule(salah.HighLatitudeMiddleOfTheNight)
+	adj := config.PrayerAdjustments{FajrAdj: 2, DhuhrAdj: 1}
+	want := salah.Adjustments{Fajr: 2, Dhuhr: 1}
 
 	cfg := &config.Config{
 		Method:            &method,
@@ -59,13 +63,14 @@ func TestBuildCalculationParams_AllOverrides(t *testing.T) {
 		MethodAdjustments: &adj,
 	}
 
-	params, err := BuildCalculationParams(cfg)
+	calculator, err := BuildCalculator(cfg)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	params := calculator.Parameters()
 
-	if params.Method != calc.EGYPTIAN {
-		t.Errorf("expected method %v, got %v", calc.EGYPTIAN, params.Method)
+	if params.Method != salah.MethodEgyptian {
+		t.Errorf("expected method %v, got %v", salah.MethodEgyptian, params.Method)
 	}
 	if params.FajrAngle != fajr {
 		t.Errorf("expected FajrAngle %v, got %v", fajr, params.FajrAngle)
@@ -76,37 +81,89 @@ func TestBuildCalculationParams_AllOverrides(t *testing.T) {
 	if params.IshaInterval != interval {
 		t.Errorf("expected IshaInterval %v, got %v", interval, params.IshaInterval)
 	}
-	if params.Madhab != calc.HANAFI {
-		t.Errorf("expected Madhab %v, got %v", calc.HANAFI, params.Madhab)
+	if params.Madhab != salah.MadhabHanafi {
+		t.Errorf("expected Madhab %v, got %v", salah.MadhabHanafi, params.Madhab)
 	}
-	if params.HighLatitudeRule != calc.MIDDLE_OF_THE_NIGHT {
-		t.Errorf("expected HighLatitudeRule %v, got %v", calc.MIDDLE_OF_THE_NIGHT, params.HighLatitudeRule)
+	if params.HighLatitudeRule != salah.HighLatitudeMiddleOfTheNight {
+		t.Errorf("expected HighLatitudeRule %v, got %v", salah.HighLatitudeMiddleOfTheNight, params.HighLatitudeRule)
 	}
-	if !reflect.DeepEqual(params.Adjustments, adj) {
-		t.Errorf("expected Adjustments %+v, got %+v", adj, params.Adjustments)
+	if params.Adjustments != want {
+		t.Errorf("expected Adjustments %+v, got %+v", want, params.Adjustments)
 	}
-	if !reflect.DeepEqual(params.MethodAdjustments, adj) {
-		t.Errorf("expected MethodAdjustments %+v, got %+v", adj, params.MethodAdjustments)
+	if params.MethodAdjustments != want {
+		t.Errorf("expected MethodAdjustments %+v, got %+v", want, params.MethodAdjustments)
 	}
 }
 
-func TestBuildCalculationParams_PartialOverrides(t *testing.T) {
+func TestBuildCalculator_PartialOverrides(t *testing.T) {
 	fajr := 19.0
-	highLat := int(calc.MIDDLE_OF_THE_NIGHT)
+	highLat := config.HighLatitudeRule(salah.HighLatitudeMiddleOfTheNight)
 	cfg := &config.Config{
 		FajrAngle:        &fajr,
 		HighLatitudeRule: &highLat,
 	}
 
-	params, err := BuildCalculationParams(cfg)
+	calculator, err := BuildCalculator(cfg)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	params := calculator.Parameters()
 
 	if params.FajrAngle != fajr {
 		t.Errorf("expected FajrAngle %v, got %v", fajr, params.FajrAngle)
 	}
-	if params.HighLatitudeRule != calc.MIDDLE_OF_THE_NIGHT {
-		t.Errorf("expected HighLatitudeRule %v, got %v", calc.MIDDLE_OF_THE_NIGHT, params.HighLatitudeRule)
+	if params.HighLatitudeRule != salah.HighLatitudeMiddleOfTheNight {
+		t.Errorf("expected HighLatitudeRule %v, got %v", salah.HighLatitudeMiddleOfTheNight, params.HighLatitudeRule)
+	}
+}
+
+func TestBuildCalculator_InvalidLocation(t *testing.T) {
+	cfg := &config.Config{Latitude: 95, Longitude: 0}
+
+	if _, err := BuildCalculator(cfg); err == nil {
+		t.Fatal("expected error for invalid latitude, got nil")
+	}
+}
+
+func TestBuildCalculator_Engines(t *testing.T) {
+	timetable := filepath.Join(t.TempDir(), "timetable.csv")
+	content := "date,fajr,sunrise,dhuhr,asr,maghrib,isha\n2025-08-27,04:10,06:05,13:05,16:45,19:58,21:30\n"
+	if err := os.WriteFile(timetable, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write timetable: %v", err)
+	}
+
+	tests := []struct {
+		cfg      config.Config
+		expected string
+	}{
+		{config.Config{}, "adhan"},
+		{config.Config{Engine: "native"}, "native"},
+		{config.Config{Engine: "timetable", TimetablePath: timetable}, "timetable"},
+	}
+	for _, tt := range tests {
+		calculator, err := BuildCalculator(&tt.cfg)
+		if err != nil {
+			t.Fatalf("unexpected error for engine %q: %v", tt.cfg.Engine, err)
+		}
+		if got := calculator.Engine().Name(); got != tt.expected {
+			t.Errorf("expected engine %s, got %s", tt.expected, got)
+		}
+	}
+
+	missing := &config.Config{Engine: "timetable", TimetablePath: filepath.Join(t.TempDir(), "missing.csv")}
+	if _, err := BuildCalculator(missing); err == nil {
+		t.Error("expected error for missing timetable, got nil")
+	}
+}
+
+func TestIshaEnd(t *testing.T) {
+	for value, want := range map[string]salah.IshaEnd{
+		"":         salah.IshaEndFajr,
+		"fajr":     salah.IshaEndFajr,
+		"midnight": salah.IshaEndMidnight,
+	} {
+		if got := IshaEnd(&config.Config{IshaEnd: value}); got != want {
+			t.Errorf("IshaEnd(%q) = %v, want %v", value, got, want)
+		}
 	}
 }
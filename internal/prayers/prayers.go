@@ -4,11 +4,9 @@ import (
 	"fmt"
 	"salah-cli/internal/config"
 	internalUtil "salah-cli/internal/util"
+	"salah-cli/pkg/salah"
+	"strings"
 	"time"
-
-	calc "github.com/mnadev/adhango/pkg/calc"
-	data "github.com/mnadev/adhango/pkg/data"
-	util "github.com/mnadev/adhango/pkg/util"
 )
 
 // Dependency injection for current time (can be overridden in tests)
@@ -17,70 +15,91 @@ var nowFunc = time.Now
 func highlight(text, color string) string {
 	code, ok := internalUtil.AnsiColors[color]
 	if !ok {
-		code = internalUtil.AnsiColors["green"] // default
+		code = internalUtil.AnsiColors[internalUtil.DefaultColour]
 	}
 	return code + text + internalUtil.AnsiColors["reset"]
 }
 
-// getPrayerTimesForDate returns prayer times for a given date (testable)
-func getPrayerTimesForDate(config *config.Config, params *calc.CalculationParameters, date time.Time) (*calc.PrayerTimes, error) {
-	coordinates, err := util.NewCoordinates(config.Latitude, config.Longitude)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialise coordinates: %w", err)
-	}
-	return calc.NewPrayerTimes(coordinates, data.NewDateComponents(date), params)
+// GetPrayerTimesForDate returns prayer times for a given date (testable)
+func GetPrayerTimesForDate(calculator *salah.Calculator, date time.Time) (*salah.Schedule, error) {
+	return calculator.ForDate(date)
 }
 
 // GetTodaysPrayerTimes returns today's prayer times using nowFunc (testable)
-func GetTodaysPrayerTimes(config *config.Config, params *calc.CalculationParameters) (*calc.PrayerTimes, error) {
-	return getPrayerTimesForDate(config, params, nowFunc())
+func GetTodaysPrayerTimes(calculator *salah.Calculator) (*salah.Schedule, error) {
+	return GetPrayerTimesForDate(calculator, nowFunc())
 }
 
 // getTomorrowsPrayerTimes returns tomorrow's prayer times using nowFunc (testable)
-func GetTomorrowsPrayerTimes(config *config.Config, params *calc.CalculationParameters) (*calc.PrayerTimes, error) {
-	return getPrayerTimesForDate(config, params, nowFunc().AddDate(0, 0, 1))
+func GetTomorrowsPrayerTimes(calculator *salah.Calculator) (*salah.Schedule, error) {
+	return GetPrayerTimesForDate(calculator, nowFunc().AddDate(0, 0, 1))
 }
 
 // formatPrayerTimes returns a string representation of daily prayer times (testable)
-func FormatPrayerTimes(times *calc.PrayerTimes, config *config.Config) string {
-	nowPrayer := times.CurrentPrayer(time.Now())
-	prayers := map[calc.Prayer]string{
-		calc.FAJR:    fmt.Sprintf("Fajr %s", times.Fajr.Local().Format("15:04")),
-		calc.SUNRISE: fmt.Sprintf("Sunrise %s", times.Sunrise.Local().Format("15:04")),
-		calc.DHUHR:   fmt.Sprintf("Dhuhr %s", times.Dhuhr.Local().Format("15:04")),
-		calc.ASR:     fmt.Sprintf("Asr %s", times.Asr.Local().Format("15:04")),
-		calc.MAGHRIB: fmt.Sprintf("Maghrib %s", times.Maghrib.Local().Format("15:04")),
-		calc.ISHA:    fmt.Sprintf("Isha %s", times.Isha.Local().Format("15:04")),
-	}
-	if config.EnableHighlighting {
+func FormatPrayerTimes(times *salah.Schedule, config *config.Config) string {
+	nowPrayer := times.CurrentPrayer(nowFunc())
+	prayers := make([]string, 0, len(salah.Prayers))
+	for _, p := range salah.Prayers {
+		entry := fmt.Sprintf("%s %s", p, times.Time(p).Format("15:04"))
+		if _, ok := times.Fallbacks[p]; ok {
+			entry += "*"
+		}
 		// Highlight the current prayer (name + time)
-		if nowPrayer != calc.NO_PRAYER {
-			prayers[nowPrayer] = highlight(prayers[nowPrayer], config.HighlightColour)
+		if config.EnableHighlighting && p == nowPrayer {
+			entry = highlight(entry, config.HighlightColour)
 		}
+		prayers = append(prayers, entry)
 	}
-	return fmt.Sprintf(
-		"%s | %s | %s | %s | %s | %s",
-		prayers[calc.FAJR],
-		prayers[calc.SUNRISE],
-		prayers[calc.DHUHR],
-		prayers[calc.ASR],
-		prayers[calc.MAGHRIB],
-		prayers[calc.ISHA],
-	)
+	return strings.Join(prayers, " | ") + formatFallbacks(times)
+}
+
+// formatFallbacks returns a note for each high latitude fallback applied, grouping prayers with the same reason
+func formatFallbacks(times *salah.Schedule) string {
+	var reasons []string
+	grouped := make(map[string][]string)
+	for _, p := range salah.Prayers {
+		reason, ok := times.Fallbacks[p]
+		if !ok {
+			continue
+		}
+		if _, seen := grouped[reason]; !seen {
+			reasons = append(reasons, reason)
+		}
+		grouped[reason] = append(grouped[reason], p.String())
+	}
+
+	var b strings.Builder
+	for _, reason := range reasons {
+		fmt.Fprintf(&b, "\n* %s: %s", strings.Join(grouped[reason], ", "), reason)
+	}
+	return b.String()
 }
 
 // NextPrayerInfo returns the name and time of the next upcoming prayer (testable)
-func NextPrayerInfo(timesToday, timesTomorrow *calc.PrayerTimes, loc *time.Location, prayerNames map[calc.Prayer]string) (string, time.Time, error) {
-	if nowFunc().Before(timesToday.Isha.Local()) {
-		nextPrayer := timesToday.NextPrayerNow()
-		if nextPrayer == calc.NO_PRAYER {
-			return "", time.Time{}, fmt.Errorf("no upcoming prayer found for today")
+func NextPrayerInfo(timesToday, timesTomorrow *salah.Schedule, loc *time.Location) (string, time.Time, error) {
+	now := nowFunc()
+	for _, p := range salah.Obligatory {
+		if now.Before(timesToday.Time(p)) {
+			return p.String(), timesToday.Time(p).In(loc), nil
 		}
-		return prayerNames[nextPrayer], timesToday.TimeForPrayer(nextPrayer).In(loc), nil
 	}
 
 	// No more prayers today; fallback to tomorrow's Fajr
-	return "Fajr", timesTomorrow.Fajr.Local(), nil
+	if timesTomorrow == nil || timesTomorrow.Fajr.IsZero() {
+		return "", time.Time{}, fmt.Errorf("no upcoming prayer found")
+	}
+	return salah.Fajr.String(), timesTomorrow.Fajr.In(loc), nil
+}
+
+// NextTimeOf returns the next time of prayer p, today or else tomorrow (testable)
+func NextTimeOf(p salah.Prayer, timesToday, timesTomorrow *salah.Schedule, loc *time.Location) (time.Time, error) {
+	if t := timesToday.Time(p); nowFunc().Before(t) {
+		return t.In(loc), nil
+	}
+	if timesTomorrow == nil || timesTomorrow.Time(p).IsZero() {
+		return time.Time{}, fmt.Errorf("no upcoming %s found", p)
+	}
+	return timesTomorrow.Time(p).In(loc), nil
 }
 
 func FormatNextPrayerInfo(name string, t time.Time, config *config.Config) string {
@@ -98,20 +117,43 @@ func FormatNextPrayerInfo(name string, t time.Time, config *config.Config) strin
 	return result
 }
 
+// CurrentWindow returns the prayer window active now (testable)
+func CurrentWindow(calculator *salah.Calculator, ishaEnd salah.IshaEnd) (salah.Window, error) {
+	return calculator.Window(nowFunc(), ishaEnd)
+}
+
+// FormatWindow returns a string representation of a prayer window with the share elapsed and the time remaining
+func FormatWindow(w salah.Window, loc *time.Location, config *config.Config) string {
+	now := nowFunc()
+	var result string
+	if w.Prayer == salah.NoPrayer {
+		result = fmt.Sprintf("No prayer due until %s %s", w.Next, w.End.In(loc).Format("15:04"))
+	} else {
+		result = fmt.Sprintf("%s %s–%s", w.Prayer, w.Start.In(loc).Format("15:04"), w.End.In(loc).Format("15:04"))
+	}
+	result = fmt.Sprintf("%s | %d%% elapsed | %s remaining", result, int(w.Elapsed(now)*100), formatDuration(w.Remaining(now)))
+	if config.EnableHighlighting && w.Prayer != salah.NoPrayer {
+		result = highlight(result, config.HighlightColour)
+	}
+	return result
+}
+
+// formatDuration returns d in hours and minutes, or seconds when under a minute
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%d sec", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%d min", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%d hr %d min", int(d.Hours()), int(d.Minutes())%60)
+}
+
 func formatCountdown(t time.Time) string {
 	now := nowFunc()
 	if t.Before(now) || t.Sub(now) < time.Second {
 		return "" // No countdown shown if it's now
 	}
 
-	diff := t.Sub(now)
-	if diff < time.Minute {
-		return fmt.Sprintf("in %d sec", int(diff.Seconds()))
-	} else if diff < time.Hour {
-		return fmt.Sprintf("in %d min", int(diff.Minutes()))
-	}
-
-	hours := int(diff.Hours())
-	minutes := int(diff.Minutes()) % 60
-	return fmt.Sprintf("in %d hr %d min", hours, minutes)
+	return "in " + formatDuration(t.Sub(now))
 }
@@ -3,21 +3,20 @@ package prayers
 import (
 	"salah-cli/internal/config"
 	"salah-cli/internal/params"
+	"salah-cli/pkg/salah"
 	"strings"
 	"testing"
 	"time"
-
-	calc "github.com/mnadev/adhango/pkg/calc"
 )
 
 func TestGetTodaysAndTomorrowsPrayerTimes(t *testing.T) {
 	cfg := &config.Config{Latitude: 51.5, Longitude: -0.12} // London
-	params, err := params.BuildCalculationParams(cfg)
+	calculator, err := params.BuildCalculator(cfg)
 	if err != nil {
-		t.Fatalf("failed to build params: %v", err)
+		t.Fatalf("failed to build calculator: %v", err)
 	}
 
-	today, err := GetTodaysPrayerTimes(cfg, params)
+	today, err := GetTodaysPrayerTimes(calculator)
 	if err != nil {
 		t.Fatalf("failed to get today's prayer times: %v", err)
 	}
@@ -25,7 +24,7 @@ func TestGetTodaysAndTomorrowsPrayerTimes(t *testing.T) {
 		t.Errorf("expected non-zero Fajr time")
 	}
 
-	tomorrow, err := GetTomorrowsPrayerTimes(cfg, params)
+	tomorrow, err := GetTomorrowsPrayerTimes(calculator)
 	if err != nil {
 		t.Fatalf("failed to get tomorrow's prayer times: %v", err)
 	}
@@ -36,31 +35,48 @@ func TestGetTodaysAndTomorrowsPrayerTimes(t *testing.T) {
 
 func TestFormatPrayerTimes(t *testing.T) {
 	cfg := &config.Config{Latitude: 51.5, Longitude: -0.12}
-	params, _ := params.BuildCalculationParams(cfg)
+	calculator, _ := params.BuildCalculator(cfg)
 
-	times, _ := GetTodaysPrayerTimes(cfg, params)
+	times, _ := GetTodaysPrayerTimes(calculator)
 	out := FormatPrayerTimes(times, cfg)
 	if !strings.Contains(out, "Fajr") || !strings.Contains(out, "Isha") {
 		t.Errorf("expected formatted string to contain prayer names, got %q", out)
 	}
 }
 
+func TestFormatPrayerTimes_Fallbacks(t *testing.T) {
+	cfg := &config.Config{}
+	date := time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC)
+	times := &salah.Schedule{
+		Date: date,
+		Fajr: date.Add(2 * time.Hour), Sunrise: date.Add(4 * time.Hour), Dhuhr: date.Add(13 * time.Hour),
+		Asr: date.Add(17 * time.Hour), Maghrib: date.Add(21 * time.Hour), Isha: date.Add(23 * time.Hour),
+		Fallbacks: map[salah.Prayer]string{
+			salah.Fajr: "seventh of the night (twilight persists all night)",
+			salah.Isha: "seventh of the night (twilight persists all night)",
+		},
+	}
+
+	out := FormatPrayerTimes(times, cfg)
+	if !strings.Contains(out, "Fajr 02:00*") || !strings.Contains(out, "Isha 23:00*") {
+		t.Errorf("expected fallback times to be marked, got %q", out)
+	}
+	if strings.Contains(out, "Dhuhr 13:00*") {
+		t.Errorf("expected Dhuhr not to be marked, got %q", out)
+	}
+	if !strings.Contains(out, "\n* Fajr, Isha: seventh of the night (twilight persists all night)") {
+		t.Errorf("expected grouped fallback note, got %q", out)
+	}
+}
+
 func TestNextPrayerInfo_TodayAndTomorrow(t *testing.T) {
 	cfg := &config.Config{Latitude: 51.5, Longitude: -0.12}
-	params, _ := params.BuildCalculationParams(cfg)
-
-	today, _ := GetTodaysPrayerTimes(cfg, params)
-	tomorrow, _ := GetTomorrowsPrayerTimes(cfg, params)
+	calculator, _ := params.BuildCalculator(cfg)
 
-	prayerNames := map[calc.Prayer]string{
-		calc.FAJR:    "Fajr",
-		calc.DHUHR:   "Dhuhr",
-		calc.ASR:     "Asr",
-		calc.MAGHRIB: "Maghrib",
-		calc.ISHA:    "Isha",
-	}
+	today, _ := GetTodaysPrayerTimes(calculator)
+	tomorrow, _ := GetTomorrowsPrayerTimes(calculator)
 
-	name, tNext, err := NextPrayerInfo(today, tomorrow, time.Local, prayerNames)
+	name, tNext, err := NextPrayerInfo(today, tomorrow, time.Local)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -82,20 +98,12 @@ func TestNextPrayerInfo_AtEndOfDay(t *testing.T) {
 	}
 
 	cfg := &config.Config{Latitude: 51.5, Longitude: -0.12}
-	params, _ := params.BuildCalculationParams(cfg)
-
-	today, _ := GetTodaysPrayerTimes(cfg, params)
-	tomorrow, _ := GetTomorrowsPrayerTimes(cfg, params)
+	calculator, _ := params.BuildCalculator(cfg)
 
-	prayerNames := map[calc.Prayer]string{
-		calc.FAJR:    "Fajr",
-		calc.DHUHR:   "Dhuhr",
-		calc.ASR:     "Asr",
-		calc.MAGHRIB: "Maghrib",
-		calc.ISHA:    "Isha",
-	}
+	today, _ := GetTodaysPrayerTimes(calculator)
+	tomorrow, _ := GetTomorrowsPrayerTimes(calculator)
 
-	name, tNext, err := NextPrayerInfo(today, tomorrow, time.UTC, prayerNames)
+	name, tNext, err := NextPrayerInfo(today, tomorrow, time.UTC)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -107,6 +115,32 @@ func TestNextPrayerInfo_AtEndOfDay(t *testing.T) {
 	}
 }
 
+func TestNextTimeOf(t *testing.T) {
+	originalNow := nowFunc
+	defer func() { nowFunc = originalNow }()
+	nowFunc = func() time.Time { return time.Date(2025, 8, 23, 14, 0, 0, 0, time.UTC) }
+
+	day := func(d int) *salah.Schedule {
+		date := time.Date(2025, 8, d, 0, 0, 0, 0, time.UTC)
+		return &salah.Schedule{
+			Date: date,
+			Fajr: date.Add(4 * time.Hour), Sunrise: date.Add(6 * time.Hour), Dhuhr: date.Add(13 * time.Hour),
+			Asr: date.Add(17 * time.Hour), Maghrib: date.Add(20 * time.Hour), Isha: date.Add(22 * time.Hour),
+		}
+	}
+	today, tomorrow := day(23), day(24)
+
+	if got, err := NextTimeOf(salah.Maghrib, today, tomorrow, time.UTC); err != nil || !got.Equal(today.Maghrib) {
+		t.Errorf("expected today's Maghrib, got %v %v", got, err)
+	}
+	if got, err := NextTimeOf(salah.Dhuhr, today, tomorrow, time.UTC); err != nil || !got.Equal(tomorrow.Dhuhr) {
+		t.Errorf("expected tomorrow's Dhuhr, got %v %v", got, err)
+	}
+	if _, err := NextTimeOf(salah.Fajr, today, nil, time.UTC); err == nil {
+		t.Errorf("expected an error without tomorrow's times")
+	}
+}
+
 func TestFormatCountdown(t *testing.T) {
 	// Override nowFunc for predictable testing
 	baseTime := time.Date(2025, 8, 27, 12, 0, 0, 0, time.UTC)
@@ -219,3 +253,36 @@ func TestFormatNextPrayerInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatWindow(t *testing.T) {
+	fixedNow := time.Date(2025, 8, 27, 16, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return fixedNow }
+
+	tests := []struct {
+		name     string
+		window   salah.Window
+		expected string
+	}{
+		{
+			name: "prayer window",
+			window: salah.Window{Prayer: salah.Asr, Next: salah.Maghrib,
+				Start: fixedNow.Add(-time.Hour), End: fixedNow.Add(3 * time.Hour)},
+			expected: "Asr 15:00–19:00 | 25% elapsed | 3 hr 0 min remaining",
+		},
+		{
+			name: "between windows",
+			window: salah.Window{Prayer: salah.NoPrayer, Next: salah.Dhuhr,
+				Start: fixedNow.Add(-90 * time.Minute), End: fixedNow.Add(30 * time.Minute)},
+			expected: "No prayer due until Dhuhr 16:30 | 75% elapsed | 30 min remaining",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatWindow(tt.window, time.UTC, &config.Config{})
+			if got != tt.expected {
+				t.Errorf("FormatWindow() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package publish
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"salah-cli/internal/config"
+	"time"
+)
+
+// MQTT 3.1.1 control packet types, in the high nibble of the fixed header
+const (
+	packetConnect    byte = 0x10
+	packetConnack    byte = 0x20
+	packetPublish    byte = 0x30
+	packetPuback     byte = 0x40
+	packetDisconnect byte = 0xe0
+)
+
+// mqttTimeout bounds each attempt to publish
+var mqttTimeout = 10 * time.Second
+
+// connackErrors are the reasons a broker refuses a connection
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// PublishMQTT connects to the broker, publishes a payload at QoS 1 and disconnects. Retained
+// payloads are sent to clients subscribing later. Failures other than the broker refusing the
+// client, such as a broker restarting, are retried with exponential backoff.
+func PublishMQTT(ctx context.Context, m *config.MQTT, topic string, payload []byte, retain bool) error {
+	addr, secure, err := m.Address()
+	if err != nil {
+		return err
+	}
+	return withRetries(ctx, func(ctx context.Context) (bool, error) {
+		attempt, cancel := context.WithTimeout(ctx, mqttTimeout)
+		defer cancel()
+		err := publishMQTT(attempt, m, addr, secure, topic, payload, retain)
+		var refused *refusedError
+		return ctx.Err() == nil && !errors.As(err, &refused), err
+	})
+}
+
+// refusedError is a connection refused by the broker, which retrying would not change unless
+// the broker is unavailable
+type refusedError struct {
+	broker string
+	code   byte
+}
+
+func (e *refusedError) Error() string {
+	return fmt.Sprintf("connecting to %s: refused: %s", e.broker, cmp.Or(connackErrors[e.code], fmt.Sprintf("code %d", e.code)))
+}
+
+// publishMQTT makes one attempt of PublishMQTT
+func publishMQTT(ctx context.Context, m *config.MQTT, addr string, secure bool, topic string, payload []byte, retain bool) error {
+	var err error
+
+	var conn net.Conn
+	if secure {
+		host, _, _ := net.SplitHostPort(addr)
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: host}}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", m.Broker, err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	// unblock reads and writes when the context is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	r := bufio.NewReader(conn)
+	if err := writePacket(conn, packetConnect, connectBody(m)); err != nil {
+		return fmt.Errorf("connecting to %s: %w", m.Broker, err)
+	}
+	header, body, err := readPacket(r)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", m.Broker, err)
+	}
+	if header&0xf0 != packetConnack || len(body) != 2 {
+		return fmt.Errorf("connecting to %s: unexpected packet 0x%02x", m.Broker, header)
+	}
+	switch code := body[1]; code {
+	case 0:
+	case 3:
+		// the broker may be starting up, so this is retried
+		return fmt.Errorf("connecting to %s: %s", m.Broker, connackErrors[code])
+	default:
+		return &refusedError{m.Broker, code}
+	}
+
+	const packetID = 1
+	flags := byte(0x02) // QoS 1
+	if retain {
+		flags |= 0x01
+	}
+	publish := appendString(nil, topic)
+	publish = binary.BigEndian.AppendUint16(publish, packetID)
+	publish = append(publish, payload...)
+	if err := writePacket(conn, packetPublish|flags, publish); err != nil {
+		return fmt.Errorf("publishing to %s: %w", topic, err)
+	}
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return fmt.Errorf("publishing to %s: %w", topic, err)
+		}
+		if header&0xf0 == packetPuback && len(body) == 2 && binary.BigEndian.Uint16(body) == packetID {
+			break
+		}
+	}
+	// the message is delivered, a failed disconnect doesn't matter
+	writePacket(conn, packetDisconnect, nil)
+	return nil
+}
+
+// connectBody returns the variable header and payload of a CONNECT packet
+func connectBody(m *config.MQTT) []byte {
+	flags := byte(0x02) // clean session
+	// MQTT 3.1.1 only allows a password along with a user name
+	password := m.Username != "" && m.Password != ""
+	if m.Username != "" {
+		flags |= 0x80
+	}
+	if password {
+		flags |= 0x40
+	}
+	b := appendString(nil, "MQTT")
+	b = append(b, 4, flags) // protocol level 4 is MQTT 3.1.1
+	b = binary.BigEndian.AppendUint16(b, 60)
+	b = appendString(b, cmp.Or(m.ClientID, config.AppName))
+	if m.Username != "" {
+		b = appendString(b, m.Username)
+	}
+	if password {
+		b = appendString(b, m.Password)
+	}
+	return b
+}
+
+// appendString appends a string prefixed with its length
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readString reads a string prefixed with its length, returning the rest of b
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 || len(b) < 2+int(binary.BigEndian.Uint16(b)) {
+		return "", nil, errors.New("malformed string")
+	}
+	n := 2 + int(binary.BigEndian.Uint16(b))
+	return string(b[2:n]), b[n:], nil
+}
+
+// writePacket writes a packet with its fixed header
+func writePacket(w io.Writer, header byte, body []byte) error {
+	b := []byte{header}
+	// the remaining length is encoded 7 bits at a time, the high bit marking more to come
+	for n := len(body); ; {
+		digit := byte(n % 128)
+		if n /= 128; n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			break
+		}
+	}
+	_, err := w.Write(append(b, body...))
+	return err
+}
+
+// readPacket reads a packet, returning its fixed header byte and the rest of the packet
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, shift := 0, 0
+	for {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length |= int(digit&0x7f) << shift
+		if digit&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
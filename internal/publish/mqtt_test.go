@@ -0,0 +1,192 @@
+package publish
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"salah-cli/internal/config"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testBroker is a minimal in-process MQTT broker recording what is published to it
+type testBroker struct {
+	addr string
+	// password is required from clients when set
+	password string
+
+	mu       sync.Mutex
+	clients  []string
+	messages []brokerMessage
+	retained map[string]string
+}
+
+type brokerMessage struct {
+	topic, payload string
+	retain         bool
+}
+
+func startBroker(t *testing.T, password string) *testBroker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	b := &testBroker{addr: ln.Addr().String(), password: password, retained: map[string]string{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *testBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header & 0xf0 {
+		case packetConnect:
+			_, rest, _ := readString(body) // protocol name
+			flags := rest[1]
+			clientID, rest, _ := readString(rest[4:])
+			var username, password string
+			if flags&0x80 != 0 {
+				username, rest, _ = readString(rest)
+			}
+			if flags&0x40 != 0 {
+				if flags&0x80 == 0 {
+					// MQTT 3.1.1 3.1.2.9: a password without a user name is a protocol violation
+					return
+				}
+				password, _, _ = readString(rest)
+			}
+			code := byte(0)
+			if b.password != "" && password != b.password {
+				code = 4
+			}
+			b.mu.Lock()
+			b.clients = append(b.clients, clientID+":"+username)
+			b.mu.Unlock()
+			writePacket(conn, packetConnack, []byte{0, code})
+			if code != 0 {
+				return
+			}
+		case packetPublish:
+			topic, rest, _ := readString(body)
+			retain := header&0x01 != 0
+			b.mu.Lock()
+			b.messages = append(b.messages, brokerMessage{topic, string(rest[2:]), retain})
+			if retain {
+				b.retained[topic] = string(rest[2:])
+			}
+			b.mu.Unlock()
+			writePacket(conn, packetPuback, rest[:2])
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+func TestPublishMQTT(t *testing.T) {
+	b := startBroker(t, "secret")
+	m := &config.MQTT{Broker: "tcp://" + b.addr, Username: "ha", Password: "secret"}
+	if err := PublishMQTT(context.Background(), m, "salah-cli/next", []byte(`{"prayer":"Asr"}`), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := PublishMQTT(context.Background(), m, "salah-cli/event", []byte(`{"prayer":"Dhuhr"}`), false); err != nil {
+		t.Fatal(err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.messages) != 2 || b.messages[1] != (brokerMessage{"salah-cli/event", `{"prayer":"Dhuhr"}`, false}) {
+		t.Errorf("unexpected messages %+v", b.messages)
+	}
+	if b.retained["salah-cli/next"] != `{"prayer":"Asr"}` || len(b.retained) != 1 {
+		t.Errorf("expected the next prayer to be retained, got %v", b.retained)
+	}
+	if b.clients[0] != "salah-cli:ha" {
+		t.Errorf("expected the default client id and the user name, got %v", b.clients)
+	}
+}
+
+func TestPublishMQTT_Retries(t *testing.T) {
+	fastRetries(t)
+	b := startBroker(t, "")
+	// a broker restarting: the first connections are dropped before the broker answers
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for n := 0; ; n++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			if n < 2 {
+				conn.Close()
+				continue
+			}
+			go b.serve(conn)
+		}
+	}()
+
+	m := &config.MQTT{Broker: ln.Addr().String()}
+	if err := PublishMQTT(context.Background(), m, "salah-cli/next", []byte(`{}`), true); err != nil {
+		t.Fatalf("expected the retained next prayer published after retries, got %v", err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.retained["salah-cli/next"]; !ok {
+		t.Errorf("expected the next prayer retained, got %v", b.retained)
+	}
+}
+
+func TestPublishMQTT_Errors(t *testing.T) {
+	fastRetries(t)
+	b := startBroker(t, "secret")
+	err := PublishMQTT(context.Background(), &config.MQTT{Broker: b.addr, Username: "ha", Password: "wrong"}, "t", nil, false)
+	if err == nil || !strings.Contains(err.Error(), "bad user name or password") {
+		t.Errorf("expected the connection to be refused, got %v", err)
+	}
+	b.mu.Lock()
+	if len(b.clients) != 1 {
+		t.Errorf("expected a refused client not to retry, got %d attempts", len(b.clients))
+	}
+	b.mu.Unlock()
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+	if err := PublishMQTT(context.Background(), &config.MQTT{Broker: addr}, "t", nil, false); err == nil {
+		t.Error("expected an error without a broker")
+	}
+}
+
+func TestPacket_RemainingLength(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384} {
+		var buf bytes.Buffer
+		body := bytes.Repeat([]byte{'x'}, n)
+		if err := writePacket(&buf, packetPublish, body); err != nil {
+			t.Fatal(err)
+		}
+		header, got, err := readPacket(bufio.NewReader(&buf))
+		if err != nil || header != packetPublish || !bytes.Equal(got, body) {
+			t.Errorf("length %d: got header 0x%02x, %d bytes, %v", n, header, len(got), err)
+		}
+	}
+}
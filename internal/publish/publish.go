@@ -0,0 +1,124 @@
+package publish
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"salah-cli/internal/config"
+	"salah-cli/pkg/salah"
+	"sync"
+	"time"
+)
+
+// publishAttempts is how many times a message is sent to the broker or a webhook before giving up
+const publishAttempts = 5
+
+// retryDelay is the wait before the first retry, doubling after each attempt
+var retryDelay = time.Second
+
+// withRetries calls attempt until it succeeds, fails in a way not worth retrying or has been
+// made publishAttempts times, backing off exponentially between attempts
+func withRetries(ctx context.Context, attempt func(ctx context.Context) (retry bool, err error)) error {
+	delay := retryDelay
+	for n := 1; ; n++ {
+		retry, err := attempt(ctx)
+		if err == nil || !retry || n == publishAttempts {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// Message types
+const (
+	// TypePrayer messages are published when a prayer time is reached
+	TypePrayer = "prayer"
+	// TypeNext messages hold the next prayer, retained by the MQTT broker
+	TypeNext = "next"
+)
+
+// Message is published to the MQTT broker and posted to the webhooks as JSON
+type Message struct {
+	Type      string    `json:"type"`
+	Prayer    string    `json:"prayer"`
+	Time      time.Time `json:"time"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+}
+
+// Next returns the message of the next prayer after after
+func Next(calculator *salah.Calculator, after time.Time) (Message, error) {
+	p, t, err := calculator.Next(after)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Type:      TypeNext,
+		Prayer:    p.String(),
+		Time:      t,
+		Latitude:  calculator.Latitude(),
+		Longitude: calculator.Longitude(),
+	}, nil
+}
+
+// Publisher sends messages to the MQTT broker and webhooks of a config
+type Publisher struct {
+	MQTT     *config.MQTT
+	Webhooks []config.Webhook
+	Client   *http.Client
+}
+
+// New returns a publisher for the integrations of a config
+func New(cfg *config.Config) *Publisher {
+	return &Publisher{MQTT: cfg.MQTT, Webhooks: cfg.Webhooks, Client: http.DefaultClient}
+}
+
+// Configured reports whether there is anywhere to publish to
+func (p *Publisher) Configured() bool {
+	return p.MQTT != nil || len(p.Webhooks) > 0
+}
+
+// Topic returns the MQTT topic of a message: <topic>/event for prayers, <topic>/next for the
+// next prayer
+func (p *Publisher) Topic(m Message) string {
+	prefix := cmp.Or(p.MQTT.Topic, config.DefaultMQTTTopic)
+	if m.Type == TypeNext {
+		return prefix + "/next"
+	}
+	return prefix + "/event"
+}
+
+// Publish sends a message to the broker and every webhook concurrently, returning their errors
+func (p *Publisher) Publish(ctx context.Context, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(p.Webhooks)+1)
+	var wg sync.WaitGroup
+	if p.MQTT != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[0] = PublishMQTT(ctx, p.MQTT, p.Topic(m), body, m.Type == TypeNext)
+		}()
+	}
+	for i, w := range p.Webhooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i+1] = PostWebhook(ctx, p.Client, w, body)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
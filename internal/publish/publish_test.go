@@ -0,0 +1,66 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"salah-cli/internal/config"
+	"salah-cli/pkg/salah/salahtest"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	calculator := salahtest.Calculator(t)
+	m, err := Next(calculator, time.Date(2025, 3, 9, 21, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Message{Type: TypeNext, Prayer: "Fajr", Time: time.Date(2025, 3, 10, 5, 0, 0, 0, time.UTC), Latitude: salahtest.Latitude, Longitude: salahtest.Longitude}
+	if m != want {
+		t.Errorf("got %+v, want %+v", m, want)
+	}
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	b := startBroker(t, "")
+	posted := make(chan Message, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m Message
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Error(err)
+		}
+		posted <- m
+	}))
+	defer server.Close()
+
+	p := New(&config.Config{
+		MQTT:     &config.MQTT{Broker: b.addr, Topic: "home/prayer"},
+		Webhooks: []config.Webhook{{URL: server.URL}},
+	})
+	m := Message{Type: TypePrayer, Prayer: "Maghrib", Time: time.Date(2025, 3, 9, 19, 0, 0, 0, time.UTC)}
+	if err := p.Publish(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-posted; got.Prayer != "Maghrib" || !got.Time.Equal(m.Time) {
+		t.Errorf("unexpected webhook message %+v", got)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.messages) != 1 || b.messages[0].topic != "home/prayer/event" || b.messages[0].retain {
+		t.Errorf("expected an unretained event, got %+v", b.messages)
+	}
+}
+
+func TestPublisher_Errors(t *testing.T) {
+	fastRetries(t)
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	p := New(&config.Config{Webhooks: []config.Webhook{{URL: server.URL}, {URL: server.URL + "/other"}}})
+	if err := p.Publish(context.Background(), Message{Type: TypeNext}); err == nil {
+		t.Error("expected the failing webhooks to be reported")
+	}
+}
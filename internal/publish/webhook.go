@@ -0,0 +1,69 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"salah-cli/internal/config"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the body of a webhook with a secret
+const SignatureHeader = "X-Salah-Signature"
+
+// webhookTimeout bounds each attempt to post a webhook, so one that never answers cannot hold
+// up the events after it
+var webhookTimeout = 10 * time.Second
+
+// Sign returns the signature of a body, as sent in SignatureHeader: "sha256=" followed by the
+// hex HMAC-SHA256 of the body keyed with the secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PostWebhook posts a JSON body to a webhook, signed when it has a secret. Network errors,
+// timeouts, 429 and 5xx responses are retried with exponential backoff.
+func PostWebhook(ctx context.Context, client *http.Client, w config.Webhook, body []byte) error {
+	err := withRetries(ctx, func(ctx context.Context) (bool, error) {
+		attempt, cancel := context.WithTimeout(ctx, webhookTimeout)
+		defer cancel()
+		retry, err := post(attempt, client, w, body)
+		// a timed out attempt is retried, unless it is ctx that is done
+		return retry || (err != nil && ctx.Err() == nil && attempt.Err() != nil), err
+	})
+	if err != nil {
+		return fmt.Errorf("posting to %s: %w", w.URL, err)
+	}
+	return nil
+}
+
+// post posts the body once, returning whether a failure is worth retrying
+func post(ctx context.Context, client *http.Client, w config.Webhook, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", config.AppName)
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
@@ -0,0 +1,102 @@
+package publish
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"salah-cli/internal/config"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries shortens the backoff of webhooks and MQTT
+func fastRetries(t *testing.T) {
+	t.Helper()
+	original := retryDelay
+	t.Cleanup(func() { retryDelay = original })
+	retryDelay = time.Millisecond
+}
+
+func TestSign(t *testing.T) {
+	// from the HMAC-SHA256 test vectors of RFC 4231
+	got := Sign("Jefe", []byte("what do ya want for nothing?"))
+	if want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestPostWebhook_Retries(t *testing.T) {
+	fastRetries(t)
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign("s3cret", body) || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	err := PostWebhook(context.Background(), server.Client(), config.Webhook{URL: server.URL, Secret: "s3cret"}, []byte(`{}`))
+	if err != nil || attempts.Load() != 3 {
+		t.Errorf("expected success on the third attempt, got %v after %d", err, attempts.Load())
+	}
+}
+
+func TestPostWebhook_Failures(t *testing.T) {
+	fastRetries(t)
+	var attempts atomic.Int32
+	status := http.StatusBadRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		if r.Header.Get(SignatureHeader) != "" {
+			t.Error("expected no signature without a secret")
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	hook := config.Webhook{URL: server.URL}
+
+	err := PostWebhook(context.Background(), server.Client(), hook, nil)
+	if err == nil || !strings.Contains(err.Error(), "400") || attempts.Load() != 1 {
+		t.Errorf("expected a client error without retries, got %v after %d", err, attempts.Load())
+	}
+
+	attempts.Store(0)
+	status = http.StatusTooManyRequests
+	err = PostWebhook(context.Background(), server.Client(), hook, nil)
+	if err == nil || attempts.Load() != publishAttempts {
+		t.Errorf("expected %d attempts, got %v after %d", publishAttempts, err, attempts.Load())
+	}
+}
+
+func TestPostWebhook_Timeout(t *testing.T) {
+	fastRetries(t)
+	original := webhookTimeout
+	t.Cleanup(func() { webhookTimeout = original })
+	webhookTimeout = 20 * time.Millisecond
+
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt never gets an answer
+		if attempts.Add(1) == 1 {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// the default client has no timeout of its own
+	if err := PostWebhook(context.Background(), http.DefaultClient, config.Webhook{URL: server.URL}, nil); err != nil || attempts.Load() != 2 {
+		t.Errorf("expected success once the hanging attempt timed out, got %v after %d", err, attempts.Load())
+	}
+}
@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// run runs a command with input on its stdin and returns its stdout (overridden in tests)
+var run = func(input, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return stdout.String(), fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// InstallCron replaces the jobs previously installed in the user's crontab with jobs, keeping
+// the other entries
+func InstallCron(jobs []Job, command string) error {
+	current, err := run("", "crontab", "-l")
+	if err != nil {
+		if !strings.Contains(err.Error(), "no crontab") {
+			return err
+		}
+		current = ""
+	}
+	_, err = run(replaceBlock(current, Crontab(jobs, command)), "crontab", "-")
+	return err
+}
+
+// replaceBlock replaces the lines between the Marker comments of crontab with block, or
+// appends block when there are none
+func replaceBlock(crontab, block string) string {
+	begin, end := "# BEGIN "+Marker+"\n", "# END "+Marker+"\n"
+	if i := strings.Index(crontab, begin); i >= 0 {
+		if j := strings.Index(crontab[i:], end); j >= 0 {
+			return crontab[:i] + block + crontab[i+j+len(end):]
+		}
+	}
+	if crontab != "" && !strings.HasSuffix(crontab, "\n") {
+		crontab += "\n"
+	}
+	return crontab + block
+}
+
+// InstallSystemd writes the timer and service units to dir, a systemd user unit directory,
+// and (re)starts the timer
+func InstallSystemd(dir string, jobs []Job, command string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	units := map[string]string{
+		UnitName + ".timer":   SystemdTimer(jobs),
+		UnitName + ".service": SystemdService(command),
+	}
+	for name, content := range units {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	for _, args := range [][]string{
+		{"--user", "daemon-reload"},
+		{"--user", "enable", UnitName + ".timer"},
+		{"--user", "restart", UnitName + ".timer"},
+	} {
+		if _, err := run("", "systemctl", args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InstallAt removes the pending at jobs tagged with Marker and submits jobs. It returns the
+// number of jobs removed.
+func InstallAt(jobs []Job, command string) (int, error) {
+	queue, err := run("", "atq")
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, line := range strings.Split(queue, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		script, err := run("", "at", "-c", fields[0])
+		if err != nil {
+			return removed, err
+		}
+		if !strings.Contains(script, "# "+Marker+":") {
+			continue
+		}
+		if _, err := run("", "atrm", fields[0]); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	for _, j := range jobs {
+		if _, err := run(atJob(j, command), "at", "-t", j.At.Format(atTimeLayout)); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRun replaces run, recording the commands and answering them with respond
+func fakeRun(t *testing.T, respond func(input, command string) (string, error)) *[]string {
+	t.Helper()
+	original := run
+	t.Cleanup(func() { run = original })
+	var calls []string
+	run = func(input, name string, args ...string) (string, error) {
+		command := strings.Join(append([]string{name}, args...), " ")
+		calls = append(calls, command)
+		return respond(input, command)
+	}
+	return &calls
+}
+
+func TestReplaceBlock(t *testing.T) {
+	block := "# BEGIN salah-cli schedule\nnew\n# END salah-cli schedule\n"
+	tests := map[string]struct{ crontab, want string }{
+		"empty":    {"", block},
+		"appended": {"@daily backup", "@daily backup\n" + block},
+		"replaced": {
+			"@daily backup\n# BEGIN salah-cli schedule\nold\n# END salah-cli schedule\n@reboot x\n",
+			"@daily backup\n" + block + "@reboot x\n",
+		},
+	}
+	for name, tt := range tests {
+		if got := replaceBlock(tt.crontab, block); got != tt.want {
+			t.Errorf("%s: got %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestInstallCron(t *testing.T) {
+	var written string
+	fakeRun(t, func(input, command string) (string, error) {
+		if command == "crontab -l" {
+			return "", errors.New("crontab: exit status 1: no crontab for user")
+		}
+		written = input
+		return "", nil
+	})
+	if err := InstallCron(testJobs, "true"); err != nil {
+		t.Fatal(err)
+	}
+	if written != Crontab(testJobs, "true") {
+		t.Errorf("expected the jobs to be written to an empty crontab, got %q", written)
+	}
+}
+
+func TestInstallSystemd(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "systemd", "user")
+	calls := fakeRun(t, func(input, command string) (string, error) { return "", nil })
+	if err := InstallSystemd(dir, testJobs, "true"); err != nil {
+		t.Fatal(err)
+	}
+	timer, err := os.ReadFile(filepath.Join(dir, UnitName+".timer"))
+	if err != nil || string(timer) != SystemdTimer(testJobs) {
+		t.Errorf("expected the timer to be written, got %q %v", timer, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, UnitName+".service")); err != nil {
+		t.Errorf("expected the service to be written: %v", err)
+	}
+	if last := (*calls)[len(*calls)-1]; last != "systemctl --user restart "+UnitName+".timer" {
+		t.Errorf("expected the timer to be restarted, got %v", *calls)
+	}
+}
+
+func TestInstallAt(t *testing.T) {
+	calls := fakeRun(t, func(input, command string) (string, error) {
+		switch command {
+		case "atq":
+			return "3\tSun Mar  9 19:00:00 2025 a user\n4\tSun Mar  9 22:00:00 2025 a user\n", nil
+		case "at -c 3":
+			return "#!/bin/sh\ncd /home/user\n# salah-cli schedule: Maghrib 2025-03-09 19:00\ntrue\n", nil
+		case "at -c 4":
+			return "#!/bin/sh\ncd /home/user\nbackup\n", nil
+		}
+		return "", nil
+	})
+	removed, err := InstallAt(testJobs, "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("expected the tagged job to be removed, removed %d", removed)
+	}
+	want := []string{"atq", "at -c 3", "atrm 3", "at -c 4", "at -t 202503091900", "at -t 202503092030"}
+	if strings.Join(*calls, ",") != strings.Join(want, ",") {
+		t.Errorf("got calls %v, want %v", *calls, want)
+	}
+}
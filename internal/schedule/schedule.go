@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"fmt"
+	"salah-cli/pkg/salah"
+	"strings"
+	"time"
+)
+
+// Formats lists the native schedulers jobs can be written for
+var Formats = []string{"cron", "systemd", "at"}
+
+// MaxDays bounds the days scheduled ahead. Crontab lines carry no year, so a longer schedule
+// would repeat dates.
+const MaxDays = 365
+
+// Marker tags the jobs written by salah-cli, so that installing them again replaces them
+const Marker = "salah-cli schedule"
+
+// UnitName names the systemd timer and service running the command
+const UnitName = "salah-cli-prayer"
+
+// Job is a run of the command at a prayer time
+type Job struct {
+	Prayer salah.Prayer
+	At     time.Time
+}
+
+// Jobs returns a job for each obligatory prayer after now over the next days, today included,
+// with times in loc
+func Jobs(calculator *salah.Calculator, now time.Time, days int, loc *time.Location) ([]Job, error) {
+	if days < 1 || days > MaxDays {
+		return nil, fmt.Errorf("invalid number of days %d, must be between 1 and %d", days, MaxDays)
+	}
+	var jobs []Job
+	for d := range days {
+		s, err := calculator.ForDate(now.AddDate(0, 0, d))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range salah.Obligatory {
+			if t := s.Time(p); t.After(now) {
+				jobs = append(jobs, Job{Prayer: p, At: t.In(loc)})
+			}
+		}
+	}
+	return jobs, nil
+}
+
+// Crontab returns the crontab lines running command for each job, between marker comments.
+// Crontab lines carry no year, so each line checks it and does nothing in the years after its
+// job's, when it would otherwise run again.
+func Crontab(jobs []Job, command string) string {
+	// an unescaped % ends the command in a crontab line
+	command = strings.ReplaceAll(command, "%", `\%`)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# BEGIN %s\n", Marker)
+	for _, j := range jobs {
+		fmt.Fprintf(&b, "%d %d %d %d * [ \"$(date +\\%%Y)\" = %d ] || exit 0; %s\n",
+			j.At.Minute(), j.At.Hour(), j.At.Day(), int(j.At.Month()), j.At.Year(), command)
+	}
+	fmt.Fprintf(&b, "# END %s\n", Marker)
+	return b.String()
+}
+
+// SystemdTimer returns a timer unit starting the service of SystemdService at each job
+func SystemdTimer(jobs []Job) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Written by %s, replaced on the next install\n", Marker)
+	b.WriteString("[Unit]\nDescription=Prayer times from salah-cli\n\n[Timer]\n")
+	for _, j := range jobs {
+		fmt.Fprintf(&b, "# %s\nOnCalendar=%s\n", j.Prayer, j.At.Format("2006-01-02 15:04:05"))
+	}
+	b.WriteString("AccuracySec=1s\n\n[Install]\nWantedBy=timers.target\n")
+	return b.String()
+}
+
+// SystemdService returns a oneshot service unit running command with /bin/sh
+func SystemdService(command string) string {
+	// systemd expands specifiers (%) and variables ($) in ExecStart, even within quotes
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(command)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Written by %s, replaced on the next install\n", Marker)
+	b.WriteString("[Unit]\nDescription=Run a command at prayer times from salah-cli\n\n[Service]\nType=oneshot\n")
+	fmt.Fprintf(&b, "ExecStart=/bin/sh -c \"%s\"\n", escaped)
+	return b.String()
+}
+
+// atTimeLayout is the layout of 'at -t'
+const atTimeLayout = "200601021504"
+
+// atJob returns the script of an at job running command, tagged with Marker
+func atJob(j Job, command string) string {
+	return fmt.Sprintf("# %s: %s %s\n%s\n", Marker, j.Prayer, j.At.Format("2006-01-02 15:04"), command)
+}
+
+// AtScript returns a shell script submitting an at job running command for each job
+func AtScript(jobs []Job, command string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#!/bin/sh\n# %s: at jobs, submit with sh\n", Marker)
+	for _, j := range jobs {
+		fmt.Fprintf(&b, "at -t %s <<'SALAH_CLI_JOB'\n%sSALAH_CLI_JOB\n", j.At.Format(atTimeLayout), atJob(j, command))
+	}
+	return b.String()
+}
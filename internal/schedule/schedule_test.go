@@ -0,0 +1,71 @@
+package schedule
+
+import (
+	"salah-cli/pkg/salah"
+	"salah-cli/pkg/salah/salahtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testJobs = []Job{
+	{Prayer: salah.Maghrib, At: time.Date(2025, 3, 9, 19, 0, 0, 0, time.UTC)},
+	{Prayer: salah.Isha, At: time.Date(2025, 3, 9, 20, 30, 0, 0, time.UTC)},
+}
+
+func TestJobs(t *testing.T) {
+	now := time.Date(2025, 3, 9, 17, 0, 0, 0, time.UTC)
+	jobs, err := Jobs(salahtest.Calculator(t), now, 2, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Maghrib and Isha today, the five prayers tomorrow
+	if len(jobs) != 7 {
+		t.Fatalf("expected 7 jobs, got %d: %v", len(jobs), jobs)
+	}
+	if jobs[0].Prayer != salah.Maghrib || !jobs[0].At.Equal(testJobs[0].At) {
+		t.Errorf("expected today's Maghrib first, got %v", jobs[0])
+	}
+	if jobs[2].Prayer != salah.Fajr || jobs[2].At.Day() != 10 {
+		t.Errorf("expected tomorrow's Fajr after today's Isha, got %v", jobs[2])
+	}
+
+	for _, days := range []int{0, MaxDays + 1} {
+		if _, err := Jobs(salahtest.Calculator(t), now, days, time.UTC); err == nil {
+			t.Errorf("expected an error for %d days", days)
+		}
+	}
+}
+
+func TestCrontab(t *testing.T) {
+	got := Crontab(testJobs, "notify-send 'Prayer' '100%'")
+	want := "# BEGIN salah-cli schedule\n" +
+		"0 19 9 3 * [ \"$(date +\\%Y)\" = 2025 ] || exit 0; notify-send 'Prayer' '100\\%'\n" +
+		"30 20 9 3 * [ \"$(date +\\%Y)\" = 2025 ] || exit 0; notify-send 'Prayer' '100\\%'\n" +
+		"# END salah-cli schedule\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSystemdUnits(t *testing.T) {
+	timer := SystemdTimer(testJobs)
+	for _, line := range []string{"OnCalendar=2025-03-09 19:00:00", "OnCalendar=2025-03-09 20:30:00", "WantedBy=timers.target"} {
+		if !strings.Contains(timer, line+"\n") {
+			t.Errorf("expected %q in timer:\n%s", line, timer)
+		}
+	}
+
+	service := SystemdService(`echo "$HOME" 100%`)
+	if !strings.Contains(service, `ExecStart=/bin/sh -c "echo \"$$HOME\" 100%%"`+"\n") {
+		t.Errorf("expected an escaped ExecStart, got:\n%s", service)
+	}
+}
+
+func TestAtScript(t *testing.T) {
+	got := AtScript(testJobs[:1], "./pause-builds.sh")
+	want := "at -t 202503091900 <<'SALAH_CLI_JOB'\n# salah-cli schedule: Maghrib 2025-03-09 19:00\n./pause-builds.sh\nSALAH_CLI_JOB\n"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("got\n%s\nwant suffix\n%s", got, want)
+	}
+}
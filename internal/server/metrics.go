@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"salah-cli/internal/params"
+	"salah-cli/pkg/salah"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricsContentType is the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelEscaper escapes label values in the exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsWriter builds a page of gauges in the Prometheus text exposition format
+type metricsWriter struct {
+	b strings.Builder
+}
+
+// gauge starts a gauge, whose samples must follow
+func (m *metricsWriter) gauge(name, help string) {
+	fmt.Fprintf(&m.b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+// sample writes a sample of the current gauge, labels being pairs of names and values
+func (m *metricsWriter) sample(name string, value float64, labels ...string) {
+	m.b.WriteString(name)
+	for i := 0; i+1 < len(labels); i += 2 {
+		sep := ","
+		if i == 0 {
+			sep = "{"
+		}
+		fmt.Fprintf(&m.b, `%s%s="%s"`, sep, labels[i], labelEscaper.Replace(labels[i+1]))
+	}
+	if len(labels) > 0 {
+		m.b.WriteByte('}')
+	}
+	fmt.Fprintf(&m.b, " %s\n", strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// unixSeconds returns t as seconds since the Unix epoch
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1000
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	calculator, err := s.calculatorFor(cfg)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	now := s.now().In(s.loc)
+	next, nextTime, err := calculator.Next(now)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	window, err := calculator.Window(now, params.IshaEnd(cfg))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	days := []time.Time{now, now.AddDate(0, 0, 1)}
+	schedules := make([]*salah.Schedule, len(days))
+	for i, d := range days {
+		if schedules[i], err = s.timesFor(cfg, d); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	var m metricsWriter
+	m.gauge("salah_next_prayer_timestamp_seconds", "Unix time of the next obligatory prayer.")
+	m.sample("salah_next_prayer_timestamp_seconds", unixSeconds(nextTime), "prayer", strings.ToLower(next.String()))
+
+	m.gauge("salah_seconds_until_next_prayer", "Seconds until the next obligatory prayer.")
+	m.sample("salah_seconds_until_next_prayer", nextTime.Sub(now).Seconds())
+
+	m.gauge("salah_prayer_time_seconds", "Unix time of each prayer today and tomorrow.")
+	for i, d := range days {
+		for _, p := range salah.Prayers {
+			m.sample("salah_prayer_time_seconds", unixSeconds(schedules[i].Time(p)), "prayer", strings.ToLower(p.String()), "date", d.Format(dateLayout))
+		}
+	}
+
+	m.gauge("salah_current_prayer", "1 for the prayer whose window is active, 0 for the others; all 0 between windows.")
+	for _, p := range salah.Obligatory {
+		active := 0.0
+		if p == window.Prayer {
+			active = 1
+		}
+		m.sample("salah_current_prayer", active, "prayer", strings.ToLower(p.String()))
+	}
+
+	m.gauge("salah_prayer_window_elapsed_ratio", "Share of the active prayer window elapsed, between 0 and 1.")
+	m.sample("salah_prayer_window_elapsed_ratio", window.Elapsed(now))
+
+	m.gauge("salah_prayer_window_remaining_seconds", "Seconds until the active prayer window, or the gap between windows, ends.")
+	m.sample("salah_prayer_window_remaining_seconds", window.Remaining(now).Seconds())
+
+	w.Header().Set("Content-Type", metricsContentType)
+	_, _ = w.Write([]byte(m.b.String()))
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// getMetrics returns the samples of /metrics by series
+func getMetrics(t *testing.T, url string) map[string]float64 {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != metricsContentType {
+		t.Fatalf("unexpected response %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	samples := map[string]float64{}
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		series, value, _ := strings.Cut(line, " ")
+		if samples[series], err = strconv.ParseFloat(value, 64); err != nil {
+			t.Fatalf("invalid sample %q", line)
+		}
+	}
+	return samples
+}
+
+func TestMetrics(t *testing.T) {
+	ts := newTestServer(t)
+	samples := getMetrics(t, ts.URL+"/metrics")
+
+	// 14:00 UTC in London is in the window of Dhuhr, before Asr
+	next, ok := samples[`salah_next_prayer_timestamp_seconds{prayer="asr"}`]
+	if !ok {
+		t.Fatalf("expected Asr to be the next prayer, got %v", samples)
+	}
+	now := 1756303200.0 // 2025-08-27 14:00 UTC
+	if until := samples["salah_seconds_until_next_prayer"]; until <= 0 || until != next-now {
+		t.Errorf("expected the seconds until Asr, got %v", until)
+	}
+	if samples[`salah_prayer_time_seconds{prayer="asr",date="2025-08-27"}`] != next {
+		t.Error("expected today's Asr time to match the next prayer")
+	}
+	if _, ok := samples[`salah_prayer_time_seconds{prayer="sunrise",date="2025-08-28"}`]; !ok {
+		t.Error("expected tomorrow's times")
+	}
+	if samples[`salah_current_prayer{prayer="dhuhr"}`] != 1 || samples[`salah_current_prayer{prayer="asr"}`] != 0 {
+		t.Errorf("expected Dhuhr to be current, got %v", samples)
+	}
+	if r := samples["salah_prayer_window_elapsed_ratio"]; r <= 0 || r >= 1 {
+		t.Errorf("expected part of the Dhuhr window to have elapsed, got %v", r)
+	}
+	if samples["salah_prayer_window_remaining_seconds"] != samples["salah_seconds_until_next_prayer"] {
+		t.Error("expected the Dhuhr window to end at Asr")
+	}
+}
+
+func TestMetrics_Overrides(t *testing.T) {
+	ts := newTestServer(t)
+	samples := getMetrics(t, ts.URL+"/metrics?lat=21.42&lon=39.83")
+	if _, ok := samples[`salah_prayer_time_seconds{prayer="fajr",date="2025-08-27"}`]; !ok {
+		t.Errorf("expected prayer times for Mecca, got %v", samples)
+	}
+
+	resp, err := http.Get(ts.URL + "/metrics?lat=200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a bad request, got %d", resp.StatusCode)
+	}
+}
+
+func TestMetricsWriter_EscapesLabels(t *testing.T) {
+	var m metricsWriter
+	m.sample("x", 1.5, "a", `q"b\`, "c", "d")
+	if got, want := m.b.String(), `x{a="q\"b\\",c="d"} 1.5`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,408 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"salah-cli/internal/calendar"
+	"salah-cli/internal/config"
+	"salah-cli/internal/params"
+	"salah-cli/pkg/salah"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// maxRangeDays bounds /v1/range and /v1/calendar.ics requests
+	maxRangeDays = 366
+	// defaultCalendarDays is used by /v1/calendar.ics when no range is given
+	defaultCalendarDays = 30
+	// maxCacheEntries bounds the number of computed days held in memory
+	maxCacheEntries = 4096
+	// shutdownTimeout is how long in-flight requests get to finish on shutdown
+	shutdownTimeout = 10 * time.Second
+)
+
+// DayResponse is the JSON representation of a single day's prayer times
+type DayResponse struct {
+	Date      string    `json:"date"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Method    int       `json:"method"`
+	Fajr      time.Time `json:"fajr"`
+	Sunrise   time.Time `json:"sunrise"`
+	Dhuhr     time.Time `json:"dhuhr"`
+	Asr       time.Time `json:"asr"`
+	Maghrib   time.Time `json:"maghrib"`
+	Isha      time.Time `json:"isha"`
+	// Fallbacks maps a prayer name to the high latitude fallback used to derive its time
+	Fallbacks map[string]string `json:"fallbacks,omitempty"`
+}
+
+// NextResponse is the JSON representation of the next upcoming prayer
+type NextResponse struct {
+	Prayer      string    `json:"prayer"`
+	Time        time.Time `json:"time"`
+	SecondsLeft int64     `json:"seconds_left"`
+}
+
+// RangeResponse is the JSON representation of several consecutive days
+type RangeResponse struct {
+	From string        `json:"from"`
+	To   string        `json:"to"`
+	Days []DayResponse `json:"days"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// cacheKey identifies a computed day for a given location and method
+type cacheKey struct {
+	lat, lon float64
+	method   int
+	date     string
+}
+
+// Server serves prayer times over HTTP
+type Server struct {
+	cfg *config.Config
+	loc *time.Location
+	now func() time.Time
+
+	mu    sync.Mutex
+	cache map[cacheKey]*salah.Schedule
+}
+
+// New creates a server answering requests from the given config
+func New(cfg *config.Config) *Server {
+	return &Server{
+		cfg:   cfg,
+		loc:   time.Local,
+		now:   time.Now,
+		cache: make(map[cacheKey]*salah.Schedule),
+	}
+}
+
+// Handler returns the HTTP routes served by the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/today", s.handleToday)
+	mux.HandleFunc("GET /v1/next", s.handleNext)
+	mux.HandleFunc("GET /v1/date/{date}", s.handleDate)
+	mux.HandleFunc("GET /v1/range", s.handleRange)
+	mux.HandleFunc("GET /v1/calendar.ics", s.handleCalendar)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return mux
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled, then shuts down gracefully
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down server: %w", err)
+		}
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// requestConfig applies lat/lon/method query overrides to a copy of the server config
+func (s *Server) requestConfig(r *http.Request) (*config.Config, error) {
+	cfg := *s.cfg
+	q := r.URL.Query()
+
+	if v := q.Get("lat"); v != "" {
+		lat, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lat '%s'", v)
+		}
+		cfg.Latitude = lat
+	}
+	if v := q.Get("lon"); v != "" {
+		lon, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lon '%s'", v)
+		}
+		cfg.Longitude = lon
+	}
+	if v := q.Get("method"); v != "" {
+		method, err := config.ParseMethod(v)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Method = &method
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// methodOf returns the effective calculation method for cfg
+func methodOf(cfg *config.Config) int {
+	if cfg.Method == nil {
+		return int(salah.DefaultMethod)
+	}
+	return int(*cfg.Method)
+}
+
+// calculatorFor returns a calculator for cfg in the server's time zone
+func (s *Server) calculatorFor(cfg *config.Config) (*salah.Calculator, error) {
+	opts, err := params.BuildOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	calculator, err := salah.New(append(opts, salah.WithTimezone(s.loc))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calculator: %w", err)
+	}
+	return calculator, nil
+}
+
+// timesFor returns the prayer times for date, computing and caching them if needed
+func (s *Server) timesFor(cfg *config.Config, date time.Time) (*salah.Schedule, error) {
+	key := cacheKey{lat: cfg.Latitude, lon: cfg.Longitude, method: methodOf(cfg), date: date.Format(dateLayout)}
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	calculator, err := s.calculatorFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	times, err := calculator.ForDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= maxCacheEntries {
+		s.cache = make(map[cacheKey]*salah.Schedule)
+	}
+	s.cache[key] = times
+	s.mu.Unlock()
+	return times, nil
+}
+
+func (s *Server) dayResponse(cfg *config.Config, date time.Time) (DayResponse, error) {
+	times, err := s.timesFor(cfg, date)
+	if err != nil {
+		return DayResponse{}, err
+	}
+	var fallbacks map[string]string
+	for p, reason := range times.Fallbacks {
+		if fallbacks == nil {
+			fallbacks = make(map[string]string)
+		}
+		fallbacks[strings.ToLower(p.String())] = reason
+	}
+	return DayResponse{
+		Date:      date.Format(dateLayout),
+		Latitude:  cfg.Latitude,
+		Longitude: cfg.Longitude,
+		Method:    methodOf(cfg),
+		Fajr:      times.Fajr,
+		Sunrise:   times.Sunrise,
+		Dhuhr:     times.Dhuhr,
+		Asr:       times.Asr,
+		Maghrib:   times.Maghrib,
+		Isha:      times.Isha,
+		Fallbacks: fallbacks,
+	}, nil
+}
+
+func (s *Server) parseDate(v string) (time.Time, error) {
+	t, err := time.ParseInLocation(dateLayout, v, s.loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", v)
+	}
+	// midday keeps the calendar date stable across DST transitions
+	return t.Add(12 * time.Hour), nil
+}
+
+// parseRange reads from/to query parameters, defaulting to today and defaultDays ahead
+func (s *Server) parseRange(r *http.Request, defaultDays int) (time.Time, time.Time, error) {
+	q := r.URL.Query()
+	from := s.now().In(s.loc)
+	if v := q.Get("from"); v != "" {
+		t, err := s.parseDate(v)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = t
+	}
+	to := from.AddDate(0, 0, defaultDays-1)
+	if v := q.Get("to"); v != "" {
+		t, err := s.parseDate(v)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = t
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("'to' must not be before 'from'")
+	}
+	if to.Sub(from) >= maxRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("range must not exceed %d days", maxRangeDays)
+	}
+	return from, to, nil
+}
+
+func (s *Server) handleToday(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	day, err := s.dayResponse(cfg, s.now().In(s.loc))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, day)
+}
+
+func (s *Server) handleDate(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	date, err := s.parseDate(r.PathValue("date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	day, err := s.dayResponse(cfg, date)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, day)
+}
+
+func (s *Server) handleNext(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	now := s.now().In(s.loc)
+	for _, date := range []time.Time{now, now.AddDate(0, 0, 1)} {
+		times, err := s.timesFor(cfg, date)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, p := range salah.Obligatory {
+			t := times.Time(p)
+			if t.After(now) {
+				writeJSON(w, NextResponse{
+					Prayer:      p.String(),
+					Time:        t,
+					SecondsLeft: int64(t.Sub(now).Seconds()),
+				})
+				return
+			}
+		}
+	}
+	writeError(w, http.StatusInternalServerError, fmt.Errorf("no upcoming prayer found"))
+}
+
+func (s *Server) handleRange(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	from, to, err := s.parseRange(r, 7)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp := RangeResponse{From: from.Format(dateLayout), To: to.Format(dateLayout)}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		day, err := s.dayResponse(cfg, d)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp.Days = append(resp.Days, day)
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	from, to, err := s.parseRange(r, defaultCalendarDays)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var events []calendar.Event
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		times, err := s.timesFor(cfg, d)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, p := range salah.Prayers {
+			events = append(events, calendar.Event{
+				UID:     fmt.Sprintf("%s-%s-%.4f-%.4f@salah-cli", strings.ToLower(p.String()), d.Format("20060102"), cfg.Latitude, cfg.Longitude),
+				Summary: p.String(),
+				Start:   times.Time(p),
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	_ = calendar.WriteICS(w, "-//salah-cli//prayer times//EN", events)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
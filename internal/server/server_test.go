@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"salah-cli/internal/config"
+	"salah-cli/pkg/salah"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	s := New(&config.Config{Latitude: 51.5, Longitude: -0.12}) // London
+	s.loc = time.UTC
+	s.now = func() time.Time { return time.Date(2025, 8, 27, 14, 0, 0, 0, time.UTC) }
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func getJSON(t *testing.T, url string, wantStatus int, out any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("expected status %d, got %d", wantStatus, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
+
+func TestToday(t *testing.T) {
+	ts := newTestServer(t)
+
+	var day DayResponse
+	getJSON(t, ts.URL+"/v1/today", http.StatusOK, &day)
+	if day.Date != "2025-08-27" {
+		t.Errorf("expected date 2025-08-27, got %s", day.Date)
+	}
+	if day.Fajr.IsZero() || !day.Fajr.Before(day.Isha) {
+		t.Errorf("unexpected prayer times: %+v", day)
+	}
+}
+
+func TestDate_WithOverrides(t *testing.T) {
+	ts := newTestServer(t)
+
+	var day DayResponse
+	getJSON(t, ts.URL+"/v1/date/2025-01-15?lat=21.42&lon=39.83&method=4", http.StatusOK, &day)
+	if day.Date != "2025-01-15" || day.Latitude != 21.42 || day.Method != 4 {
+		t.Errorf("overrides not applied: %+v", day)
+	}
+}
+
+func TestDate_Fallbacks(t *testing.T) {
+	rule := config.HighLatitudeRule(salah.HighLatitudeAuto)
+	s := New(&config.Config{Latitude: 69.65, Longitude: 18.96, HighLatitudeRule: &rule}) // Tromsø
+	s.loc = time.UTC
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+
+	var day DayResponse
+	getJSON(t, ts.URL+"/v1/date/2025-06-21", http.StatusOK, &day)
+	if !strings.Contains(day.Fallbacks["maghrib"], "nearest latitude") {
+		t.Errorf("expected nearest latitude fallback for maghrib, got %v", day.Fallbacks)
+	}
+
+	var equinox DayResponse
+	getJSON(t, ts.URL+"/v1/date/2025-03-20", http.StatusOK, &equinox)
+	if equinox.Fallbacks != nil {
+		t.Errorf("expected no fallbacks at the equinox, got %v", equinox.Fallbacks)
+	}
+}
+
+func TestDate_Invalid(t *testing.T) {
+	ts := newTestServer(t)
+
+	var errResp errorResponse
+	getJSON(t, ts.URL+"/v1/date/15-01-2025", http.StatusBadRequest, &errResp)
+	if errResp.Error == "" {
+		t.Error("expected error message")
+	}
+	getJSON(t, ts.URL+"/v1/today?lat=200", http.StatusBadRequest, &errResp)
+}
+
+func TestNext(t *testing.T) {
+	ts := newTestServer(t)
+
+	var next NextResponse
+	getJSON(t, ts.URL+"/v1/next", http.StatusOK, &next)
+	if next.Prayer != "Asr" && next.Prayer != "Maghrib" {
+		t.Errorf("expected Asr or Maghrib after 14:00 UTC in London, got %s", next.Prayer)
+	}
+	if next.SecondsLeft <= 0 {
+		t.Errorf("expected positive seconds left, got %d", next.SecondsLeft)
+	}
+}
+
+func TestRange(t *testing.T) {
+	ts := newTestServer(t)
+
+	var rng RangeResponse
+	getJSON(t, ts.URL+"/v1/range?from=2025-08-01&to=2025-08-03", http.StatusOK, &rng)
+	if len(rng.Days) != 3 || rng.Days[2].Date != "2025-08-03" {
+		t.Errorf("unexpected range: %+v", rng)
+	}
+
+	var errResp errorResponse
+	getJSON(t, ts.URL+"/v1/range?from=2025-08-03&to=2025-08-01", http.StatusBadRequest, &errResp)
+	getJSON(t, ts.URL+"/v1/range?from=2025-01-01&to=2026-06-01", http.StatusBadRequest, &errResp)
+}
+
+func TestCalendar(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/v1/calendar.ics?from=2025-08-01&to=2025-08-02")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if n := strings.Count(string(body), "BEGIN:VEVENT"); n != 12 {
+		t.Errorf("expected 12 events over 2 days, got %d", n)
+	}
+}
+
+func TestTimesFor_Caches(t *testing.T) {
+	s := New(&config.Config{Latitude: 51.5, Longitude: -0.12})
+	date := time.Date(2025, 8, 27, 12, 0, 0, 0, time.UTC)
+
+	first, err := s.timesFor(s.cfg, date)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second, _ := s.timesFor(s.cfg, date)
+	if first != second {
+		t.Error("expected cached prayer times to be reused")
+	}
+}
+
+func TestListenAndServe_GracefulShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ListenAndServe(ctx, addr, http.NotFoundHandler()) }()
+
+	// wait for the listener to come up
+	for i := 0; i < 50; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -0,0 +1,128 @@
+package travel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// localLayouts are the layouts of times without a UTC offset, read in the place's time zone
+var localLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// Place is an airport or city of an itinerary
+type Place struct {
+	Name      string  `yaml:"name"`
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+	// Timezone is the IANA zone of times without a UTC offset, e.g. Europe/London
+	Timezone string `yaml:"timezone"`
+}
+
+// Leg is a flight of an itinerary
+type Leg struct {
+	From Place `yaml:"from"`
+	To   Place `yaml:"to"`
+	// Depart and Arrive are RFC 3339 times, or local times in the zone of the place
+	Depart string `yaml:"depart"`
+	Arrive string `yaml:"arrive"`
+
+	departure, arrival time.Time
+}
+
+// Departure returns the departure time of the leg
+func (l Leg) Departure() time.Time { return l.departure }
+
+// Arrival returns the arrival time of the leg
+func (l Leg) Arrival() time.Time { return l.arrival }
+
+// Itinerary is a journey of consecutive legs
+type Itinerary struct {
+	Legs []Leg `yaml:"legs"`
+}
+
+// Parse reads an itinerary in YAML (or JSON), checking its places and that its legs follow
+// each other
+func Parse(r io.Reader) (*Itinerary, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var it Itinerary
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&it); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid itinerary: %s", strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	if len(it.Legs) == 0 {
+		return nil, fmt.Errorf("invalid itinerary: no legs")
+	}
+
+	for i := range it.Legs {
+		l := &it.Legs[i]
+		field := fmt.Sprintf("legs[%d]", i)
+		for _, p := range []struct {
+			field string
+			place Place
+		}{{field + ".from", l.From}, {field + ".to", l.To}} {
+			if err := p.place.check(p.field); err != nil {
+				return nil, err
+			}
+		}
+		if l.departure, err = parseTime(field+".depart", l.Depart, l.From); err != nil {
+			return nil, err
+		}
+		if l.arrival, err = parseTime(field+".arrive", l.Arrive, l.To); err != nil {
+			return nil, err
+		}
+		if !l.arrival.After(l.departure) {
+			return nil, fmt.Errorf("%s arrives at %s, not after it departs at %s", field, l.Arrive, l.Depart)
+		}
+		if i > 0 && l.departure.Before(it.Legs[i-1].arrival) {
+			return nil, fmt.Errorf("%s departs at %s, before legs[%d] arrives", field, l.Depart, i-1)
+		}
+	}
+	return &it, nil
+}
+
+// check returns an error for a place without a name or with invalid coordinates
+func (p Place) check(field string) error {
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		return fmt.Errorf("%s.name is required", field)
+	case p.Latitude < -90 || p.Latitude > 90:
+		return fmt.Errorf("%s.latitude %g must be between -90 and 90", field, p.Latitude)
+	case p.Longitude < -180 || p.Longitude > 180:
+		return fmt.Errorf("%s.longitude %g must be between -180 and 180", field, p.Longitude)
+	case p.Latitude == 0 && p.Longitude == 0:
+		return fmt.Errorf("%s needs a latitude and longitude", field)
+	}
+	return nil
+}
+
+// parseTime reads an RFC 3339 time, or a local time in the zone of the place
+func parseTime(field, value string, place Place) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("%s is required", field)
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if place.Timezone == "" {
+		return time.Time{}, fmt.Errorf("invalid %s '%s', expected an RFC 3339 time such as 2025-09-01T21:00:00+01:00, or a timezone for %s", field, value, place.Name)
+	}
+	loc, err := time.LoadLocation(place.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone '%s' of %s: %w", place.Timezone, place.Name, err)
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s '%s', expected a time such as 2025-09-01 21:00", field, value)
+}
@@ -0,0 +1,61 @@
+package travel
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const itinerary = `
+legs:
+  - from: {name: London, latitude: 51.47, longitude: -0.45, timezone: Europe/London}
+    to: {name: Dubai, latitude: 25.25, longitude: 55.36, timezone: Asia/Dubai}
+    depart: 2025-09-01 09:00
+    arrive: 2025-09-01 19:00
+  - from: {name: Dubai, latitude: 25.25, longitude: 55.36}
+    to: {name: Kuala Lumpur, latitude: 2.74, longitude: 101.7}
+    depart: 2025-09-01T17:00:00Z
+    arrive: 2025-09-02T01:00:00Z
+`
+
+func TestParse(t *testing.T) {
+	it, err := Parse(strings.NewReader(itinerary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(it.Legs) != 2 || it.Legs[1].To.Name != "Kuala Lumpur" {
+		t.Fatalf("unexpected itinerary %+v", it)
+	}
+	// local times are read in the zone of their place
+	if got := it.Legs[0].Departure(); !got.Equal(time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected a departure at 08:00 UTC, got %s", got)
+	}
+	if got := it.Legs[0].Arrival(); !got.Equal(time.Date(2025, 9, 1, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected an arrival at 15:00 UTC, got %s", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"depart: 2025-09-01T17:00:00Z", "depart: 2025-09-01T14:00:00Z", "before legs[0] arrives"},
+		{"name: London,", "name: ' ',", "legs[0].from.name is required"},
+		{"latitude: 51.47", "latitude: 151.47", "legs[0].from.latitude"},
+		{"timezone: Europe/London", "timezone: Europe/Nowhere", "invalid timezone"},
+		{"arrive: 2025-09-01 19:00", "arrive: 2025-09-01 10:00", "not after it departs"},
+		{"arrive: 2025-09-01 19:00", "arrive: tomorrow", "legs[0].arrive"},
+		{"depart: 2025-09-01T17:00:00Z", "depart: 2025-09-01 21:00", "or a timezone for Dubai"},
+		{"legs:", "flights:", "field flights not found"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(strings.Replace(itinerary, tt.from, tt.to, 1)))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error about %q, got %v", tt.to, tt.want, err)
+		}
+	}
+	if _, err := Parse(strings.NewReader("")); err == nil {
+		t.Error("expected an error for an itinerary without legs")
+	}
+}
@@ -0,0 +1,206 @@
+package travel
+
+import (
+	"salah-cli/pkg/salah"
+	"slices"
+	"time"
+)
+
+const (
+	// scanStep is the step at which prayer times are looked for along the route; prayer times
+	// move by far less than a step's worth while it is flown
+	scanStep = 5 * time.Minute
+	// tolerance is how close to the traveller's prayer time a time found must be, rejecting the
+	// jumps of high latitude rules
+	tolerance = time.Minute
+	// combineLimit bounds how far apart prayers combined together may be
+	combineLimit = 12 * time.Hour
+)
+
+// NewCalculator returns a calculator for a position, reporting schedules in UTC
+type NewCalculator func(latitude, longitude float64) (*salah.Calculator, error)
+
+// PrayerTime is an obligatory prayer time met on a journey, and where the traveller is then
+type PrayerTime struct {
+	Prayer salah.Prayer
+	Time   time.Time
+	Position
+	// CombineWith is the prayer on the ground a prayer met in flight may be combined with,
+	// before take-off or after landing; nil when there is none or combining was not asked for
+	CombineWith *PrayerTime
+}
+
+// Segment is a leg of a journey, or a layover between two legs, and the prayer times met during it
+type Segment struct {
+	// Leg is the index of the leg flown, or -1 for a layover at Place
+	Leg     int
+	Place   string
+	Start   time.Time
+	End     time.Time
+	Prayers []PrayerTime
+}
+
+// combinePartners are the prayers each prayer may be combined with, and whether that prayer
+// comes after it
+var combinePartners = map[salah.Prayer]struct {
+	prayer salah.Prayer
+	after  bool
+}{
+	salah.Dhuhr:   {salah.Asr, true},
+	salah.Asr:     {salah.Dhuhr, false},
+	salah.Maghrib: {salah.Isha, true},
+	salah.Isha:    {salah.Maghrib, false},
+}
+
+// Plan returns the legs and layovers of an itinerary with the obligatory prayer times met
+// during them. A prayer time is met when the prayer time of where the traveller is comes, the
+// route being flown along great circles at a constant speed. When combine is set, prayers met
+// in flight are paired with the prayer they may be combined with when it is on the ground.
+func Plan(it *Itinerary, newCalculator NewCalculator, combine bool) ([]Segment, error) {
+	start, end := it.Legs[0].departure, it.Legs[len(it.Legs)-1].arrival
+	// the prayers around the journey are looked for too, as partners to combine with
+	found, err := find(it, newCalculator, start.Add(-combineLimit), end.Add(combineLimit))
+	if err != nil {
+		return nil, err
+	}
+	if combine {
+		for i := range found {
+			if found[i].InFlight() {
+				found[i].CombineWith = partner(found, i)
+			}
+		}
+	}
+
+	var segments []Segment
+	for i, l := range it.Legs {
+		if i > 0 && l.departure.After(it.Legs[i-1].arrival) {
+			segments = append(segments, Segment{Leg: -1, Place: it.Legs[i-1].To.Name, Start: it.Legs[i-1].arrival, End: l.departure})
+		}
+		segments = append(segments, Segment{Leg: i, Start: l.departure, End: l.arrival})
+	}
+	for _, p := range found {
+		for i := range segments {
+			s := &segments[i]
+			if !p.Time.Before(s.Start) && p.Time.Before(s.End) {
+				s.Prayers = append(s.Prayers, p)
+				break
+			}
+		}
+	}
+	return segments, nil
+}
+
+// find returns the obligatory prayer times met from from to to, in order.
+//
+// The prayer times of the schedule of a UTC date shift as the traveller moves, so each is
+// followed along the route: it is met where it goes from being ahead of the traveller to being
+// behind them.
+func find(it *Itinerary, newCalculator NewCalculator, from, to time.Time) ([]PrayerTime, error) {
+	schedule := func(t, date time.Time) (*salah.Schedule, Position, error) {
+		pos := it.PositionAt(t)
+		calculator, err := newCalculator(pos.Latitude, pos.Longitude)
+		if err != nil {
+			return nil, pos, err
+		}
+		s, err := calculator.ForDate(date)
+		return s, pos, err
+	}
+
+	var found []PrayerTime
+	first := from.UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	for date := first; date.Before(to.AddDate(0, 0, 1)); date = date.AddDate(0, 0, 1) {
+		// the prayer times of a date are within a day of it at any longitude
+		scanFrom, scanTo := maxTime(from, date.AddDate(0, 0, -1)), minTime(to, date.AddDate(0, 0, 2))
+		if !scanFrom.Before(scanTo) {
+			continue
+		}
+		prev, _, err := schedule(scanFrom, date)
+		if err != nil {
+			return nil, err
+		}
+		for t := scanFrom; t.Before(scanTo); {
+			next := minTime(t.Add(scanStep), scanTo)
+			s, _, err := schedule(next, date)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range salah.Obligatory {
+				if !prev.Time(p).After(t) || s.Time(p).After(next) {
+					continue
+				}
+				// bisect to the time the prayer time of where the traveller is comes
+				lo, hi := t, next
+				for hi.Sub(lo) > time.Second {
+					mid := lo.Add(hi.Sub(lo) / 2)
+					m, _, err := schedule(mid, date)
+					if err != nil {
+						return nil, err
+					}
+					if m.Time(p).After(mid) {
+						lo = mid
+					} else {
+						hi = mid
+					}
+				}
+				at, pos, err := schedule(hi, date)
+				if err != nil {
+					return nil, err
+				}
+				if hi.Sub(at.Time(p)).Abs() > tolerance {
+					continue
+				}
+				found = append(found, PrayerTime{Prayer: p, Time: hi.Truncate(time.Second), Position: pos})
+			}
+			prev, t = s, next
+		}
+	}
+
+	slices.SortFunc(found, func(a, b PrayerTime) int { return a.Time.Compare(b.Time) })
+	// flying west faster than the sun, near the poles, a prayer time can come twice
+	var deduped []PrayerTime
+	for _, p := range found {
+		if !slices.ContainsFunc(deduped, func(d PrayerTime) bool { return d.Prayer == p.Prayer && p.Time.Sub(d.Time) < 2*time.Hour }) {
+			deduped = append(deduped, p)
+		}
+	}
+	return deduped, nil
+}
+
+// partner returns the prayer on the ground found[i] may be combined with, nil when there is none
+func partner(found []PrayerTime, i int) *PrayerTime {
+	want, ok := combinePartners[found[i].Prayer]
+	if !ok {
+		return nil
+	}
+	step := -1
+	if want.after {
+		step = 1
+	}
+	for j := i + step; j >= 0 && j < len(found); j += step {
+		p := found[j]
+		if p.Time.Sub(found[i].Time).Abs() > combineLimit {
+			return nil
+		}
+		if p.Prayer == want.prayer {
+			if p.InFlight() {
+				return nil
+			}
+			return &p
+		}
+	}
+	return nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,92 @@
+package travel
+
+import (
+	"salah-cli/pkg/salah"
+	"testing"
+	"time"
+)
+
+func calculators(engine salah.Engine) NewCalculator {
+	return func(latitude, longitude float64) (*salah.Calculator, error) {
+		return salah.New(salah.WithLocation(latitude, longitude), salah.WithTimezone(time.UTC), salah.WithEngine(engine))
+	}
+}
+
+func TestPlan(t *testing.T) {
+	fixed := salah.FixedEngine{
+		Fajr: 5 * time.Hour, Sunrise: 6*time.Hour + 30*time.Minute, Dhuhr: 13 * time.Hour,
+		Asr: 16*time.Hour + 30*time.Minute, Maghrib: 19 * time.Hour, Isha: 20*time.Hour + 30*time.Minute,
+	}
+	at := func(hour int) time.Time { return time.Date(2025, 8, 27, hour, 0, 0, 0, time.UTC) }
+	it := &Itinerary{Legs: []Leg{
+		{From: london, To: dubai, departure: at(10), arrival: at(14)},
+		{From: dubai, To: london, departure: at(18), arrival: at(22)},
+	}}
+
+	segments, err := Plan(it, calculators(fixed), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 3 || segments[1].Leg != -1 || segments[1].Place != "Dubai" {
+		t.Fatalf("expected two legs and a layover in Dubai, got %+v", segments)
+	}
+	dhuhr := segments[0].Prayers
+	if len(dhuhr) != 1 || dhuhr[0].Prayer != salah.Dhuhr || !dhuhr[0].Time.Equal(at(13)) || !dhuhr[0].InFlight() {
+		t.Fatalf("expected Dhuhr in flight at 13:00, got %+v", dhuhr)
+	}
+	// Dhuhr can be delayed to Asr in Dubai
+	if c := dhuhr[0].CombineWith; c == nil || c.Prayer != salah.Asr || c.Place != "Dubai" {
+		t.Errorf("expected Dhuhr combined with Asr in Dubai, got %+v", c)
+	}
+	if asr := segments[1].Prayers; len(asr) != 1 || asr[0].Prayer != salah.Asr || asr[0].InFlight() || asr[0].CombineWith != nil {
+		t.Errorf("expected Asr on the ground in Dubai, got %+v", asr)
+	}
+	// Maghrib and Isha are both in flight, with nothing on the ground to combine them with
+	last := segments[2].Prayers
+	if len(last) != 2 || last[0].Prayer != salah.Maghrib || last[1].Prayer != salah.Isha || last[0].CombineWith != nil || last[1].CombineWith != nil {
+		t.Errorf("expected Maghrib and Isha in flight, got %+v", last)
+	}
+
+	segments, err = Plan(it, calculators(fixed), false)
+	if err != nil || segments[0].Prayers[0].CombineWith != nil {
+		t.Errorf("expected no combining unless asked, got %+v, %v", segments, err)
+	}
+}
+
+func TestPlan_FollowsTheSun(t *testing.T) {
+	newYork := Place{Name: "New York", Latitude: 40.64, Longitude: -73.78}
+	it := &Itinerary{Legs: []Leg{{
+		From: london, To: newYork,
+		departure: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), arrival: time.Date(2025, 9, 1, 18, 0, 0, 0, time.UTC),
+	}}}
+	newCalculator := calculators(salah.AdhanEngine{})
+	segments, err := Plan(it, newCalculator, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dhuhr []PrayerTime
+	for _, p := range segments[0].Prayers {
+		if p.Prayer == salah.Dhuhr {
+			dhuhr = append(dhuhr, p)
+		}
+	}
+	if len(dhuhr) != 1 || !dhuhr[0].InFlight() {
+		t.Fatalf("expected Dhuhr once in flight, got %+v", segments[0].Prayers)
+	}
+
+	// flying west delays noon, between the noon of London and that of New York
+	noon := func(p Place) time.Time {
+		calculator, _ := newCalculator(p.Latitude, p.Longitude)
+		s, err := calculator.ForDate(it.Legs[0].departure)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.Time(salah.Dhuhr)
+	}
+	if got := dhuhr[0].Time; !got.After(noon(london)) || !got.Before(noon(newYork)) {
+		t.Errorf("expected Dhuhr between %s and %s, got %s", noon(london), noon(newYork), got)
+	}
+	if got, want := dhuhr[0].Time, noon(Place{Latitude: dhuhr[0].Latitude, Longitude: dhuhr[0].Longitude}); got.Sub(want).Abs() > time.Minute {
+		t.Errorf("expected Dhuhr at the noon of where it is met, %s, got %s", want, got)
+	}
+}
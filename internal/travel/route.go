@@ -0,0 +1,78 @@
+package travel
+
+import (
+	"math"
+	"time"
+)
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// Position is where a traveller is at a time
+type Position struct {
+	Latitude  float64
+	Longitude float64
+	// Leg is the index of the leg being flown, or -1 on the ground at Place
+	Leg   int
+	Place string
+}
+
+// InFlight reports whether the position is on a leg
+func (p Position) InFlight() bool { return p.Leg >= 0 }
+
+// vector returns the unit vector of a point on the sphere
+func vector(lat, lon float64) [3]float64 {
+	phi, lambda := lat*math.Pi/180, lon*math.Pi/180
+	return [3]float64{math.Cos(phi) * math.Cos(lambda), math.Cos(phi) * math.Sin(lambda), math.Sin(phi)}
+}
+
+// angle returns the angle between two points in radians
+func angle(a, b [3]float64) float64 {
+	cross := [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+	return math.Atan2(math.Hypot(math.Hypot(cross[0], cross[1]), cross[2]), a[0]*b[0]+a[1]*b[1]+a[2]*b[2])
+}
+
+// Distance returns the great-circle distance between two places in kilometres
+func Distance(from, to Place) float64 {
+	return angle(vector(from.Latitude, from.Longitude), vector(to.Latitude, to.Longitude)) * earthRadiusKm
+}
+
+// Interpolate returns the point a fraction f of the way from one place to another along the
+// great circle joining them
+func Interpolate(from, to Place, f float64) (lat, lon float64) {
+	a, b := vector(from.Latitude, from.Longitude), vector(to.Latitude, to.Longitude)
+	d := angle(a, b)
+	if d < 1e-9 {
+		return from.Latitude, from.Longitude
+	}
+	wa, wb := math.Sin((1-f)*d)/math.Sin(d), math.Sin(f*d)/math.Sin(d)
+	var p [3]float64
+	for i := range p {
+		p[i] = wa*a[i] + wb*b[i]
+	}
+	return math.Atan2(p[2], math.Hypot(p[0], p[1])) * 180 / math.Pi, math.Atan2(p[1], p[0]) * 180 / math.Pi
+}
+
+// PositionAt returns where the traveller is at t, assuming each leg is flown at a constant
+// speed. Before the first leg the traveller is at its origin, between legs at the airport of the
+// connection and after the last leg at its destination.
+func (it *Itinerary) PositionAt(t time.Time) Position {
+	first := it.Legs[0]
+	if t.Before(first.departure) {
+		return Position{Latitude: first.From.Latitude, Longitude: first.From.Longitude, Leg: -1, Place: first.From.Name}
+	}
+	for i, l := range it.Legs {
+		if t.Before(l.arrival) {
+			if t.Before(l.departure) {
+				// between legs, at the destination of the previous one
+				prev := it.Legs[i-1].To
+				return Position{Latitude: prev.Latitude, Longitude: prev.Longitude, Leg: -1, Place: prev.Name}
+			}
+			f := float64(t.Sub(l.departure)) / float64(l.arrival.Sub(l.departure))
+			lat, lon := Interpolate(l.From, l.To, f)
+			return Position{Latitude: lat, Longitude: lon, Leg: i}
+		}
+	}
+	last := it.Legs[len(it.Legs)-1].To
+	return Position{Latitude: last.Latitude, Longitude: last.Longitude, Leg: -1, Place: last.Name}
+}
@@ -0,0 +1,58 @@
+package travel
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	london = Place{Name: "London", Latitude: 51.47, Longitude: -0.45}
+	dubai  = Place{Name: "Dubai", Latitude: 25.25, Longitude: 55.36}
+)
+
+func TestDistance(t *testing.T) {
+	if d := Distance(london, dubai); math.Abs(d-5500) > 20 {
+		t.Errorf("expected about 5500 km from London to Dubai, got %.0f", d)
+	}
+	if d := Distance(london, london); d != 0 {
+		t.Errorf("expected no distance to the same place, got %f", d)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	lat, lon := Interpolate(london, dubai, 0.5)
+	mid := Place{Latitude: lat, Longitude: lon}
+	if a, b := Distance(london, mid), Distance(mid, dubai); math.Abs(a-b) > 1 || math.Abs(a+b-Distance(london, dubai)) > 1 {
+		t.Errorf("expected the midpoint on the great circle, got %f, %f (%.0f and %.0f km away)", lat, lon, a, b)
+	}
+	// the great circle runs north of the straight line on the map
+	if lat < (london.Latitude+dubai.Latitude)/2 {
+		t.Errorf("expected the midpoint north of %f, got %f", (london.Latitude+dubai.Latitude)/2, lat)
+	}
+	if lat, lon := Interpolate(london, dubai, 1); math.Abs(lat-dubai.Latitude) > 1e-9 || math.Abs(lon-dubai.Longitude) > 1e-9 {
+		t.Errorf("expected Dubai at the end, got %f, %f", lat, lon)
+	}
+}
+
+func TestPositionAt(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 9, 1, hour, 0, 0, 0, time.UTC) }
+	it := &Itinerary{Legs: []Leg{
+		{From: london, To: dubai, departure: at(8), arrival: at(14)},
+		{From: dubai, To: london, departure: at(16), arrival: at(22)},
+	}}
+	tests := []struct {
+		hour  int
+		leg   int
+		place string
+	}{{6, -1, "London"}, {8, 0, ""}, {11, 0, ""}, {14, -1, "Dubai"}, {15, -1, "Dubai"}, {19, 1, ""}, {23, -1, "London"}}
+	for _, tt := range tests {
+		p := it.PositionAt(at(tt.hour))
+		if p.Leg != tt.leg || p.Place != tt.place || p.InFlight() != (tt.leg >= 0) {
+			t.Errorf("%02d:00: unexpected position %+v", tt.hour, p)
+		}
+	}
+	if p := it.PositionAt(at(11)); math.Abs(Distance(london, Place{Latitude: p.Latitude, Longitude: p.Longitude})-Distance(london, dubai)/2) > 1 {
+		t.Errorf("expected to be halfway at 11:00, got %+v", p)
+	}
+}
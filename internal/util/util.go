@@ -1,5 +1,7 @@
 package util
 
+// DefaultColour is the highlight colour used when none, or an unknown one, is configured
+const DefaultColour = "green"
 
 var AnsiColors = map[string]string{
 	"black":   "\033[30m",
@@ -0,0 +1,119 @@
+package salah
+
+import (
+	"fmt"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// Coordinates is a position on Earth in decimal degrees
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Engine calculates the prayer times for a single day.
+//
+// date is midnight at the start of the requested day in the caller's time
+// zone. Engines are responsible for applying params.Adjustments (and
+// params.MethodAdjustments where meaningful) to the times they return.
+type Engine interface {
+	Name() string
+	Compute(date time.Time, coords Coordinates, params Parameters) (*Schedule, error)
+}
+
+// ApplyAdjustments shifts each time in s by the sum of the given per-prayer offsets
+func ApplyAdjustments(s *Schedule, adjustments ...Adjustments) {
+	for _, a := range adjustments {
+		s.Fajr = s.Fajr.Add(time.Duration(a.Fajr) * time.Minute)
+		s.Sunrise = s.Sunrise.Add(time.Duration(a.Sunrise) * time.Minute)
+		s.Dhuhr = s.Dhuhr.Add(time.Duration(a.Dhuhr) * time.Minute)
+		s.Asr = s.Asr.Add(time.Duration(a.Asr) * time.Minute)
+		s.Maghrib = s.Maghrib.Add(time.Duration(a.Maghrib) * time.Minute)
+		s.Isha = s.Isha.Add(time.Duration(a.Isha) * time.Minute)
+	}
+}
+
+// AdhanEngine calculates prayer times with github.com/mnadev/adhango
+type AdhanEngine struct{}
+
+// Name implements Engine
+func (AdhanEngine) Name() string { return "adhan" }
+
+// Compute implements Engine
+func (AdhanEngine) Compute(date time.Time, coords Coordinates, params Parameters) (*Schedule, error) {
+	c, err := util.NewCoordinates(coords.Latitude, coords.Longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := calc.GetMethodParameters(calc.CalculationMethod(params.Method))
+	cp.FajrAngle = params.FajrAngle
+	cp.IshaAngle = params.IshaAngle
+	cp.IshaInterval = params.IshaInterval
+	cp.Madhab = calc.AsrJuristicMethod(params.Madhab)
+	cp.HighLatitudeRule = calc.HighLatitudeRule(params.HighLatitudeRule)
+	cp.Adjustments = toCalcAdjustments(params.Adjustments)
+	cp.MethodAdjustments = toCalcAdjustments(params.MethodAdjustments)
+
+	times, err := calc.NewPrayerTimes(c, data.NewDateComponents(date), cp)
+	if err != nil {
+		return nil, fmt.Errorf("adhan: %w", err)
+	}
+	return &Schedule{
+		Date:    date,
+		Fajr:    times.Fajr,
+		Sunrise: times.Sunrise,
+		Dhuhr:   times.Dhuhr,
+		Asr:     times.Asr,
+		Maghrib: times.Maghrib,
+		Isha:    times.Isha,
+	}, nil
+}
+
+func toCalcAdjustments(a Adjustments) calc.PrayerAdjustments {
+	return calc.PrayerAdjustments{
+		FajrAdj:    a.Fajr,
+		SunriseAdj: a.Sunrise,
+		DhuhrAdj:   a.Dhuhr,
+		AsrAdj:     a.Asr,
+		MaghribAdj: a.Maghrib,
+		IshaAdj:    a.Isha,
+	}
+}
+
+// FixedEngine returns the same wall-clock offsets from midnight every day.
+// It is intended for tests that need predictable schedules.
+type FixedEngine struct {
+	Fajr    time.Duration
+	Sunrise time.Duration
+	Dhuhr   time.Duration
+	Asr     time.Duration
+	Maghrib time.Duration
+	Isha    time.Duration
+}
+
+// Name implements Engine
+func (FixedEngine) Name() string { return "fixed" }
+
+// Compute implements Engine
+func (e FixedEngine) Compute(date time.Time, _ Coordinates, params Parameters) (*Schedule, error) {
+	at := func(offset time.Duration) time.Time {
+		h, m, s := int(offset.Hours()), int(offset.Minutes())%60, int(offset.Seconds())%60
+		return time.Date(date.Year(), date.Month(), date.Day(), h, m, s, 0, date.Location())
+	}
+	s := &Schedule{
+		Date:    date,
+		Fajr:    at(e.Fajr),
+		Sunrise: at(e.Sunrise),
+		Dhuhr:   at(e.Dhuhr),
+		Asr:     at(e.Asr),
+		Maghrib: at(e.Maghrib),
+		Isha:    at(e.Isha),
+	}
+	ApplyAdjustments(s, params.Adjustments)
+	return s, nil
+}
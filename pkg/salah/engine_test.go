@@ -0,0 +1,141 @@
+package salah
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFixedEngine = FixedEngine{
+	Fajr:    5 * time.Hour,
+	Sunrise: 6*time.Hour + 30*time.Minute,
+	Dhuhr:   13 * time.Hour,
+	Asr:     16*time.Hour + 30*time.Minute,
+	Maghrib: 19 * time.Hour,
+	Isha:    20*time.Hour + 30*time.Minute,
+}
+
+func TestFixedEngine(t *testing.T) {
+	c := londonCalculator(t, WithEngine(testFixedEngine), WithAdjustments(Adjustments{Asr: 2}))
+	if c.Engine().Name() != "fixed" {
+		t.Errorf("expected fixed engine, got %s", c.Engine().Name())
+	}
+
+	s, err := c.ForDate(time.Date(2025, 8, 27, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := s.Fajr.Format("15:04"); got != "05:00" {
+		t.Errorf("expected Fajr 05:00, got %s", got)
+	}
+	if got := s.Asr.Format("15:04"); got != "16:32" {
+		t.Errorf("expected adjusted Asr 16:32, got %s", got)
+	}
+}
+
+func TestNativeEngine_MatchesAdhan(t *testing.T) {
+	locations := []struct {
+		name     string
+		lat, lon float64
+		method   Method
+	}{
+		{"London", 51.5074, -0.1278, MethodMuslimWorldLeague},
+		{"Makkah", 21.4225, 39.8262, MethodUmmAlQura},
+		{"New York", 40.7128, -74.0060, MethodNorthAmerica},
+		{"Jakarta", -6.2088, 106.8456, MethodSingapore},
+	}
+	dates := []time.Time{
+		time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, l := range locations {
+		opts := []Option{WithLocation(l.lat, l.lon), WithMethod(l.method), WithTimezone(time.UTC)}
+		adhan, _ := New(append(opts, WithEngine(AdhanEngine{}))...)
+		native, _ := New(append(opts, WithEngine(NativeEngine{}))...)
+
+		for _, d := range dates {
+			want, err := adhan.ForDate(d)
+			if err != nil {
+				t.Fatalf("%s: adhan failed: %v", l.name, err)
+			}
+			got, err := native.ForDate(d)
+			if err != nil {
+				t.Fatalf("%s: native failed: %v", l.name, err)
+			}
+			for _, p := range Prayers {
+				diff := got.Time(p).Sub(want.Time(p))
+				if diff < -3*time.Minute || diff > 3*time.Minute {
+					t.Errorf("%s %s %s: native %s differs from adhan %s by %v",
+						l.name, d.Format(time.DateOnly), p, got.Time(p).Format("15:04"), want.Time(p).Format("15:04"), diff)
+				}
+			}
+		}
+	}
+}
+
+func TestNativeEngine_PolarDay(t *testing.T) {
+	c := londonCalculator(t, WithEngine(NativeEngine{}))
+	c.coords = Coordinates{Latitude: 78.2232, Longitude: 15.6267} // Longyearbyen
+	if _, err := c.ForDate(time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Fatal("expected error when the sun never sets, got nil")
+	}
+}
+
+const testTimetable = `date,fajr,sunrise,dhuhr,asr,maghrib,isha
+# published by the local mosque
+2025-08-27,04:10,06:05,13:05,16:45,19:58,21:30
+2025-08-28,04:12,06:07,13:05,16:44,19:56,21:28
+`
+
+func TestTimetableEngine(t *testing.T) {
+	tt, err := ParseTimetable(strings.NewReader(testTimetable))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	london, _ := time.LoadLocation("Europe/London")
+	c := londonCalculator(t, WithEngine(tt), WithTimezone(london), WithAdjustments(Adjustments{Isha: 5}))
+
+	s, err := c.ForDate(time.Date(2025, 8, 28, 12, 0, 0, 0, london))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := s.Dhuhr.Format("15:04"); got != "13:05" {
+		t.Errorf("expected Dhuhr 13:05 (no method adjustment), got %s", got)
+	}
+	if got := s.Isha.Format("15:04"); got != "21:33" {
+		t.Errorf("expected adjusted Isha 21:33, got %s", got)
+	}
+
+	if _, err := c.ForDate(time.Date(2025, 9, 1, 12, 0, 0, 0, london)); err == nil {
+		t.Error("expected error for date missing from timetable, got nil")
+	}
+}
+
+func TestParseTimetable_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"bad header": "day,fajr,sunrise,dhuhr,asr,maghrib,isha\n2025-08-27,04:10,06:05,13:05,16:45,19:58,21:30\n",
+		"bad date":   "date,fajr,sunrise,dhuhr,asr,maghrib,isha\n27/08/2025,04:10,06:05,13:05,16:45,19:58,21:30\n",
+		"bad time":   "date,fajr,sunrise,dhuhr,asr,maghrib,isha\n2025-08-27,4am,06:05,13:05,16:45,19:58,21:30\n",
+		"empty":      "date,fajr,sunrise,dhuhr,asr,maghrib,isha\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseTimetable(strings.NewReader(input)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestMethodParameters(t *testing.T) {
+	p := MethodParameters(MethodUmmAlQura)
+	if p.FajrAngle != 18.5 || p.IshaInterval != 90 {
+		t.Errorf("unexpected Umm al-Qura parameters: %+v", p)
+	}
+	if p.HighLatitudeRule != HighLatitudeMiddleOfTheNight {
+		t.Errorf("expected default high latitude rule, got %v", p.HighLatitudeRule)
+	}
+}
@@ -0,0 +1,82 @@
+package salah_test
+
+import (
+	"fmt"
+	"time"
+
+	"salah-cli/pkg/salah"
+)
+
+func ExampleForDate() {
+	schedule, err := salah.ForDate(time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC),
+		salah.WithLocation(51.5074, -0.1278),
+		salah.WithMethod(salah.MethodMuslimWorldLeague),
+		salah.WithTimezone(time.UTC),
+	)
+	if err != nil {
+		panic(err)
+	}
+	for _, p := range salah.Prayers {
+		fmt.Printf("%-7s %s\n", p, schedule.Time(p).Format("15:04"))
+	}
+	// Output:
+	// Fajr    02:56
+	// Sunrise 05:05
+	// Dhuhr   12:03
+	// Asr     15:49
+	// Maghrib 18:57
+	// Isha    20:57
+}
+
+func ExampleNew() {
+	calculator, err := salah.New(
+		salah.WithLocation(21.4225, 39.8262),
+		salah.WithMethod(salah.MethodUmmAlQura),
+		salah.WithTimezone(time.FixedZone("AST", 3*60*60)),
+	)
+	if err != nil {
+		panic(err)
+	}
+	prayer, at, err := calculator.Next(time.Date(2025, 8, 27, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(prayer, at.Format("15:04 MST"))
+	// Output: Asr 15:46 AST
+}
+
+func ExampleCalculator_Current() {
+	calculator, err := salah.New(
+		salah.WithLocation(51.5074, -0.1278),
+		salah.WithTimezone(time.UTC),
+	)
+	if err != nil {
+		panic(err)
+	}
+	prayer, since, err := calculator.Current(time.Date(2025, 8, 27, 15, 0, 0, 0, time.UTC))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(prayer, "since", since.Format("15:04"))
+	// Output: Dhuhr since 12:07
+}
+
+func ExampleRange() {
+	from := time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)
+	days, err := salah.Range(from, from.AddDate(0, 0, 2),
+		salah.WithLocation(40.7128, -74.0060),
+		salah.WithMethod(salah.MethodNorthAmerica),
+		salah.WithMadhab(salah.MadhabHanafi),
+		salah.WithTimezone(time.UTC),
+	)
+	if err != nil {
+		panic(err)
+	}
+	for _, d := range days {
+		fmt.Println(d.Date.Format(time.DateOnly), "Asr", d.Asr.Format("15:04"))
+	}
+	// Output:
+	// 2025-12-30 Asr 19:57
+	// 2025-12-31 Asr 19:58
+	// 2026-01-01 Asr 19:59
+}
@@ -0,0 +1,167 @@
+package salah
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// twilightAngleLatitude is the latitude above which the twilight angle rule is recommended
+	twilightAngleLatitude = 48.0
+	// nearestLatitudeStep is the step used when searching for a latitude with a sunrise and sunset
+	nearestLatitudeStep = 0.5
+	// maxNearestDaySearch bounds the search for a day with a sunrise and sunset
+	maxNearestDaySearch = 183
+)
+
+// solarConditions describes which solar events occur at a location on a date
+type solarConditions struct {
+	sunRisesAndSets bool
+	fajrReached     bool
+	ishaReached     bool
+}
+
+// conditionsFor checks whether the sun rises and sets and whether the twilight angles are reached
+func conditionsFor(date time.Time, coords Coordinates, params Parameters) solarConditions {
+	n := nativeDay{
+		lat:   coords.Latitude,
+		jDate: julianDate(date.Year(), int(date.Month()), date.Day()) - coords.Longitude/(15*24),
+	}
+	sunrise := n.sunAngleTime(riseSetAngle, 6, true)
+	sunset := n.sunAngleTime(riseSetAngle, 18, false)
+	return solarConditions{
+		sunRisesAndSets: !math.IsNaN(sunrise) && !math.IsNaN(sunset),
+		fajrReached:     !math.IsNaN(n.sunAngleTime(params.FajrAngle, 5, true)),
+		ishaReached:     params.IshaInterval > 0 || !math.IsNaN(n.sunAngleTime(params.IshaAngle, 18, false)),
+	}
+}
+
+// isAdaptiveRule reports whether the rule is resolved per day by the calculator
+func isAdaptiveRule(rule HighLatitudeRule) bool {
+	return rule == HighLatitudeAuto || rule == HighLatitudeNearestLatitude || rule == HighLatitudeNearestDay
+}
+
+// recommendedRule picks a concrete rule for a day on which the sun rises and sets
+func recommendedRule(rule HighLatitudeRule, latitude float64, cond solarConditions) HighLatitudeRule {
+	if rule != HighLatitudeAuto || !cond.fajrReached || !cond.ishaReached {
+		return HighLatitudeSeventhOfTheNight
+	}
+	if math.Abs(latitude) >= twilightAngleLatitude {
+		return HighLatitudeTwilightAngle
+	}
+	return HighLatitudeMiddleOfTheNight
+}
+
+// computeAdaptive calculates a day using HighLatitudeAuto, HighLatitudeNearestLatitude or HighLatitudeNearestDay
+func (c *Calculator) computeAdaptive(midnight time.Time) (*Schedule, error) {
+	cond := conditionsFor(midnight, c.coords, c.params)
+	p := c.params
+	p.HighLatitudeRule = recommendedRule(c.params.HighLatitudeRule, c.coords.Latitude, cond)
+
+	var s *Schedule
+	if cond.sunRisesAndSets {
+		s, _ = c.computeUsable(midnight, c.coords, p)
+	}
+	if s == nil {
+		if c.params.HighLatitudeRule == HighLatitudeNearestDay {
+			return c.computeNearestDay(midnight)
+		}
+		return c.computeNearestLatitude(midnight)
+	}
+
+	if !cond.fajrReached {
+		s.addFallback(Fajr, fmt.Sprintf("%s (twilight persists all night)", p.HighLatitudeRule))
+	}
+	if !cond.ishaReached {
+		s.addFallback(Isha, fmt.Sprintf("%s (twilight persists all night)", p.HighLatitudeRule))
+	}
+	return s, nil
+}
+
+// computeNearestLatitude calculates the day at the closest latitude, towards the equator, where the sun rises and sets
+func (c *Calculator) computeNearestLatitude(midnight time.Time) (*Schedule, error) {
+	p := c.params
+	p.HighLatitudeRule = HighLatitudeSeventhOfTheNight
+
+	coords := c.coords
+	sign := math.Copysign(1, coords.Latitude)
+	for math.Abs(coords.Latitude) > 0 {
+		coords.Latitude -= sign * nearestLatitudeStep
+		if !conditionsFor(midnight, coords, p).sunRisesAndSets {
+			continue
+		}
+		s, ok := c.computeUsable(midnight, coords, p)
+		if !ok {
+			continue
+		}
+		reason := fmt.Sprintf("nearest latitude %.1f° (%s)", coords.Latitude, polarReason(midnight, c.coords))
+		for _, prayer := range Prayers {
+			s.addFallback(prayer, reason)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("no latitude with a sunrise and sunset found")
+}
+
+// computeNearestDay calculates the closest day with a sunrise and sunset and reuses its clock times
+func (c *Calculator) computeNearestDay(midnight time.Time) (*Schedule, error) {
+	p := c.params
+	p.HighLatitudeRule = HighLatitudeSeventhOfTheNight
+
+	for offset := 1; offset <= maxNearestDaySearch; offset++ {
+		for _, dir := range []int{1, -1} {
+			day := midnight.AddDate(0, 0, dir*offset)
+			if !conditionsFor(day, c.coords, p).sunRisesAndSets {
+				continue
+			}
+			nearest, ok := c.computeUsable(day, c.coords, p)
+			if !ok {
+				continue
+			}
+
+			s := &Schedule{Date: midnight}
+			reason := fmt.Sprintf("nearest day %s (%s)", day.Format(time.DateOnly), polarReason(midnight, c.coords))
+			for _, prayer := range Prayers {
+				clock := nearest.Time(prayer).In(midnight.Location())
+				s.set(prayer, time.Date(midnight.Year(), midnight.Month(), midnight.Day(),
+					clock.Hour(), clock.Minute(), clock.Second(), 0, midnight.Location()))
+				s.addFallback(prayer, reason)
+			}
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("no day with a sunrise and sunset found within %d days", maxNearestDaySearch)
+}
+
+// computeUsable runs the engine and reports whether it produced a day with every time in order.
+// When the sun barely clears the horizon engines can place Asr after Maghrib, which is not usable.
+func (c *Calculator) computeUsable(date time.Time, coords Coordinates, p Parameters) (*Schedule, bool) {
+	s, err := c.engine.Compute(date, coords, p)
+	if err != nil {
+		return nil, false
+	}
+	for i := 1; i < len(Prayers); i++ {
+		if !s.Time(Prayers[i]).After(s.Time(Prayers[i-1])) {
+			return nil, false
+		}
+	}
+	return s, true
+}
+
+// polarReason describes whether the location is in polar day or polar night
+func polarReason(date time.Time, coords Coordinates) string {
+	decl, _ := sunPosition(julianDate(date.Year(), int(date.Month()), date.Day()))
+	if (coords.Latitude > 0) == (decl > 0) {
+		return "the sun does not set"
+	}
+	return "the sun does not rise"
+}
+
+// addFallback records that a fallback was used to derive the time of a prayer
+func (s *Schedule) addFallback(p Prayer, reason string) {
+	if s.Fallbacks == nil {
+		s.Fallbacks = make(map[Prayer]string)
+	}
+	s.Fallbacks[p] = reason
+}
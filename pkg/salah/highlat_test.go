@@ -0,0 +1,103 @@
+package salah
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func highLatitudeCalculator(t *testing.T, lat, lon float64, rule HighLatitudeRule) *Calculator {
+	t.Helper()
+	c, err := New(
+		WithLocation(lat, lon),
+		WithMethod(MethodMuslimWorldLeague),
+		WithHighLatitudeRule(rule),
+		WithTimezone(time.UTC),
+	)
+	if err != nil {
+		t.Fatalf("failed to create calculator: %v", err)
+	}
+	return c
+}
+
+func assertOrdered(t *testing.T, s *Schedule) {
+	t.Helper()
+	for i := 1; i < len(Prayers); i++ {
+		if !s.Time(Prayers[i]).After(s.Time(Prayers[i-1])) {
+			t.Errorf("expected %s (%v) after %s (%v)", Prayers[i], s.Time(Prayers[i]), Prayers[i-1], s.Time(Prayers[i-1]))
+		}
+	}
+}
+
+var (
+	summerSolstice = time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC)
+	winterSolstice = time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+)
+
+func TestAuto_NoFallbackAtModerateLatitude(t *testing.T) {
+	c := highLatitudeCalculator(t, 51.5074, -0.1278, HighLatitudeAuto) // London
+	s, err := c.ForDate(winterSolstice)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if s.Fallbacks != nil {
+		t.Errorf("expected no fallbacks in London in winter, got %v", s.Fallbacks)
+	}
+}
+
+func TestAuto_TwilightPersists(t *testing.T) {
+	c := highLatitudeCalculator(t, 51.5074, -0.1278, HighLatitudeAuto) // London
+	s, err := c.ForDate(summerSolstice)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertOrdered(t, s)
+	for _, p := range []Prayer{Fajr, Isha} {
+		if !strings.Contains(s.Fallbacks[p], "seventh of the night") {
+			t.Errorf("expected seventh of the night fallback for %s, got %q", p, s.Fallbacks[p])
+		}
+	}
+	if _, ok := s.Fallbacks[Dhuhr]; ok {
+		t.Errorf("expected no fallback for Dhuhr, got %v", s.Fallbacks)
+	}
+}
+
+func TestAuto_PolarDayUsesNearestLatitude(t *testing.T) {
+	c := highLatitudeCalculator(t, 69.6492, 18.9553, HighLatitudeAuto) // Tromsø
+	s, err := c.ForDate(summerSolstice)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertOrdered(t, s)
+	for _, p := range Prayers {
+		if !strings.Contains(s.Fallbacks[p], "nearest latitude") || !strings.Contains(s.Fallbacks[p], "does not set") {
+			t.Errorf("expected nearest latitude fallback for %s, got %q", p, s.Fallbacks[p])
+		}
+	}
+}
+
+func TestNearestDay_PolarNight(t *testing.T) {
+	c := highLatitudeCalculator(t, 78.2232, 15.6267, HighLatitudeNearestDay) // Longyearbyen
+	s, err := c.ForDate(winterSolstice)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertOrdered(t, s)
+	if !s.Date.Equal(winterSolstice) || s.Dhuhr.YearDay() != winterSolstice.YearDay() {
+		t.Errorf("expected times on %v, got Dhuhr %v", winterSolstice, s.Dhuhr)
+	}
+	if !strings.Contains(s.Fallbacks[Asr], "nearest day") || !strings.Contains(s.Fallbacks[Asr], "does not rise") {
+		t.Errorf("expected nearest day fallback, got %q", s.Fallbacks[Asr])
+	}
+}
+
+func TestFixedRule_ReportsNoFallbacks(t *testing.T) {
+	c := highLatitudeCalculator(t, 51.5074, -0.1278, HighLatitudeSeventhOfTheNight)
+	s, err := c.ForDate(summerSolstice)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if s.Fallbacks != nil {
+		t.Errorf("expected fixed rule to report no fallbacks, got %v", s.Fallbacks)
+	}
+}
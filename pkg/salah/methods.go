@@ -0,0 +1,42 @@
+package salah
+
+// MethodParameters returns the default parameters defined by a calculation method
+func MethodParameters(m Method) Parameters {
+	p := Parameters{
+		Method:           m,
+		Madhab:           MadhabShafi,
+		HighLatitudeRule: HighLatitudeMiddleOfTheNight,
+	}
+	switch m {
+	case MethodMuslimWorldLeague:
+		p.FajrAngle, p.IshaAngle = 18.0, 17.0
+		p.MethodAdjustments = Adjustments{Dhuhr: 1}
+	case MethodEgyptian:
+		p.FajrAngle, p.IshaAngle = 19.5, 17.5
+		p.MethodAdjustments = Adjustments{Dhuhr: 1}
+	case MethodKarachi:
+		p.FajrAngle, p.IshaAngle = 18.0, 18.0
+		p.MethodAdjustments = Adjustments{Dhuhr: 1}
+	case MethodUmmAlQura:
+		p.FajrAngle, p.IshaInterval = 18.5, 90
+	case MethodDubai:
+		p.FajrAngle, p.IshaAngle = 18.2, 18.2
+		p.MethodAdjustments = Adjustments{Sunrise: -3, Dhuhr: 3, Asr: 3, Maghrib: 3}
+	case MethodMoonsightingCommittee:
+		p.FajrAngle, p.IshaAngle = 18.0, 18.0
+		p.MethodAdjustments = Adjustments{Dhuhr: 5, Maghrib: 3}
+	case MethodNorthAmerica:
+		p.FajrAngle, p.IshaAngle = 15.0, 15.0
+		p.MethodAdjustments = Adjustments{Dhuhr: 1}
+	case MethodKuwait:
+		p.FajrAngle, p.IshaAngle = 18.0, 17.5
+	case MethodQatar:
+		p.FajrAngle, p.IshaInterval = 18.0, 90
+	case MethodSingapore:
+		p.FajrAngle, p.IshaAngle = 20.0, 18.0
+		p.MethodAdjustments = Adjustments{Dhuhr: 1}
+	case MethodUOIF:
+		p.FajrAngle, p.IshaAngle = 12.0, 12.0
+	}
+	return p
+}
@@ -0,0 +1,197 @@
+package salah
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// riseSetAngle is the solar depression at sunrise/sunset, accounting for refraction and the solar disc
+const riseSetAngle = 0.833
+
+// NativeEngine calculates prayer times with a self-contained implementation of
+// the standard solar position equations. It does not apply the seasonal
+// adjustments of the Moonsighting Committee method, so results for that method
+// can differ from AdhanEngine by a few minutes.
+type NativeEngine struct{}
+
+// Name implements Engine
+func (NativeEngine) Name() string { return "native" }
+
+// Compute implements Engine
+func (NativeEngine) Compute(date time.Time, coords Coordinates, params Parameters) (*Schedule, error) {
+	n := nativeDay{
+		lat:   coords.Latitude,
+		jDate: julianDate(date.Year(), int(date.Month()), date.Day()) - coords.Longitude/(15*24),
+	}
+
+	asrFactor := 1.0
+	if params.Madhab == MadhabHanafi {
+		asrFactor = 2.0
+	}
+
+	// initial guesses in hours, refined by re-evaluating the sun's position at each estimate
+	fajr, sunrise, dhuhr, asr, sunset, isha := 5.0, 6.0, 12.0, 13.0, 18.0, 18.0
+	for i := 0; i < 2; i++ {
+		fajr = n.sunAngleTime(params.FajrAngle, fajr, true)
+		sunrise = n.sunAngleTime(riseSetAngle, sunrise, true)
+		dhuhr = n.midDay(dhuhr)
+		asr = n.asrTime(asrFactor, asr)
+		sunset = n.sunAngleTime(riseSetAngle, sunset, false)
+		isha = n.sunAngleTime(params.IshaAngle, isha, false)
+	}
+
+	if math.IsNaN(sunrise) || math.IsNaN(sunset) {
+		return nil, fmt.Errorf("native: the sun does not rise or set at latitude %.4f on %s", coords.Latitude, date.Format(time.DateOnly))
+	}
+
+	night := fixHour(sunrise - sunset)
+	if params.IshaInterval > 0 {
+		isha = sunset + float64(params.IshaInterval)/60
+	}
+
+	var err error
+	if fajr, err = adjustHighLatitude(fajr, sunrise, params.FajrAngle, night, params.HighLatitudeRule, true); err != nil {
+		return nil, fmt.Errorf("native: Fajr: %w", err)
+	}
+	if params.IshaInterval <= 0 {
+		if isha, err = adjustHighLatitude(isha, sunset, params.IshaAngle, night, params.HighLatitudeRule, false); err != nil {
+			return nil, fmt.Errorf("native: Isha: %w", err)
+		}
+	}
+
+	// convert local solar hours to UTC instants on the requested date
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	at := func(hours float64) time.Time {
+		utc := hours - coords.Longitude/15
+		return midnight.Add(time.Duration(utc * float64(time.Hour)))
+	}
+	s := &Schedule{
+		Date:    date,
+		Fajr:    at(fajr),
+		Sunrise: at(sunrise),
+		Dhuhr:   at(dhuhr),
+		Asr:     at(asr),
+		Maghrib: at(sunset),
+		Isha:    at(isha),
+	}
+	ApplyAdjustments(s, params.Adjustments, params.MethodAdjustments)
+	for _, p := range Prayers {
+		s.set(p, s.Time(p).Round(time.Minute))
+	}
+	return s, nil
+}
+
+// nativeDay holds the per-day state of a native calculation
+type nativeDay struct {
+	lat   float64
+	jDate float64
+}
+
+// sunPosition returns the sun's declination (degrees) and the equation of time (hours)
+func sunPosition(jd float64) (float64, float64) {
+	d := jd - 2451545.0
+	g := fixAngle(357.529 + 0.98560028*d)
+	q := fixAngle(280.459 + 0.98564736*d)
+	l := fixAngle(q + 1.915*dsin(g) + 0.020*dsin(2*g))
+	e := 23.439 - 0.00000036*d
+
+	ra := darctan2(dcos(e)*dsin(l), dcos(l)) / 15
+	eqt := q/15 - fixHour(ra)
+	decl := darcsin(dsin(e) * dsin(l))
+	return decl, eqt
+}
+
+// midDay returns solar noon in local solar hours
+func (n nativeDay) midDay(hours float64) float64 {
+	_, eqt := sunPosition(n.jDate + hours/24)
+	return fixHour(12 - eqt)
+}
+
+// sunAngleTime returns when the sun reaches the given depression angle, before (ccw) or after noon.
+// It returns NaN if the sun never reaches that angle.
+func (n nativeDay) sunAngleTime(angle, hours float64, ccw bool) float64 {
+	decl, _ := sunPosition(n.jDate + hours/24)
+	noon := n.midDay(hours)
+	x := (-dsin(angle) - dsin(decl)*dsin(n.lat)) / (dcos(decl) * dcos(n.lat))
+	if x < -1 || x > 1 {
+		return math.NaN()
+	}
+	t := darccos(x) / 15
+	if ccw {
+		return noon - t
+	}
+	return noon + t
+}
+
+// asrTime returns when an object's shadow reaches factor times its length plus the noon shadow
+func (n nativeDay) asrTime(factor, hours float64) float64 {
+	decl, _ := sunPosition(n.jDate + hours/24)
+	angle := -darccot(factor + dtan(math.Abs(n.lat-decl)))
+	return n.sunAngleTime(angle, hours, false)
+}
+
+// adjustHighLatitude bounds a twilight time to a portion of the night measured from base
+func adjustHighLatitude(t, base, angle, night float64, rule HighLatitudeRule, ccw bool) (float64, error) {
+	var portion float64
+	switch rule {
+	case HighLatitudeMiddleOfTheNight:
+		portion = night / 2
+	case HighLatitudeSeventhOfTheNight:
+		portion = night / 7
+	case HighLatitudeTwilightAngle:
+		portion = night * angle / 60
+	default:
+		if math.IsNaN(t) {
+			return 0, fmt.Errorf("twilight angle %.1f° is never reached and no high latitude rule is set", angle)
+		}
+		return t, nil
+	}
+
+	var diff float64
+	if ccw {
+		diff = fixHour(base - t)
+	} else {
+		diff = fixHour(t - base)
+	}
+	if math.IsNaN(t) || diff > portion {
+		if ccw {
+			return base - portion, nil
+		}
+		return base + portion, nil
+	}
+	return t, nil
+}
+
+// julianDate returns the Julian date at 0h UT of the given Gregorian date
+func julianDate(year, month, day int) float64 {
+	if month <= 2 {
+		year--
+		month += 12
+	}
+	a := math.Floor(float64(year) / 100)
+	b := 2 - a + math.Floor(a/4)
+	return math.Floor(365.25*float64(year+4716)) + math.Floor(30.6001*float64(month+1)) + float64(day) + b - 1524.5
+}
+
+func dtr(d float64) float64 { return d * math.Pi / 180 }
+func rtd(r float64) float64 { return r * 180 / math.Pi }
+
+func dsin(d float64) float64        { return math.Sin(dtr(d)) }
+func dcos(d float64) float64        { return math.Cos(dtr(d)) }
+func dtan(d float64) float64        { return math.Tan(dtr(d)) }
+func darcsin(x float64) float64     { return rtd(math.Asin(x)) }
+func darccos(x float64) float64     { return rtd(math.Acos(x)) }
+func darctan2(y, x float64) float64 { return rtd(math.Atan2(y, x)) }
+func darccot(x float64) float64     { return rtd(math.Atan(1 / x)) }
+
+func fixAngle(a float64) float64 { return fix(a, 360) }
+func fixHour(h float64) float64  { return fix(h, 24) }
+
+func fix(a, b float64) float64 {
+	a = a - b*math.Floor(a/b)
+	if a < 0 {
+		return a + b
+	}
+	return a
+}
@@ -0,0 +1,82 @@
+package salah
+
+import "time"
+
+// settings collects the values supplied through options before they are resolved
+type settings struct {
+	hasLocation       bool
+	latitude          float64
+	longitude         float64
+	timezone          *time.Location
+	method            Method
+	fajrAngle         *float64
+	ishaAngle         *float64
+	ishaInterval      *int
+	madhab            *Madhab
+	highLatitudeRule  *HighLatitudeRule
+	adjustments       *Adjustments
+	methodAdjustments *Adjustments
+	engine            Engine
+}
+
+// Option configures a Calculator
+type Option func(*settings)
+
+// WithLocation sets the coordinates prayer times are calculated for (required)
+func WithLocation(latitude, longitude float64) Option {
+	return func(s *settings) {
+		s.hasLocation = true
+		s.latitude = latitude
+		s.longitude = longitude
+	}
+}
+
+// WithTimezone sets the location schedule times are reported in (default: time.Local)
+func WithTimezone(loc *time.Location) Option {
+	return func(s *settings) { s.timezone = loc }
+}
+
+// WithMethod sets the calculation method (default: DefaultMethod)
+func WithMethod(m Method) Option {
+	return func(s *settings) { s.method = m }
+}
+
+// WithFajrAngle overrides the method's Fajr twilight angle in degrees
+func WithFajrAngle(angle float64) Option {
+	return func(s *settings) { s.fajrAngle = &angle }
+}
+
+// WithIshaAngle overrides the method's Isha twilight angle in degrees
+func WithIshaAngle(angle float64) Option {
+	return func(s *settings) { s.ishaAngle = &angle }
+}
+
+// WithIshaInterval sets Isha to a fixed number of minutes after Maghrib
+func WithIshaInterval(minutes int) Option {
+	return func(s *settings) { s.ishaInterval = &minutes }
+}
+
+// WithMadhab sets the juristic method used for Asr
+func WithMadhab(m Madhab) Option {
+	return func(s *settings) { s.madhab = &m }
+}
+
+// WithHighLatitudeRule sets how Fajr and Isha are bounded at high latitudes
+func WithHighLatitudeRule(rule HighLatitudeRule) Option {
+	return func(s *settings) { s.highLatitudeRule = &rule }
+}
+
+// WithAdjustments applies user offsets (in minutes) to each prayer
+func WithAdjustments(adj Adjustments) Option {
+	return func(s *settings) { s.adjustments = &adj }
+}
+
+// WithMethodAdjustments replaces the offsets defined by the calculation method
+func WithMethodAdjustments(adj Adjustments) Option {
+	return func(s *settings) { s.methodAdjustments = &adj }
+}
+
+// WithEngine sets the engine used to calculate prayer times (default: AdhanEngine)
+func WithEngine(e Engine) Option {
+	return func(s *settings) { s.engine = e }
+}
@@ -0,0 +1,222 @@
+// Package salah calculates Islamic prayer times for a location.
+//
+// A Calculator is created once from options and can then be queried for any
+// date. The package-level functions are shortcuts for one-off calculations:
+//
+//	schedule, err := salah.ForDate(time.Now(),
+//		salah.WithLocation(51.5074, -0.1278),
+//		salah.WithMethod(salah.MethodMuslimWorldLeague),
+//	)
+package salah
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoLocation is returned when a Calculator is created without WithLocation
+var ErrNoLocation = errors.New("location is required")
+
+// Calculator computes prayer schedules for a fixed location and set of parameters
+type Calculator struct {
+	coords Coordinates
+	loc    *time.Location
+	params Parameters
+	engine Engine
+}
+
+// New creates a Calculator from the given options
+func New(opts ...Option) (*Calculator, error) {
+	s := settings{method: DefaultMethod}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if !s.hasLocation {
+		return nil, ErrNoLocation
+	}
+	if s.latitude < -90 || s.latitude > 90 {
+		return nil, fmt.Errorf("invalid location: latitude must be between -90 and 90 (got %f)", s.latitude)
+	}
+	if s.longitude < -180 || s.longitude > 180 {
+		return nil, fmt.Errorf("invalid location: longitude must be between -180 and 180 (got %f)", s.longitude)
+	}
+
+	loc := s.timezone
+	if loc == nil {
+		loc = time.Local
+	}
+	engine := s.engine
+	if engine == nil {
+		engine = AdhanEngine{}
+	}
+
+	p := MethodParameters(s.method)
+	if s.fajrAngle != nil {
+		p.FajrAngle = *s.fajrAngle
+	}
+	if s.ishaAngle != nil {
+		p.IshaAngle = *s.ishaAngle
+	}
+	if s.ishaInterval != nil {
+		p.IshaInterval = *s.ishaInterval
+	}
+	if s.madhab != nil {
+		p.Madhab = *s.madhab
+	}
+	if s.highLatitudeRule != nil {
+		p.HighLatitudeRule = *s.highLatitudeRule
+	}
+	if s.adjustments != nil {
+		p.Adjustments = *s.adjustments
+	}
+	if s.methodAdjustments != nil {
+		p.MethodAdjustments = *s.methodAdjustments
+	}
+
+	return &Calculator{
+		coords: Coordinates{Latitude: s.latitude, Longitude: s.longitude},
+		loc:    loc,
+		params: p,
+		engine: engine,
+	}, nil
+}
+
+// Parameters returns the effective calculation parameters
+func (c *Calculator) Parameters() Parameters {
+	return c.params
+}
+
+// Latitude returns the latitude the calculator was created for
+func (c *Calculator) Latitude() float64 {
+	return c.coords.Latitude
+}
+
+// Longitude returns the longitude the calculator was created for
+func (c *Calculator) Longitude() float64 {
+	return c.coords.Longitude
+}
+
+// Engine returns the engine used to calculate schedules
+func (c *Calculator) Engine() Engine {
+	return c.engine
+}
+
+// Location returns the time zone schedules are reported in
+func (c *Calculator) Location() *time.Location {
+	return c.loc
+}
+
+// ForDate returns the schedule for the calendar date of date in the calculator's time zone
+func (c *Calculator) ForDate(date time.Time) (*Schedule, error) {
+	local := date.In(c.loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.loc)
+	var s *Schedule
+	var err error
+	if isAdaptiveRule(c.params.HighLatitudeRule) {
+		s, err = c.computeAdaptive(midnight)
+	} else {
+		s, err = c.engine.Compute(midnight, c.coords, c.params)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate prayer times for %s: %w", midnight.Format(time.DateOnly), err)
+	}
+
+	s.Date = midnight
+	for _, p := range Prayers {
+		s.set(p, s.Time(p).In(c.loc))
+	}
+	return s, nil
+}
+
+// Range returns one schedule per day from from to to, inclusive
+func (c *Calculator) Range(from, to time.Time) ([]*Schedule, error) {
+	start := from.In(c.loc)
+	end := to.In(c.loc)
+	// step at midday so DST transitions never skip or repeat a date
+	day := time.Date(start.Year(), start.Month(), start.Day(), 12, 0, 0, 0, c.loc)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 12, 0, 0, 0, c.loc)
+	if last.Before(day) {
+		return nil, fmt.Errorf("range end %s is before start %s", last.Format(time.DateOnly), day.Format(time.DateOnly))
+	}
+
+	var out []*Schedule
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		s, err := c.ForDate(day)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Next returns the next obligatory prayer strictly after now, looking into tomorrow if needed
+func (c *Calculator) Next(now time.Time) (Prayer, time.Time, error) {
+	for _, date := range []time.Time{now, now.In(c.loc).AddDate(0, 0, 1)} {
+		s, err := c.ForDate(date)
+		if err != nil {
+			return NoPrayer, time.Time{}, err
+		}
+		for _, p := range Obligatory {
+			if t := s.Time(p); t.After(now) {
+				return p, t, nil
+			}
+		}
+	}
+	return NoPrayer, time.Time{}, fmt.Errorf("no upcoming prayer found after %s", now.Format(time.RFC3339))
+}
+
+// Current returns the prayer time most recently reached at now. Sunrise is
+// returned between sunrise and Dhuhr; before Fajr the previous day's Isha is returned.
+func (c *Calculator) Current(now time.Time) (Prayer, time.Time, error) {
+	today, err := c.ForDate(now)
+	if err != nil {
+		return NoPrayer, time.Time{}, err
+	}
+	if p := today.CurrentPrayer(now); p != NoPrayer {
+		return p, today.Time(p), nil
+	}
+
+	yesterday, err := c.ForDate(now.In(c.loc).AddDate(0, 0, -1))
+	if err != nil {
+		return NoPrayer, time.Time{}, err
+	}
+	return Isha, yesterday.Isha, nil
+}
+
+// ForDate returns the schedule for date using a Calculator built from opts
+func ForDate(date time.Time, opts ...Option) (*Schedule, error) {
+	c, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.ForDate(date)
+}
+
+// Range returns the schedules from from to to, inclusive, using a Calculator built from opts
+func Range(from, to time.Time, opts ...Option) ([]*Schedule, error) {
+	c, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.Range(from, to)
+}
+
+// Next returns the next obligatory prayer after now using a Calculator built from opts
+func Next(now time.Time, opts ...Option) (Prayer, time.Time, error) {
+	c, err := New(opts...)
+	if err != nil {
+		return NoPrayer, time.Time{}, err
+	}
+	return c.Next(now)
+}
+
+// Current returns the prayer time most recently reached at now using a Calculator built from opts
+func Current(now time.Time, opts ...Option) (Prayer, time.Time, error) {
+	c, err := New(opts...)
+	if err != nil {
+		return NoPrayer, time.Time{}, err
+	}
+	return c.Current(now)
+}
@@ -0,0 +1,157 @@
+package salah
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func londonCalculator(t *testing.T, opts ...Option) *Calculator {
+	t.Helper()
+	opts = append([]Option{WithLocation(51.5074, -0.1278), WithTimezone(time.UTC)}, opts...)
+	c, err := New(opts...)
+	if err != nil {
+		t.Fatalf("failed to create calculator: %v", err)
+	}
+	return c
+}
+
+func TestNew_RequiresLocation(t *testing.T) {
+	if _, err := New(); !errors.Is(err, ErrNoLocation) {
+		t.Fatalf("expected ErrNoLocation, got %v", err)
+	}
+	if _, err := New(WithLocation(100, 0)); err == nil {
+		t.Fatal("expected error for invalid latitude, got nil")
+	}
+}
+
+func TestNew_Parameters(t *testing.T) {
+	c := londonCalculator(t)
+	if c.Parameters().Method != DefaultMethod {
+		t.Errorf("expected default method %v, got %v", DefaultMethod, c.Parameters().Method)
+	}
+
+	adj := Adjustments{Fajr: 2, Isha: -3}
+	c = londonCalculator(t,
+		WithMethod(MethodEgyptian),
+		WithFajrAngle(18.5),
+		WithMadhab(MadhabHanafi),
+		WithHighLatitudeRule(HighLatitudeSeventhOfTheNight),
+		WithAdjustments(adj),
+	)
+	p := c.Parameters()
+	if p.Method != MethodEgyptian || p.FajrAngle != 18.5 || p.Madhab != MadhabHanafi ||
+		p.HighLatitudeRule != HighLatitudeSeventhOfTheNight || p.Adjustments != adj {
+		t.Errorf("options not applied: %+v", p)
+	}
+}
+
+func TestForDate(t *testing.T) {
+	c := londonCalculator(t)
+	s, err := c.ForDate(time.Date(2025, 8, 27, 15, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !s.Date.Equal(time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected schedule date %v", s.Date)
+	}
+	previous := time.Time{}
+	for _, p := range Prayers {
+		if !s.Time(p).After(previous) {
+			t.Errorf("expected %s after %v, got %v", p, previous, s.Time(p))
+		}
+		previous = s.Time(p)
+	}
+}
+
+func TestForDate_AdjustmentsShiftTimes(t *testing.T) {
+	date := time.Date(2025, 8, 27, 12, 0, 0, 0, time.UTC)
+	base, _ := londonCalculator(t).ForDate(date)
+	adjusted, _ := londonCalculator(t, WithAdjustments(Adjustments{Dhuhr: 5})).ForDate(date)
+
+	if got := adjusted.Dhuhr.Sub(base.Dhuhr); got != 5*time.Minute {
+		t.Errorf("expected Dhuhr shifted by 5m, got %v", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	c := londonCalculator(t)
+	from := time.Date(2025, 3, 29, 0, 0, 0, 0, time.UTC)
+	days, err := c.Range(from, from.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(days) != 4 {
+		t.Fatalf("expected 4 days, got %d", len(days))
+	}
+	if _, err := c.Range(from, from.AddDate(0, 0, -1)); err == nil {
+		t.Error("expected error for reversed range, got nil")
+	}
+}
+
+func TestNext(t *testing.T) {
+	c := londonCalculator(t)
+	s, _ := c.ForDate(time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC))
+
+	p, at, err := c.Next(s.Sunrise)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p != Dhuhr || !at.Equal(s.Dhuhr) {
+		t.Errorf("expected Dhuhr at %v after sunrise, got %s at %v", s.Dhuhr, p, at)
+	}
+
+	p, at, err = c.Next(s.Isha.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p != Fajr || at.Day() != 28 {
+		t.Errorf("expected tomorrow's Fajr after Isha, got %s at %v", p, at)
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	c := londonCalculator(t)
+	s, _ := c.ForDate(time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC))
+
+	p, at, err := c.Current(s.Asr.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p != Asr || !at.Equal(s.Asr) {
+		t.Errorf("expected Asr at %v, got %s at %v", s.Asr, p, at)
+	}
+
+	p, at, _ = c.Current(s.Fajr.Add(-time.Minute))
+	if p != Isha || at.Day() != 26 {
+		t.Errorf("expected yesterday's Isha before Fajr, got %s at %v", p, at)
+	}
+}
+
+func TestScheduleCurrentAndNextPrayer(t *testing.T) {
+	c := londonCalculator(t)
+	s, _ := c.ForDate(time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC))
+
+	if got := s.CurrentPrayer(s.Fajr.Add(-time.Second)); got != NoPrayer {
+		t.Errorf("expected NoPrayer before Fajr, got %s", got)
+	}
+	if got := s.CurrentPrayer(s.Sunrise); got != Sunrise {
+		t.Errorf("expected Sunrise at sunrise, got %s", got)
+	}
+	if got := s.NextPrayer(s.Fajr); got != Sunrise {
+		t.Errorf("expected Sunrise after Fajr, got %s", got)
+	}
+	if got := s.NextPrayer(s.Isha); got != NoPrayer {
+		t.Errorf("expected NoPrayer after Isha, got %s", got)
+	}
+}
+
+func TestParsePrayer(t *testing.T) {
+	p, err := ParsePrayer("maghrib")
+	if err != nil || p != Maghrib {
+		t.Errorf("expected Maghrib, got %s (%v)", p, err)
+	}
+	if _, err := ParsePrayer("tahajjud"); err == nil {
+		t.Error("expected error for unknown prayer, got nil")
+	}
+}
@@ -0,0 +1,41 @@
+// Package salahtest provides a calculator with predictable prayer times for tests.
+package salahtest
+
+import (
+	"salah-cli/pkg/salah"
+	"testing"
+	"time"
+)
+
+// Engine gives the same times every day: Fajr 05:00, Sunrise 06:30, Dhuhr 13:00, Asr 16:30,
+// Maghrib 19:00 and Isha 20:30
+var Engine = salah.FixedEngine{
+	Fajr:    5 * time.Hour,
+	Sunrise: 6*time.Hour + 30*time.Minute,
+	Dhuhr:   13 * time.Hour,
+	Asr:     16*time.Hour + 30*time.Minute,
+	Maghrib: 19 * time.Hour,
+	Isha:    20*time.Hour + 30*time.Minute,
+}
+
+// Latitude and Longitude are where the calculator of Calculator is, in London
+const (
+	Latitude  = 51.5
+	Longitude = -0.12
+)
+
+// Calculator returns a calculator using Engine in London, reporting times in UTC. Options are
+// applied after those, so they can change them.
+func Calculator(t testing.TB, opts ...salah.Option) *salah.Calculator {
+	t.Helper()
+	opts = append([]salah.Option{
+		salah.WithLocation(Latitude, Longitude),
+		salah.WithTimezone(time.UTC),
+		salah.WithEngine(Engine),
+	}, opts...)
+	c, err := salah.New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
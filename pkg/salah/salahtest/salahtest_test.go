@@ -0,0 +1,30 @@
+package salahtest
+
+import (
+	"salah-cli/pkg/salah"
+	"testing"
+	"time"
+)
+
+func TestCalculator(t *testing.T) {
+	s, err := Calculator(t).ForDate(time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[salah.Prayer]string{salah.Fajr: "05:00", salah.Sunrise: "06:30", salah.Dhuhr: "13:00", salah.Asr: "16:30", salah.Maghrib: "19:00", salah.Isha: "20:30"}
+	for p, at := range want {
+		if got := s.Time(p).Format("15:04"); got != at {
+			t.Errorf("expected %s at %s, got %s", p, at, got)
+		}
+	}
+}
+
+func TestCalculator_Options(t *testing.T) {
+	s, err := Calculator(t, salah.WithAdjustments(salah.Adjustments{Asr: 2})).ForDate(time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Asr.Format("15:04"); got != "16:32" {
+		t.Errorf("expected adjusted Asr 16:32, got %s", got)
+	}
+}
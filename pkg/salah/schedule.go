@@ -0,0 +1,79 @@
+package salah
+
+import "time"
+
+// Schedule holds the prayer times for a single day
+type Schedule struct {
+	// Date is midnight at the start of the day, in the calculator's time zone
+	Date    time.Time
+	Fajr    time.Time
+	Sunrise time.Time
+	Dhuhr   time.Time
+	Asr     time.Time
+	Maghrib time.Time
+	Isha    time.Time
+
+	// Fallbacks describes, per prayer, any high-latitude fallback used to
+	// derive a time that cannot be observed directly. It is nil when none applied.
+	Fallbacks map[Prayer]string
+}
+
+// Time returns the time of the given prayer, or the zero time for NoPrayer
+func (s *Schedule) Time(p Prayer) time.Time {
+	switch p {
+	case Fajr:
+		return s.Fajr
+	case Sunrise:
+		return s.Sunrise
+	case Dhuhr:
+		return s.Dhuhr
+	case Asr:
+		return s.Asr
+	case Maghrib:
+		return s.Maghrib
+	case Isha:
+		return s.Isha
+	default:
+		return time.Time{}
+	}
+}
+
+// CurrentPrayer returns the latest prayer time reached at t, or NoPrayer before Fajr
+func (s *Schedule) CurrentPrayer(t time.Time) Prayer {
+	current := NoPrayer
+	for _, p := range Prayers {
+		if t.Before(s.Time(p)) {
+			break
+		}
+		current = p
+	}
+	return current
+}
+
+// NextPrayer returns the first prayer time after t (including Sunrise), or NoPrayer after Isha
+func (s *Schedule) NextPrayer(t time.Time) Prayer {
+	for _, p := range Prayers {
+		if t.Before(s.Time(p)) {
+			return p
+		}
+	}
+	return NoPrayer
+}
+
+// set replaces the time of the given prayer
+func (s *Schedule) set(p Prayer, t time.Time) {
+	switch p {
+	case Fajr:
+		s.Fajr = t
+	case Sunrise:
+		s.Sunrise = t
+	case Dhuhr:
+		s.Dhuhr = t
+	case Asr:
+		s.Asr = t
+	case Maghrib:
+		s.Maghrib = t
+	case Isha:
+		s.Isha = t
+	}
+}
@@ -0,0 +1,107 @@
+package salah
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// timetableColumns is the header expected at the top of a timetable CSV file
+var timetableColumns = []string{"date", "fajr", "sunrise", "dhuhr", "asr", "maghrib", "isha"}
+
+// TimetableEngine looks prayer times up from a published timetable, such as
+// one issued by a local mosque. Times are wall-clock times in the calculator's
+// time zone. Only params.Adjustments are applied; method adjustments are
+// assumed to be part of the published times.
+type TimetableEngine struct {
+	days map[string][6]string
+}
+
+// LoadTimetable reads a timetable CSV file from path
+func LoadTimetable(path string) (*TimetableEngine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open timetable %s: %w", path, err)
+	}
+	defer f.Close()
+
+	t, err := ParseTimetable(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timetable %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// ParseTimetable reads a timetable in CSV form with the header
+// "date,fajr,sunrise,dhuhr,asr,maghrib,isha", dates as YYYY-MM-DD and times as HH:MM
+func ParseTimetable(r io.Reader) (*TimetableEngine, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if len(header) != len(timetableColumns) {
+		return nil, fmt.Errorf("expected header %q", strings.Join(timetableColumns, ","))
+	}
+	for i, col := range header {
+		if !strings.EqualFold(strings.TrimSpace(col), timetableColumns[i]) {
+			return nil, fmt.Errorf("expected header %q", strings.Join(timetableColumns, ","))
+		}
+	}
+
+	t := &TimetableEngine{days: make(map[string][6]string)}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if _, err := time.Parse(time.DateOnly, record[0]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid date '%s', expected YYYY-MM-DD", line, record[0])
+		}
+		var times [6]string
+		for i := range times {
+			v := strings.TrimSpace(record[i+1])
+			if _, err := time.Parse("15:04", v); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s time '%s', expected HH:MM", line, timetableColumns[i+1], v)
+			}
+			times[i] = v
+		}
+		t.days[record[0]] = times
+	}
+	if len(t.days) == 0 {
+		return nil, fmt.Errorf("timetable has no entries")
+	}
+	return t, nil
+}
+
+// Name implements Engine
+func (*TimetableEngine) Name() string { return "timetable" }
+
+// Compute implements Engine
+func (t *TimetableEngine) Compute(date time.Time, _ Coordinates, params Parameters) (*Schedule, error) {
+	key := date.Format(time.DateOnly)
+	times, ok := t.days[key]
+	if !ok {
+		return nil, fmt.Errorf("timetable: no entry for %s", key)
+	}
+
+	s := &Schedule{Date: date}
+	for i, p := range Prayers {
+		clock, _ := time.Parse("15:04", times[i])
+		s.set(p, time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, date.Location()))
+	}
+	ApplyAdjustments(s, params.Adjustments)
+	return s, nil
+}
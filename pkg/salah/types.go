@@ -0,0 +1,192 @@
+package salah
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prayer identifies one of the daily prayer times
+type Prayer int
+
+const (
+	NoPrayer Prayer = iota
+	Fajr
+	Sunrise
+	Dhuhr
+	Asr
+	Maghrib
+	Isha
+)
+
+// Prayers lists every time in a Schedule, in daily order (including Sunrise)
+var Prayers = []Prayer{Fajr, Sunrise, Dhuhr, Asr, Maghrib, Isha}
+
+// Obligatory lists the five daily prayers, in daily order (excluding Sunrise)
+var Obligatory = []Prayer{Fajr, Dhuhr, Asr, Maghrib, Isha}
+
+var prayerNames = map[Prayer]string{
+	NoPrayer: "None",
+	Fajr:     "Fajr",
+	Sunrise:  "Sunrise",
+	Dhuhr:    "Dhuhr",
+	Asr:      "Asr",
+	Maghrib:  "Maghrib",
+	Isha:     "Isha",
+}
+
+// String returns the display name of the prayer
+func (p Prayer) String() string {
+	if name, ok := prayerNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Prayer(%d)", int(p))
+}
+
+// ParsePrayer returns the prayer with the given name (case-insensitive)
+func ParsePrayer(name string) (Prayer, error) {
+	for _, p := range Prayers {
+		if strings.EqualFold(p.String(), name) {
+			return p, nil
+		}
+	}
+	return NoPrayer, fmt.Errorf("unknown prayer '%s'", name)
+}
+
+// Method is a calculation method defining twilight angles and adjustments
+type Method int
+
+const (
+	MethodOther Method = iota
+	MethodMuslimWorldLeague
+	MethodEgyptian
+	MethodKarachi
+	MethodUmmAlQura
+	MethodDubai
+	MethodMoonsightingCommittee
+	MethodNorthAmerica
+	MethodKuwait
+	MethodQatar
+	MethodSingapore
+	MethodUOIF
+)
+
+// DefaultMethod is used when no method is given
+const DefaultMethod = MethodMoonsightingCommittee
+
+// Methods lists every calculation method
+var Methods = []Method{
+	MethodOther, MethodMuslimWorldLeague, MethodEgyptian, MethodKarachi, MethodUmmAlQura, MethodDubai,
+	MethodMoonsightingCommittee, MethodNorthAmerica, MethodKuwait, MethodQatar, MethodSingapore, MethodUOIF,
+}
+
+var methodNames = map[Method]string{
+	MethodOther:                 "Other",
+	MethodMuslimWorldLeague:     "Muslim World League",
+	MethodEgyptian:              "Egyptian",
+	MethodKarachi:               "Karachi",
+	MethodUmmAlQura:             "Umm al-Qura",
+	MethodDubai:                 "Dubai",
+	MethodMoonsightingCommittee: "Moonsighting Committee",
+	MethodNorthAmerica:          "North America (ISNA)",
+	MethodKuwait:                "Kuwait",
+	MethodQatar:                 "Qatar",
+	MethodSingapore:             "Singapore",
+	MethodUOIF:                  "UOIF",
+}
+
+// String returns the display name of the method
+func (m Method) String() string {
+	if name, ok := methodNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Method(%d)", int(m))
+}
+
+// Madhab is the juristic method used to compute Asr
+type Madhab int
+
+const (
+	MadhabShafi Madhab = iota
+	MadhabHanafi
+)
+
+// Madhabs lists every juristic method
+var Madhabs = []Madhab{MadhabShafi, MadhabHanafi}
+
+var madhabNames = map[Madhab]string{
+	MadhabShafi:  "Shafi",
+	MadhabHanafi: "Hanafi",
+}
+
+// String returns the display name of the madhab
+func (m Madhab) String() string {
+	if name, ok := madhabNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Madhab(%d)", int(m))
+}
+
+// HighLatitudeRule controls how Fajr and Isha are bounded at high latitudes
+type HighLatitudeRule int
+
+const (
+	HighLatitudeNone HighLatitudeRule = iota
+	HighLatitudeMiddleOfTheNight
+	HighLatitudeSeventhOfTheNight
+	HighLatitudeTwilightAngle
+	// HighLatitudeAuto picks a rule from the location and date, falling back to
+	// the nearest latitude where the sun rises and sets during polar day or night
+	HighLatitudeAuto
+	// HighLatitudeNearestLatitude uses the seventh of the night when twilight
+	// persists, and the nearest latitude where the sun rises and sets during polar day or night
+	HighLatitudeNearestLatitude
+	// HighLatitudeNearestDay uses the seventh of the night when twilight
+	// persists, and the clock times of the nearest day with a sunrise and sunset during polar day or night
+	HighLatitudeNearestDay
+)
+
+// HighLatitudeRules lists every high latitude rule
+var HighLatitudeRules = []HighLatitudeRule{
+	HighLatitudeNone, HighLatitudeMiddleOfTheNight, HighLatitudeSeventhOfTheNight, HighLatitudeTwilightAngle,
+	HighLatitudeAuto, HighLatitudeNearestLatitude, HighLatitudeNearestDay,
+}
+
+var highLatitudeRuleNames = map[HighLatitudeRule]string{
+	HighLatitudeNone:              "none",
+	HighLatitudeMiddleOfTheNight:  "middle of the night",
+	HighLatitudeSeventhOfTheNight: "seventh of the night",
+	HighLatitudeTwilightAngle:     "twilight angle",
+	HighLatitudeAuto:              "auto",
+	HighLatitudeNearestLatitude:   "nearest latitude",
+	HighLatitudeNearestDay:        "nearest day",
+}
+
+// String returns a human readable name for the rule
+func (r HighLatitudeRule) String() string {
+	if name, ok := highLatitudeRuleNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("HighLatitudeRule(%d)", int(r))
+}
+
+// Adjustments are per-prayer offsets in minutes
+type Adjustments struct {
+	Fajr    int
+	Sunrise int
+	Dhuhr   int
+	Asr     int
+	Maghrib int
+	Isha    int
+}
+
+// Parameters are the effective settings used to calculate prayer times
+type Parameters struct {
+	Method            Method
+	FajrAngle         float64
+	IshaAngle         float64
+	IshaInterval      int
+	Madhab            Madhab
+	HighLatitudeRule  HighLatitudeRule
+	Adjustments       Adjustments
+	MethodAdjustments Adjustments
+}
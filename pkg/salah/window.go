@@ -0,0 +1,101 @@
+package salah
+
+import "time"
+
+// IshaEnd selects when the Isha window ends
+type IshaEnd int
+
+const (
+	// IshaEndFajr ends Isha at the next day's Fajr
+	IshaEndFajr IshaEnd = iota
+	// IshaEndMidnight ends Isha at Islamic midnight, halfway between Maghrib and the next Fajr
+	IshaEndMidnight
+)
+
+// Window is the time during which a prayer is due, from its time until the time ending it:
+// Fajr ends at sunrise, Dhuhr at Asr, Asr at Maghrib, Maghrib at Isha and Isha at the next Fajr
+// or Islamic midnight. Between windows, from sunrise to Dhuhr and from midnight to Fajr,
+// Prayer is NoPrayer.
+type Window struct {
+	Prayer Prayer
+	Start  time.Time
+	End    time.Time
+	// Next is the obligatory prayer following the window
+	Next Prayer
+}
+
+// Duration returns the length of the window
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// Elapsed returns the fraction of the window elapsed at t, between 0 and 1
+func (w Window) Elapsed(t time.Time) float64 {
+	total := w.Duration()
+	if total <= 0 || !t.After(w.Start) {
+		return 0
+	}
+	if !t.Before(w.End) {
+		return 1
+	}
+	return float64(t.Sub(w.Start)) / float64(total)
+}
+
+// Remaining returns the time left in the window at t
+func (w Window) Remaining(t time.Time) time.Duration {
+	if !t.Before(w.End) {
+		return 0
+	}
+	return w.End.Sub(t)
+}
+
+// Window returns the prayer window active at now, with Isha ending as selected by ishaEnd
+func (c *Calculator) Window(now time.Time, ishaEnd IshaEnd) (Window, error) {
+	today, err := c.ForDate(now)
+	if err != nil {
+		return Window{}, err
+	}
+
+	switch today.CurrentPrayer(now) {
+	case NoPrayer:
+		yesterday, err := c.ForDate(now.In(c.loc).AddDate(0, 0, -1))
+		if err != nil {
+			return Window{}, err
+		}
+		return ishaWindow(now, yesterday, today, ishaEnd), nil
+	case Fajr:
+		return Window{Prayer: Fajr, Start: today.Fajr, End: today.Sunrise, Next: Dhuhr}, nil
+	case Sunrise:
+		return Window{Prayer: NoPrayer, Start: today.Sunrise, End: today.Dhuhr, Next: Dhuhr}, nil
+	case Dhuhr:
+		return Window{Prayer: Dhuhr, Start: today.Dhuhr, End: today.Asr, Next: Asr}, nil
+	case Asr:
+		return Window{Prayer: Asr, Start: today.Asr, End: today.Maghrib, Next: Maghrib}, nil
+	case Maghrib:
+		return Window{Prayer: Maghrib, Start: today.Maghrib, End: today.Isha, Next: Isha}, nil
+	}
+
+	tomorrow, err := c.ForDate(now.In(c.loc).AddDate(0, 0, 1))
+	if err != nil {
+		return Window{}, err
+	}
+	return ishaWindow(now, today, tomorrow, ishaEnd), nil
+}
+
+// ishaWindow returns the window at now, between the Isha of day and the Fajr of the day after
+func ishaWindow(now time.Time, day, next *Schedule, ishaEnd IshaEnd) Window {
+	w := Window{Prayer: Isha, Start: day.Isha, End: next.Fajr, Next: Fajr}
+	if ishaEnd != IshaEndMidnight {
+		return w
+	}
+	// at high latitudes Isha can fall after midnight, leaving it until Fajr
+	midnight := day.Maghrib.Add(next.Fajr.Sub(day.Maghrib) / 2)
+	if !midnight.After(w.Start) {
+		return w
+	}
+	if now.Before(midnight) {
+		w.End = midnight
+		return w
+	}
+	return Window{Prayer: NoPrayer, Start: midnight, End: next.Fajr, Next: Fajr}
+}
@@ -0,0 +1,68 @@
+package salah
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	c := londonCalculator(t)
+	yesterday, _ := c.ForDate(time.Date(2025, 8, 26, 0, 0, 0, 0, time.UTC))
+	s, _ := c.ForDate(time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC))
+	tomorrow, _ := c.ForDate(time.Date(2025, 8, 28, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		ishaEnd IshaEnd
+		want    Window
+	}{
+		{"fajr", s.Fajr, IshaEndFajr, Window{Fajr, s.Fajr, s.Sunrise, Dhuhr}},
+		{"after sunrise", s.Sunrise.Add(time.Hour), IshaEndFajr, Window{NoPrayer, s.Sunrise, s.Dhuhr, Dhuhr}},
+		{"dhuhr", s.Dhuhr.Add(time.Minute), IshaEndFajr, Window{Dhuhr, s.Dhuhr, s.Asr, Asr}},
+		{"asr", s.Asr, IshaEndFajr, Window{Asr, s.Asr, s.Maghrib, Maghrib}},
+		{"maghrib", s.Maghrib, IshaEndFajr, Window{Maghrib, s.Maghrib, s.Isha, Isha}},
+		{"isha until fajr", s.Isha.Add(time.Minute), IshaEndFajr, Window{Isha, s.Isha, tomorrow.Fajr, Fajr}},
+		{"before fajr", s.Fajr.Add(-time.Minute), IshaEndFajr, Window{Isha, yesterday.Isha, s.Fajr, Fajr}},
+	}
+	for _, tt := range tests {
+		got, err := c.Window(tt.now, tt.ishaEnd)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestWindow_IshaEndsAtMidnight(t *testing.T) {
+	c := londonCalculator(t)
+	s, _ := c.ForDate(time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC))
+	tomorrow, _ := c.ForDate(time.Date(2025, 8, 28, 0, 0, 0, 0, time.UTC))
+	midnight := s.Maghrib.Add(tomorrow.Fajr.Sub(s.Maghrib) / 2)
+
+	w, _ := c.Window(s.Isha.Add(time.Minute), IshaEndMidnight)
+	if w.Prayer != Isha || !w.End.Equal(midnight) {
+		t.Errorf("expected Isha until %v, got %+v", midnight, w)
+	}
+	w, _ = c.Window(midnight.Add(time.Minute), IshaEndMidnight)
+	if w.Prayer != NoPrayer || !w.Start.Equal(midnight) || !w.End.Equal(tomorrow.Fajr) || w.Next != Fajr {
+		t.Errorf("expected no prayer from midnight until Fajr, got %+v", w)
+	}
+}
+
+func TestWindow_ElapsedAndRemaining(t *testing.T) {
+	start := time.Date(2025, 8, 27, 13, 0, 0, 0, time.UTC)
+	w := Window{Prayer: Dhuhr, Start: start, End: start.Add(4 * time.Hour), Next: Asr}
+
+	if got := w.Elapsed(start.Add(time.Hour)); got != 0.25 {
+		t.Errorf("expected a quarter elapsed, got %v", got)
+	}
+	if got := w.Remaining(start.Add(time.Hour)); got != 3*time.Hour {
+		t.Errorf("expected 3h remaining, got %v", got)
+	}
+	if w.Elapsed(start.Add(-time.Hour)) != 0 || w.Elapsed(w.End.Add(time.Hour)) != 1 || w.Remaining(w.End) != 0 {
+		t.Error("expected elapsed and remaining to be clamped to the window")
+	}
+}